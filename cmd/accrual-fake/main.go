@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/accrualfake"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Команда запускает поддельную систему расчета баллов для локальной разработки
+// ответы по заказам можно задать JSON-файлом сценариев (флаг -scenarios)
+func main() {
+	address := flag.String("a", "localhost:8081", "Accrual fake address")
+	latency := flag.Duration("latency", 0, "Delay before every response")
+	rateLimit := flag.Int("rate-limit", 0, "Max requests per minute, 0 - unlimited")
+	scenarios := flag.String("scenarios", "", "Path to JSON file with order scenarios and goods rules")
+	flag.Parse()
+
+	fake := accrualfake.New()
+	if *scenarios != "" {
+		f, err := os.Open(*scenarios)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(2)
+		}
+		err = fake.Load(f)
+		_ = f.Close()
+		if err != nil {
+			fmt.Println(fmt.Sprintf("failed to load scenarios: %v", err.Error()))
+			os.Exit(2)
+		}
+	}
+	// задержка из флага имеет приоритет над задержкой из файла сценариев
+	if *latency != 0 {
+		fake.SetLatency(*latency)
+	}
+	fake.SetRateLimit(*rateLimit, time.Minute)
+
+	srv := &http.Server{Addr: *address, Handler: fake}
+	go func() {
+		log.Info().Msg(fmt.Sprintf("accrual fake is listening on %s", *address))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error().Msg(fmt.Sprintf("accrual fake failed: %v", err.Error()))
+			os.Exit(1)
+		}
+	}()
+
+	notifyCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-notifyCtx.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error().Msg(fmt.Sprintf("accrual fake shutdown failed: %v", err.Error()))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
 	"gophermart/internal/app/configs"
+	"gophermart/internal/app/domain"
 	"gophermart/internal/app/handlers"
 	"gophermart/internal/app/repositories"
 	"gophermart/internal/app/services"
@@ -17,13 +18,14 @@ import (
 	"time"
 )
 
-func initOrderService(orderSender *services.OrderSender, orderRepository *repositories.OrderRepository) *services.OrderService {
-	return services.NewOrderService(orderRepository, orderSender)
-}
-
-func initUserService(db *sqlx.DB, orderRepository *repositories.OrderRepository) *services.UserService {
-	userRepository := repositories.NewUserRepository(db, orderRepository)
-	return services.NewUserService(userRepository)
+func initUserService(
+	db *sqlx.DB,
+	orderRepository *repositories.OrderRepository,
+	ledgerRepository *repositories.LedgerRepository,
+	tiers domain.Tiers,
+) *services.UserService {
+	userRepository := repositories.NewUserRepository(db, orderRepository, ledgerRepository)
+	return services.NewUserService(userRepository, tiers)
 }
 
 func main() {
@@ -42,17 +44,64 @@ func main() {
 	}
 	defer db.Close()
 
-	ordersCh := make(chan string)
 	orderRepository := repositories.NewOrderRepository(db)
-	orderSender := services.NewOrderSender(ordersCh)
-	orderService := initOrderService(orderSender, orderRepository)
-	userService := initUserService(db, orderRepository)
-	// Инициируем хэндлеры для ендпоинтов
-	router := handlers.InitRouter(db, cfg, orderService, userService)
+	orderService := services.NewOrderService(orderRepository)
+	accrualJobService := services.NewAccrualJobService(
+		repositories.NewAccrualJobRepository(db),
+		services.NewBackoff(cfg.AccrualPollInterval, cfg.AccrualPollMaxInterval),
+		services.NewBackoff(cfg.AccrualRetryInterval, cfg.AccrualRetryMaxInterval),
+		cfg.AccrualMaxAttempts,
+	)
+	ledgerRepository := repositories.NewLedgerRepository(db, cfg.PointsLifetime)
+	userService := initUserService(db, orderRepository, ledgerRepository, cfg.Tiers())
+	ledgerService := services.NewLedgerService(ledgerRepository)
+	withdrawalRules := services.NewWithdrawalRules(
+		repositories.NewBalanceRepository(db, ledgerRepository),
+		services.WithdrawalLimits{
+			MinSum:                 cfg.WithdrawalMinSum,
+			MaxSum:                 cfg.WithdrawalMaxSum,
+			DailyCap:               cfg.WithdrawalDailyCap,
+			MonthlyCap:             cfg.WithdrawalMonthlyCap,
+			PasswordChangeCooldown: cfg.WithdrawalPasswordCooldown,
+		},
+	)
+	holdService := services.NewHoldService(
+		repositories.NewHoldRepository(db, ledgerRepository), cfg.HoldTTL, withdrawalRules,
+	)
+	tierService := services.NewTierService(repositories.NewTierRepository(db), cfg.Tiers(), cfg.TierPeriod)
+	campaignService := services.NewCampaignService(repositories.NewCampaignRepository(db, ledgerRepository))
+	referralService := services.NewReferralService(
+		repositories.NewReferralRepository(db, ledgerRepository),
+		services.ReferralRules{Bonus: cfg.ReferralBonus, MaxRewards: cfg.ReferralMaxRewards},
+	)
+	// ограничитель и выключатель общие для всех воркеров, чтобы пауза по Retry-After и недоступность
+	// системы расчета баллов останавливали все запросы к ней
+	accrualCalculator := services.NewAccrualCalculationService(
+		cfg.AccrualSystemAddr,
+		services.NewAccrualRateLimiter(cfg.AccrualRateLimit),
+		services.NewAccrualHTTPClient(services.AccrualClientOptions{
+			ConnectTimeout:  cfg.AccrualConnectTimeout,
+			ResponseTimeout: cfg.AccrualResponseTimeout,
+			RequestTimeout:  cfg.AccrualRequestTimeout,
+			MaxIdleConns:    cfg.AccrualMaxIdleConns,
+		}),
+		services.NewCircuitBreaker(cfg.AccrualBreakerFailureThreshold, cfg.AccrualBreakerOpenTimeout),
+	)
+	idempotencyService := services.NewIdempotencyService(
+		repositories.NewIdempotencyRepository(db), cfg.IdempotencyKeyTTL,
+	)
 	// Запускаем воркеров
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	runner := workers.NewRunner()
-	runner.StartWorkers(ctx, cfg, ordersCh, orderService, userService)
+	runner.StartWorkers(
+		ctx, cfg, accrualCalculator, accrualJobService, orderService, userService, ledgerService, holdService, tierService,
+		campaignService, referralService, idempotencyService,
+	)
+	// Инициируем хэндлеры для ендпоинтов, размер пула воркеров можно менять через API администратора
+	router := handlers.InitRouter(
+		db, cfg, orderService, userService, holdService, tierService, withdrawalRules, campaignService, referralService,
+		accrualCalculator, accrualJobService, idempotencyService, runner.AccrualWorkerPool(),
+	)
 
 	srv := &http.Server{
 		Addr:    cfg.RunAddr,
@@ -77,8 +126,6 @@ func main() {
 		log.Error().Msg(fmt.Sprintf("server shutdown error: %v", err))
 	}
 
-	// после остановки сервера останавливаем отправку ордеров воркерам
-	orderSender.Stop()
 	// выполняем остановку всех воркеров
 	cancelFunc()
 	if ok := runner.WaitWorkersToStop(5 * time.Second); !ok {
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	_ "github.com/jackc/pgx/stdlib"
+	"gophermart/internal/app/configs"
+	"gophermart/internal/app/repositories"
+	"gophermart/internal/app/services"
+	"os"
+)
+
+// Команда сверяет балансы пользователей с журналом операций и выводит найденные расхождения
+// с флагом -fix расходящиеся балансы пересчитываются по журналу
+func main() {
+	fix := flag.Bool("fix", false, "Rebuild mismatched balances from the ledger")
+	cfg, err := configs.InitConfig()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+
+	// сверка не меняет схему, а с флагом -fix изменяет только балансы и партии баллов,
+	// поэтому подключаемся без миграций схемы
+	db, err := repositories.OpenDB(cfg.DatabaseURI)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+	defer db.Close()
+
+	ledgerService := services.NewLedgerService(repositories.NewLedgerRepository(db, cfg.PointsLifetime))
+	report, err := ledgerService.Reconcile(context.Background(), *fix)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+
+	for _, id := range report.UnbalancedTransactions {
+		fmt.Printf("ledger transaction %d is unbalanced\n", id)
+	}
+	for _, m := range report.Mismatches {
+		fmt.Printf(
+			"user %d: balance current=%v withdrawn=%v held=%v, ledger current=%v withdrawn=%v held=%v\n",
+			m.UserID, m.Cached.Current, m.Cached.Withdrawn, m.Cached.Held,
+			m.Ledger.Current, m.Ledger.Withdrawn, m.Ledger.Held,
+		)
+	}
+	if len(report.UnbalancedTransactions) == 0 && len(report.Mismatches) == 0 {
+		fmt.Println("balances match the ledger")
+		return
+	}
+	if *fix && len(report.UnbalancedTransactions) == 0 {
+		fmt.Printf("%d balances rebuilt from the ledger\n", len(report.Mismatches))
+		return
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,71 @@
+package accrualfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+// Scenario сценарий обработки одного заказа в поддельной системе расчета баллов
+type Scenario struct {
+	// RegisterStatus код ответа на регистрацию заказа, 0 - заказ регистрируется как обычно
+	// при любом коде, кроме 202, заказ не регистрируется и запросы результата расчета по нему получают 204
+	RegisterStatus int `json:"register_status"`
+	// Steps ответы на запросы результата расчета по порядку, последний ответ повторяется
+	// если шаги не заданы, используется прогрессия статусов по умолчанию
+	Steps []Step `json:"steps"`
+}
+
+// Step ответ на очередной запрос результата расчета по заказу
+type Step struct {
+	// Status статус расчета: REGISTERED, PROCESSING, INVALID или PROCESSED
+	Status string `json:"status"`
+	// Accrual начисление для статуса PROCESSED, nil - начисление считается по правилам для товаров
+	Accrual *domain.Money `json:"accrual"`
+	// HTTPStatus код ответа вместо результата расчета, например 204, 429 или 500
+	HTTPStatus int `json:"http_status"`
+	// RetryAfter значение заголовка Retry-After в секундах для ответа 429
+	RetryAfter int `json:"retry_after"`
+	// Latency задержка перед ответом
+	Latency Duration `json:"latency"`
+}
+
+// DefaultSteps прогрессия статусов, которую проходит заказ без отдельного сценария
+var DefaultSteps = []Step{
+	{Status: domain.AccrualRegisteredStatus},
+	{Status: domain.OrderProcessingStatus},
+	{Status: domain.OrderProcessedStatus},
+}
+
+// Config сценарии поддельной системы расчета баллов, загружаемые из JSON-файла
+type Config struct {
+	// DefaultSteps прогрессия статусов для заказов без отдельного сценария
+	DefaultSteps []Step `json:"default_steps"`
+	// Orders сценарии для отдельных заказов по их номерам
+	Orders map[string]Scenario `json:"orders"`
+	// Goods правила начисления баллов за товары
+	Goods []GoodsRule `json:"goods"`
+	// Latency задержка перед каждым ответом
+	Latency Duration `json:"latency"`
+}
+
+// Duration длительность, которая в JSON записывается строкой вроде "1.5s" или "200ms"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"1s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
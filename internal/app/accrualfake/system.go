@@ -0,0 +1,377 @@
+// Package accrualfake реализует поддельную систему расчета баллов для локальной разработки и тестов
+//
+// System отвечает на те же запросы, что и настоящая система расчета баллов:
+// POST /api/orders, POST /api/goods и GET /api/orders/{number}
+// ответы по заказам задаются сценариями, что позволяет проверять обработку заказов без внешних зависимостей
+package accrualfake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gophermart/internal/app/domain"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RewardPercent начисление в процентах от цены товара
+	RewardPercent = "%"
+	// RewardPoints фиксированное начисление за товар
+	RewardPoints = "pt"
+
+	ordersPath = "/api/orders"
+	goodsPath  = "/api/goods"
+
+	// defaultRetryAfter значение Retry-After для ответа 429 из сценария, если оно не задано
+	defaultRetryAfter = 60
+)
+
+var (
+	ErrGoodsRuleExists  = fmt.Errorf("goods rule with such match already exists")
+	ErrInvalidGoodsRule = fmt.Errorf("goods rule is invalid")
+)
+
+// Good товар в заказе
+type Good struct {
+	Description string       `json:"description"`
+	Price       domain.Money `json:"price"`
+}
+
+// GoodsRule правило начисления баллов за товары, в описании которых встречается Match
+type GoodsRule struct {
+	Match      string  `json:"match"`
+	Reward     float64 `json:"reward"`
+	RewardType string  `json:"reward_type"`
+}
+
+type orderInput struct {
+	Order string `json:"order"`
+	Goods []Good `json:"goods"`
+}
+
+type orderRes struct {
+	Order   string        `json:"order"`
+	Status  string        `json:"status"`
+	Accrual *domain.Money `json:"accrual,omitempty"`
+}
+
+type order struct {
+	goods []Good
+	polls int
+}
+
+// System поддельная система расчета баллов
+type System struct {
+	mu           sync.Mutex
+	orders       map[string]*order
+	scenarios    map[string]Scenario
+	defaultSteps []Step
+	rules        []GoodsRule
+	latency      time.Duration
+
+	rateLimit      int
+	rateWindow     time.Duration
+	windowStart    time.Time
+	windowRequests int
+}
+
+// New создает поддельную систему расчета баллов без правил для товаров, задержек и ограничения частоты запросов
+func New() *System {
+	return &System{
+		orders:       make(map[string]*order),
+		scenarios:    make(map[string]Scenario),
+		defaultSteps: DefaultSteps,
+	}
+}
+
+// NewServer создает поддельную систему расчета баллов и запускает для нее тестовый HTTP-сервер
+// сервер нужно остановить вызовом Close
+func NewServer() (*System, *httptest.Server) {
+	s := New()
+	return s, httptest.NewServer(s)
+}
+
+// Load применяет сценарии из JSON-описания Config
+func (s *System) Load(r io.Reader) error {
+	var cfg Config
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return err
+	}
+
+	for _, rule := range cfg.Goods {
+		if err := s.AddGoodsRule(rule); err != nil {
+			return fmt.Errorf("%w: %q", err, rule.Match)
+		}
+	}
+	for orderNumber, scenario := range cfg.Orders {
+		s.SetScenario(orderNumber, scenario)
+	}
+	if len(cfg.DefaultSteps) != 0 {
+		s.SetDefaultSteps(cfg.DefaultSteps...)
+	}
+	s.SetLatency(time.Duration(cfg.Latency))
+	return nil
+}
+
+// SetScenario задает сценарий обработки заказа с номером orderNumber
+// счетчик запросов результата расчета по заказу сбрасывается
+func (s *System) SetScenario(orderNumber string, scenario Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scenarios[orderNumber] = scenario
+	if o, ok := s.orders[orderNumber]; ok {
+		o.polls = 0
+	}
+}
+
+// SetDefaultSteps задает прогрессию статусов для заказов без отдельного сценария
+func (s *System) SetDefaultSteps(steps ...Step) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.defaultSteps = steps
+}
+
+// AddGoodsRule добавляет правило начисления баллов за товары
+func (s *System) AddGoodsRule(rule GoodsRule) error {
+	if rule.Match == "" || rule.Reward < 0 || (rule.RewardType != RewardPercent && rule.RewardType != RewardPoints) {
+		return ErrInvalidGoodsRule
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.rules {
+		if r.Match == rule.Match {
+			return ErrGoodsRuleExists
+		}
+	}
+	s.rules = append(s.rules, rule)
+	return nil
+}
+
+// SetLatency задает задержку перед каждым ответом
+func (s *System) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = latency
+}
+
+// SetRateLimit ограничивает количество запросов за окно window, сверх лимита система отвечает 429
+// нулевой requests снимает ограничение
+func (s *System) SetRateLimit(requests int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rateLimit = requests
+	s.rateWindow = window
+	s.windowStart = time.Time{}
+	s.windowRequests = 0
+}
+
+// Polls возвращает количество запросов результата расчета по заказу с номером orderNumber
+func (s *System) Polls(orderNumber string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if o, ok := s.orders[orderNumber]; ok {
+		return o.polls
+	}
+	return 0
+}
+
+func (s *System) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	latency := s.latency
+	s.mu.Unlock()
+	if !sleep(r.Context(), latency) {
+		return
+	}
+
+	if retryAfter, msg, limited := s.takeRequest(time.Now()); limited {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, msg)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == ordersPath:
+		s.registerOrder(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == goodsPath:
+		s.addGoods(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, ordersPath+"/"):
+		s.getOrder(w, r, strings.TrimPrefix(r.URL.Path, ordersPath+"/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// takeRequest учитывает запрос в текущем окне ограничения частоты запросов
+// если лимит исчерпан, возвращает количество секунд до начала следующего окна и текст ответа
+func (s *System) takeRequest(now time.Time) (int, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rateLimit <= 0 {
+		return 0, "", false
+	}
+	if s.windowStart.IsZero() || now.Sub(s.windowStart) >= s.rateWindow {
+		s.windowStart = now
+		s.windowRequests = 0
+	}
+	if s.windowRequests >= s.rateLimit {
+		wait := s.rateWindow - now.Sub(s.windowStart)
+		msg := fmt.Sprintf("No more than %d requests per %v allowed", s.rateLimit, s.rateWindow)
+		if s.rateWindow == time.Minute {
+			msg = fmt.Sprintf("No more than %d requests per minute allowed", s.rateLimit)
+		}
+		return int(math.Ceil(wait.Seconds())), msg, true
+	}
+	s.windowRequests++
+	return 0, "", false
+}
+
+func (s *System) registerOrder(w http.ResponseWriter, r *http.Request) {
+	var input orderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Order == "" {
+		http.Error(w, "invalid order", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if scenario, ok := s.scenarios[input.Order]; ok && scenario.RegisterStatus != 0 && scenario.RegisterStatus != http.StatusAccepted {
+		w.WriteHeader(scenario.RegisterStatus)
+		return
+	}
+	if _, ok := s.orders[input.Order]; ok {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	s.orders[input.Order] = &order{goods: input.Goods}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *System) addGoods(w http.ResponseWriter, r *http.Request) {
+	var rule GoodsRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "invalid goods rule", http.StatusBadRequest)
+		return
+	}
+
+	switch err := s.AddGoodsRule(rule); err {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case ErrGoodsRuleExists:
+		w.WriteHeader(http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+func (s *System) getOrder(w http.ResponseWriter, r *http.Request, orderNumber string) {
+	step, accrual, ok := s.nextStep(orderNumber)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if !sleep(r.Context(), time.Duration(step.Latency)) {
+		return
+	}
+
+	if step.HTTPStatus != 0 && step.HTTPStatus != http.StatusOK {
+		if step.HTTPStatus == http.StatusTooManyRequests {
+			retryAfter := step.RetryAfter
+			if retryAfter == 0 {
+				retryAfter = defaultRetryAfter
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		}
+		w.WriteHeader(step.HTTPStatus)
+		return
+	}
+
+	res := orderRes{Order: orderNumber, Status: step.Status}
+	if step.Status == domain.OrderProcessedStatus {
+		res.Accrual = &accrual
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&res)
+}
+
+// nextStep возвращает очередной шаг сценария для зарегистрированного заказа и начисление по нему
+func (s *System) nextStep(orderNumber string) (Step, domain.Money, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[orderNumber]
+	if !ok {
+		return Step{}, 0, false
+	}
+
+	steps := s.defaultSteps
+	if scenario, ok := s.scenarios[orderNumber]; ok && len(scenario.Steps) != 0 {
+		steps = scenario.Steps
+	}
+	if len(steps) == 0 {
+		steps = DefaultSteps
+	}
+	idx := o.polls
+	if idx >= len(steps) {
+		idx = len(steps) - 1
+	}
+	o.polls++
+
+	step := steps[idx]
+	if step.Accrual != nil {
+		return step, *step.Accrual, true
+	}
+	return step, s.calculateAccrual(o.goods), true
+}
+
+// calculateAccrual считает начисление за товары по правилам, за товар начисляется по каждому подходящему правилу
+func (s *System) calculateAccrual(goods []Good) domain.Money {
+	var accrual domain.Money
+	for _, good := range goods {
+		for _, rule := range s.rules {
+			if !strings.Contains(good.Description, rule.Match) {
+				continue
+			}
+			if rule.RewardType == RewardPercent {
+				accrual += good.Price.Multiply(rule.Reward / 100)
+			} else {
+				accrual += domain.NewMoney(1, 0).Multiply(rule.Reward)
+			}
+		}
+	}
+	return accrual
+}
+
+// sleep ждет d или отмены контекста, возвращает false, если контекст был отменен
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,226 @@
+package accrualfake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) (*System, *httptest.Server) {
+	fake, srv := NewServer()
+	t.Cleanup(srv.Close)
+	return fake, srv
+}
+
+func registerOrder(t *testing.T, srv *httptest.Server, body string) int {
+	resp, err := http.Post(srv.URL+"/api/orders", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+type pollRes struct {
+	statusCode int
+	retryAfter string
+	order      domain.AccrualCalculationRes
+	body       string
+}
+
+func pollOrder(t *testing.T, srv *httptest.Server, orderNumber string) pollRes {
+	resp, err := http.Get(srv.URL + "/api/orders/" + orderNumber)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	res := pollRes{statusCode: resp.StatusCode, retryAfter: resp.Header.Get("Retry-After"), body: string(body)}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.Unmarshal(body, &res.order))
+	}
+	return res
+}
+
+func TestSystem_DefaultProgression(t *testing.T) {
+	fake, srv := newTestServer(t)
+	require.NoError(t, fake.AddGoodsRule(GoodsRule{Match: "Bork", Reward: 10, RewardType: RewardPercent}))
+	require.NoError(t, fake.AddGoodsRule(GoodsRule{Match: "чайник", Reward: 15.5, RewardType: RewardPoints}))
+
+	assert.Equal(t, http.StatusNoContent, pollOrder(t, srv, "12345678903").statusCode)
+
+	body := `{"order": "12345678903", "goods": [{"description": "Чайник Bork", "price": 7000}, {"description": "Bork чайник", "price": 99.99}]}`
+	assert.Equal(t, http.StatusAccepted, registerOrder(t, srv, body))
+	assert.Equal(t, http.StatusConflict, registerOrder(t, srv, body))
+
+	var statuses []string
+	for i := 0; i < 4; i++ {
+		res := pollOrder(t, srv, "12345678903")
+		require.Equal(t, http.StatusOK, res.statusCode)
+		statuses = append(statuses, res.order.Status)
+	}
+	assert.Equal(t, []string{
+		domain.AccrualRegisteredStatus,
+		domain.OrderProcessingStatus,
+		domain.OrderProcessedStatus,
+		domain.OrderProcessedStatus,
+	}, statuses)
+
+	// 700 + 10 + 15.5 баллов
+	res := pollOrder(t, srv, "12345678903")
+	assert.Equal(t, domain.NewMoney(725, 50), res.order.Accrual)
+	assert.Equal(t, 5, fake.Polls("12345678903"))
+}
+
+func TestSystem_Scenario(t *testing.T) {
+	accrual := domain.NewMoney(500, 0)
+
+	tests := []struct {
+		name        string
+		scenario    Scenario
+		wantCodes   []int
+		wantStatus  string
+		wantAccrual domain.Money
+		retryAfter  string
+	}{
+		{
+			name:       "invalid order",
+			scenario:   Scenario{Steps: []Step{{Status: domain.OrderProcessingStatus}, {Status: domain.OrderInvalidStatus}}},
+			wantCodes:  []int{http.StatusOK, http.StatusOK},
+			wantStatus: domain.OrderInvalidStatus,
+		},
+		{
+			name: "too many requests before result",
+			scenario: Scenario{Steps: []Step{
+				{HTTPStatus: http.StatusTooManyRequests, RetryAfter: 3},
+				{Status: domain.OrderProcessedStatus, Accrual: &accrual},
+			}},
+			wantCodes:   []int{http.StatusTooManyRequests, http.StatusOK},
+			wantStatus:  domain.OrderProcessedStatus,
+			wantAccrual: accrual,
+			retryAfter:  "3",
+		},
+		{
+			name: "no content and server error",
+			scenario: Scenario{Steps: []Step{
+				{HTTPStatus: http.StatusNoContent},
+				{HTTPStatus: http.StatusInternalServerError},
+				{Status: domain.OrderProcessedStatus},
+			}},
+			wantCodes:  []int{http.StatusNoContent, http.StatusInternalServerError, http.StatusOK},
+			wantStatus: domain.OrderProcessedStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, srv := newTestServer(t)
+			fake.SetScenario("79927398713", tt.scenario)
+			require.Equal(t, http.StatusAccepted, registerOrder(t, srv, `{"order": "79927398713"}`))
+
+			var res pollRes
+			for i, code := range tt.wantCodes {
+				res = pollOrder(t, srv, "79927398713")
+				require.Equal(t, code, res.statusCode)
+				if code == http.StatusTooManyRequests {
+					assert.Equal(t, tt.retryAfter, res.retryAfter)
+				}
+				if i == len(tt.wantCodes)-1 {
+					assert.Equal(t, tt.wantStatus, res.order.Status)
+					assert.Equal(t, tt.wantAccrual, res.order.Accrual)
+				}
+			}
+		})
+	}
+}
+
+func TestSystem_RegisterStatus(t *testing.T) {
+	fake, srv := newTestServer(t)
+	fake.SetScenario("79927398713", Scenario{RegisterStatus: http.StatusInternalServerError})
+
+	assert.Equal(t, http.StatusInternalServerError, registerOrder(t, srv, `{"order": "79927398713"}`))
+	assert.Equal(t, http.StatusNoContent, pollOrder(t, srv, "79927398713").statusCode)
+	assert.Equal(t, http.StatusBadRequest, registerOrder(t, srv, `{"goods": []}`))
+}
+
+func TestSystem_RateLimit(t *testing.T) {
+	fake, srv := newTestServer(t)
+	fake.SetRateLimit(2, time.Minute)
+
+	assert.Equal(t, http.StatusNoContent, pollOrder(t, srv, "1").statusCode)
+	assert.Equal(t, http.StatusNoContent, pollOrder(t, srv, "1").statusCode)
+
+	res := pollOrder(t, srv, "1")
+	assert.Equal(t, http.StatusTooManyRequests, res.statusCode)
+	assert.Equal(t, "60", res.retryAfter)
+	assert.Equal(t, "No more than 2 requests per minute allowed", res.body)
+
+	fake.SetRateLimit(0, 0)
+	assert.Equal(t, http.StatusNoContent, pollOrder(t, srv, "1").statusCode)
+}
+
+func TestSystem_Latency(t *testing.T) {
+	fake, srv := newTestServer(t)
+	fake.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/orders/1", nil)
+	require.NoError(t, err)
+	_, err = http.DefaultClient.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	fake.SetLatency(0)
+	fake.SetScenario("2", Scenario{Steps: []Step{{Status: domain.OrderProcessingStatus, Latency: Duration(30 * time.Millisecond)}}})
+	require.Equal(t, http.StatusAccepted, registerOrder(t, srv, `{"order": "2"}`))
+	start := time.Now()
+	assert.Equal(t, http.StatusOK, pollOrder(t, srv, "2").statusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestSystem_Goods(t *testing.T) {
+	_, srv := newTestServer(t)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "new rule", body: `{"match": "Bork", "reward": 10, "reward_type": "%"}`, want: http.StatusOK},
+		{name: "duplicate rule", body: `{"match": "Bork", "reward": 5, "reward_type": "pt"}`, want: http.StatusConflict},
+		{name: "unknown reward type", body: `{"match": "LG", "reward": 5, "reward_type": "usd"}`, want: http.StatusBadRequest},
+		{name: "malformed body", body: `{"match":`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+"/api/goods", "application/json", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
+
+func TestSystem_Load(t *testing.T) {
+	fake, srv := newTestServer(t)
+	cfg := `{
+		"default_steps": [{"status": "PROCESSED", "accrual": 42}],
+		"orders": {"2": {"steps": [{"status": "INVALID", "latency": "1ms"}]}},
+		"goods": [{"match": "Bork", "reward": 10, "reward_type": "%"}]
+	}`
+	require.NoError(t, fake.Load(bytes.NewBufferString(cfg)))
+
+	require.Equal(t, http.StatusAccepted, registerOrder(t, srv, `{"order": "1"}`))
+	require.Equal(t, http.StatusAccepted, registerOrder(t, srv, `{"order": "2"}`))
+	assert.Equal(t, domain.NewMoney(42, 0), pollOrder(t, srv, "1").order.Accrual)
+	assert.Equal(t, domain.OrderInvalidStatus, pollOrder(t, srv, "2").order.Status)
+
+	assert.Error(t, New().Load(bytes.NewBufferString(`{"orders": {"1": {"steps": [{"latency": 5}]}}}`)))
+	assert.Error(t, New().Load(bytes.NewBufferString(`{"unknown": true}`)))
+}
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"github.com/caarlos0/env/v6"
+	"gophermart/internal/app/domain"
+	"time"
 )
 
 type Config struct {
@@ -11,6 +13,112 @@ type Config struct {
 	RunAddr           string `env:"RUN_ADDRESS"`
 	DatabaseURI       string `env:"DATABASE_URI"`
 	AuthSecretKey     string `env:"AUTH_SECRET_KEY"`
+	// AccrualWorkers количество воркеров, обрабатывающих заказы, при запуске сервиса
+	AccrualWorkers int `env:"ACCRUAL_WORKERS" envDefault:"2"`
+	// AccrualRateLimit максимальное количество запросов в секунду к системе расчета баллов от всех воркеров, 0 - без ограничения
+	AccrualRateLimit int `env:"ACCRUAL_RATE_LIMIT" envDefault:"0"`
+	// ограничения времени запросов к системе расчета баллов и размер пула соединений
+	AccrualConnectTimeout  time.Duration `env:"ACCRUAL_CONNECT_TIMEOUT" envDefault:"5s"`
+	AccrualResponseTimeout time.Duration `env:"ACCRUAL_RESPONSE_TIMEOUT" envDefault:"10s"`
+	AccrualRequestTimeout  time.Duration `env:"ACCRUAL_REQUEST_TIMEOUT" envDefault:"30s"`
+	AccrualMaxIdleConns    int           `env:"ACCRUAL_MAX_IDLE_CONNS" envDefault:"10"`
+	// выключатель запросов к системе расчета баллов размыкается после AccrualBreakerFailureThreshold ошибок подряд
+	// и не пропускает запросы в течение AccrualBreakerOpenTimeout
+	AccrualBreakerFailureThreshold int           `env:"ACCRUAL_BREAKER_FAILURE_THRESHOLD" envDefault:"5"`
+	AccrualBreakerOpenTimeout      time.Duration `env:"ACCRUAL_BREAKER_OPEN_TIMEOUT" envDefault:"30s"`
+	// паузы между запросами результата расчета по заказу и между повторными попытками после ошибок
+	// удваиваются с каждым запросом, начиная с первого значения и не превышая второго
+	AccrualPollInterval     time.Duration `env:"ACCRUAL_POLL_INTERVAL" envDefault:"3s"`
+	AccrualPollMaxInterval  time.Duration `env:"ACCRUAL_POLL_MAX_INTERVAL" envDefault:"5m"`
+	AccrualRetryInterval    time.Duration `env:"ACCRUAL_RETRY_INTERVAL" envDefault:"10s"`
+	AccrualRetryMaxInterval time.Duration `env:"ACCRUAL_RETRY_MAX_INTERVAL" envDefault:"10m"`
+	// AccrualMaxAttempts количество неудачных попыток обработать заказ, после которого заказ откладывается
+	// до решения администратора, 0 - без ограничения
+	AccrualMaxAttempts int `env:"ACCRUAL_MAX_ATTEMPTS" envDefault:"10"`
+	// IdempotencyKeyTTL время, в течение которого хранится ответ на запрос с ключом идемпотентности
+	IdempotencyKeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	// IdempotencyKeyPurgeInterval интервал между удалениями ключей идемпотентности с истекшим сроком действия
+	IdempotencyKeyPurgeInterval time.Duration `env:"IDEMPOTENCY_KEY_PURGE_INTERVAL" envDefault:"1h"`
+	// PointsLifetime время, через которое сгорают начисленные баллы, 0 - баллы не сгорают
+	PointsLifetime time.Duration `env:"POINTS_LIFETIME" envDefault:"8760h"`
+	// PointsExpiringSoonPeriod период, за который пользователю показываются сгорающие баллы
+	PointsExpiringSoonPeriod time.Duration `env:"POINTS_EXPIRING_SOON_PERIOD" envDefault:"720h"`
+	// PointsExpirationInterval интервал между проверками сгоревших баллов
+	PointsExpirationInterval time.Duration `env:"POINTS_EXPIRATION_INTERVAL" envDefault:"1h"`
+	// ограничения на переводы баллов между пользователями, 0 - без ограничения
+	TransferMinSum     domain.Money `env:"TRANSFER_MIN_SUM" envDefault:"1"`
+	TransferMaxSum     domain.Money `env:"TRANSFER_MAX_SUM" envDefault:"0"`
+	TransferDailyLimit domain.Money `env:"TRANSFER_DAILY_LIMIT" envDefault:"0"`
+	// HoldTTL время, через которое неподтвержденный резерв баллов снимается автоматически
+	HoldTTL time.Duration `env:"HOLD_TTL" envDefault:"15m"`
+	// HoldSweepInterval интервал между проверками просроченных резервов
+	HoldSweepInterval time.Duration `env:"HOLD_SWEEP_INTERVAL" envDefault:"1m"`
+	// пороги уровней программы лояльности по баллам, заработанным за TierPeriod, и множители начислений
+	TierSilverThreshold    domain.Money  `env:"TIER_SILVER_THRESHOLD" envDefault:"1000"`
+	TierSilverMultiplier   float64       `env:"TIER_SILVER_MULTIPLIER" envDefault:"1.1"`
+	TierGoldThreshold      domain.Money  `env:"TIER_GOLD_THRESHOLD" envDefault:"5000"`
+	TierGoldMultiplier     float64       `env:"TIER_GOLD_MULTIPLIER" envDefault:"1.25"`
+	TierPlatinumThreshold  domain.Money  `env:"TIER_PLATINUM_THRESHOLD" envDefault:"20000"`
+	TierPlatinumMultiplier float64       `env:"TIER_PLATINUM_MULTIPLIER" envDefault:"1.5"`
+	TierPeriod             time.Duration `env:"TIER_PERIOD" envDefault:"8760h"`
+	// правила списания баллов, 0 - без ограничения
+	WithdrawalMinSum     domain.Money `env:"WITHDRAWAL_MIN_SUM" envDefault:"0"`
+	WithdrawalMaxSum     domain.Money `env:"WITHDRAWAL_MAX_SUM" envDefault:"0"`
+	WithdrawalDailyCap   domain.Money `env:"WITHDRAWAL_DAILY_CAP" envDefault:"0"`
+	WithdrawalMonthlyCap domain.Money `env:"WITHDRAWAL_MONTHLY_CAP" envDefault:"0"`
+	// WithdrawalPasswordCooldown время после смены пароля, в течение которого списания запрещены
+	WithdrawalPasswordCooldown time.Duration `env:"WITHDRAWAL_PASSWORD_COOLDOWN" envDefault:"24h"`
+	// ReferralBonus сумма, начисляемая пригласившему и приглашенному пользователю после первого обработанного заказа
+	ReferralBonus domain.Money `env:"REFERRAL_BONUS" envDefault:"100"`
+	// ReferralMaxRewards максимальное количество бонусов за приглашения у одного пользователя, 0 - без ограничения
+	ReferralMaxRewards int `env:"REFERRAL_MAX_REWARDS" envDefault:"10"`
+	// не больше VoucherRedeemRateLimit попыток погашения ваучеров за VoucherRedeemRateWindow с одного пользователя и IP
+	VoucherRedeemRateLimit  int           `env:"VOUCHER_REDEEM_RATE_LIMIT" envDefault:"5"`
+	VoucherRedeemRateWindow time.Duration `env:"VOUCHER_REDEEM_RATE_WINDOW" envDefault:"1m"`
+	// AdjustmentApprovalThreshold сумма ручной корректировки баланса, выше которой нужно подтверждение второго администратора
+	AdjustmentApprovalThreshold domain.Money `env:"ADJUSTMENT_APPROVAL_THRESHOLD" envDefault:"1000"`
+	// TierRecalculationInterval интервал между пересчетами уровней пользователей
+	TierRecalculationInterval time.Duration `env:"TIER_RECALCULATION_INTERVAL" envDefault:"24h"`
+	// TrustedProxies адреса и подсети прокси, которым доверяем заголовки с IP клиента, по умолчанию не доверяем никому
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:","`
+}
+
+// Tiers возвращает уровни программы лояльности, заданные в настройках
+func (cfg *Config) Tiers() domain.Tiers {
+	return domain.Tiers{
+		{Name: domain.TierSilver, Threshold: cfg.TierSilverThreshold, Multiplier: cfg.TierSilverMultiplier},
+		{Name: domain.TierGold, Threshold: cfg.TierGoldThreshold, Multiplier: cfg.TierGoldMultiplier},
+		{Name: domain.TierPlatinum, Threshold: cfg.TierPlatinumThreshold, Multiplier: cfg.TierPlatinumMultiplier},
+	}
+}
+
+// intervals возвращает интервалы, которые должны быть больше нуля: с ними запускаются периодические воркеры
+// и рассчитываются паузы между запросами к системе расчета баллов
+func (cfg *Config) intervals() []struct {
+	name  string
+	value time.Duration
+} {
+	return []struct {
+		name  string
+		value time.Duration
+	}{
+		{name: "POINTS_EXPIRATION_INTERVAL", value: cfg.PointsExpirationInterval},
+		{name: "HOLD_SWEEP_INTERVAL", value: cfg.HoldSweepInterval},
+		{name: "TIER_RECALCULATION_INTERVAL", value: cfg.TierRecalculationInterval},
+		{name: "IDEMPOTENCY_KEY_PURGE_INTERVAL", value: cfg.IdempotencyKeyPurgeInterval},
+		{name: "ACCRUAL_POLL_INTERVAL", value: cfg.AccrualPollInterval},
+		{name: "ACCRUAL_RETRY_INTERVAL", value: cfg.AccrualRetryInterval},
+	}
+}
+
+// validateIntervals проверяет, что все интервалы больше нуля
+func (cfg *Config) validateIntervals() error {
+	for _, interval := range cfg.intervals() {
+		if interval.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %v", interval.name, interval.value)
+		}
+	}
+	return nil
 }
 
 // InitFlags иницирует флаги, используемые при запуске сервера
@@ -20,6 +128,10 @@ func InitFlags(cfg *Config) {
 	)
 	flag.StringVar(&cfg.RunAddr, "a", cfg.RunAddr, "Service address and port")
 	flag.StringVar(&cfg.DatabaseURI, "d", cfg.DatabaseURI, "Database connection address")
+	flag.IntVar(&cfg.AccrualWorkers, "accrual-workers", cfg.AccrualWorkers, "Number of order accrual workers")
+	flag.DurationVar(&cfg.IdempotencyKeyTTL, "idempotency-ttl", cfg.IdempotencyKeyTTL, "Idempotency keys lifetime")
+	flag.DurationVar(&cfg.PointsLifetime, "points-lifetime", cfg.PointsLifetime, "Lifetime of accrued points")
+	flag.DurationVar(&cfg.HoldTTL, "hold-ttl", cfg.HoldTTL, "Lifetime of uncaptured balance holds")
 }
 
 func InitConfig() (*Config, error) {
@@ -34,6 +146,18 @@ func InitConfig() (*Config, error) {
 	// Переписываем содержимое конфигна значениями из переданных флагов
 	flag.Parse()
 
+	// уровни программы лояльности должны быть упорядочены по возрастанию порога и не уменьшать начисления
+	if err := cfg.Tiers().Validate(); err != nil {
+		fmt.Println(err.Error())
+		return nil, err
+	}
+
+	// нулевой или отрицательный интервал остановил бы запуск периодического воркера
+	if err := cfg.validateIntervals(); err != nil {
+		fmt.Println(err.Error())
+		return nil, err
+	}
+
 	// если secret_key не задан, то задаем просто рандомное значение
 	if cfg.AuthSecretKey == "" {
 		cfg.AuthSecretKey = generateRandomKey(secretKeyLen)
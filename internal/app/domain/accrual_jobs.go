@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Этапы задания на обработку заказа системой расчета баллов
+const (
+	// AccrualJobRegisterStage заказ еще нужно зарегистрировать в системе расчета баллов
+	AccrualJobRegisterStage = "REGISTER"
+	// AccrualJobPollStage заказ зарегистрирован, нужно запрашивать результат расчета
+	AccrualJobPollStage = "POLL"
+)
+
+// AccrualWorkerPoolStatus состояние пула воркеров, обрабатывающих заказы
+type AccrualWorkerPoolStatus struct {
+	Size int `json:"size"`
+}
+
+// AccrualJob задание на обработку заказа системой расчета баллов
+// задания хранятся в базе данных, поэтому заказы не теряются при перезапуске сервиса
+type AccrualJob struct {
+	ID          int64  `json:"id" db:"id"`
+	OrderNumber string `json:"order" db:"order_number"`
+	Stage       string `json:"stage" db:"stage"`
+	// Attempts количество неудачных попыток выполнить задание подряд, сбрасывается после успешной попытки
+	Attempts int `json:"attempts" db:"attempts"`
+	// Polls количество запросов результата расчета, на которые система ответила, что заказ еще не рассчитан
+	Polls         int       `json:"polls" db:"polls"`
+	NextAttemptAt time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	// LockedUntil время, до которого задание захвачено воркером и не выдается другим воркерам
+	LockedUntil *time.Time `json:"-" db:"locked_until"`
+	// LockedBy случайный токен захвата: изменить задание может только воркер, который его захватил,
+	// даже если захват истек и задание досталось воркеру другого экземпляра сервиса
+	LockedBy string `json:"-" db:"locked_by"`
+	// LastError текст последней ошибки при выполнении задания
+	LastError string `json:"last_error,omitempty" db:"last_error"`
+	// DeadLetteredAt время, когда задание было отложено после слишком большого количества неудачных попыток
+	// такие задания не выполняются, пока администратор не вернет их в очередь
+	DeadLetteredAt *time.Time `json:"dead_lettered_at,omitempty" db:"dead_lettered_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
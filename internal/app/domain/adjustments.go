@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Статусы ручных корректировок баланса
+// корректировка на сумму выше порога ожидает подтверждения вторым администратором в статусе PENDING
+const (
+	AdjustmentPendingStatus  = "PENDING"
+	AdjustmentAppliedStatus  = "APPLIED"
+	AdjustmentRejectedStatus = "REJECTED"
+)
+
+// BalanceAdjustment ручная корректировка баланса пользователя администратором
+// положительная сумма начисляет баллы, отрицательная - списывает
+type BalanceAdjustment struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"user_id" db:"user_id"`
+	Amount      Money      `json:"amount" db:"amount"`
+	Reason      string     `json:"reason" db:"reason"`
+	Status      string     `json:"status" db:"status"`
+	RequestedBy int        `json:"requested_by" db:"requested_by"`
+	ReviewedBy  *int       `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+}
+
+// Действия администраторов, сохраняемые в журнале аудита
+const (
+	AuditActionAdjustmentRequested = "ADJUSTMENT_REQUESTED"
+	AuditActionAdjustmentApplied   = "ADJUSTMENT_APPLIED"
+	AuditActionAdjustmentRejected  = "ADJUSTMENT_REJECTED"
+	AuditActionAccrualJobRequeued  = "ACCRUAL_JOB_REQUEUED"
+)
+
+// AuditLogEntry запись журнала аудита о действии администратора
+type AuditLogEntry struct {
+	ID         int64     `json:"id" db:"id"`
+	ActorID    int       `json:"actor_id" db:"actor_id"`
+	Action     string    `json:"action" db:"action"`
+	EntityType string    `json:"entity_type" db:"entity_type"`
+	EntityID   int       `json:"entity_id" db:"entity_id"`
+	Details    string    `json:"details" db:"details"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
@@ -3,14 +3,61 @@ package domain
 import "time"
 
 type BalanceData struct {
-	Current   float32 `json:"current" db:"current"`
-	Withdrawn float32 `json:"withdrawn" db:"withdrawn"`
+	Current   Money             `json:"current" db:"current"`
+	Withdrawn Money             `json:"withdrawn" db:"withdrawn"`
+	Held      Money             `json:"held" db:"held"`
+	Expiring  []*ExpiringPoints `json:"expiring,omitempty" db:"-"`
 }
 
+// ExpiringPoints баллы, которые сгорят в указанное время, если не будут потрачены
+type ExpiringPoints struct {
+	Sum       Money     `json:"sum" db:"sum"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+const (
+	WithdrawalCompletedStatus         = "COMPLETED"
+	WithdrawalPartiallyReversedStatus = "PARTIALLY_REVERSED"
+	WithdrawalReversedStatus          = "REVERSED"
+)
+
 type Withdrawal struct {
 	ID          int       `json:"-" db:"id"`
 	Order       string    `json:"order" db:"order_number"`
-	Sum         float32   `json:"sum" db:"sum"`
+	Sum         Money     `json:"sum" db:"sum"`
 	ProcessedAt time.Time `json:"processed_at" db:"processed_at"`
 	UserID      int       `json:"-" db:"user_id"`
+	Status      string    `json:"status" db:"-"`
+	ReversedSum Money     `json:"reversed_sum,omitempty" db:"reversed_sum"`
+	// LotsTransactionID операция журнала, израсходовавшая партии баллов для списания
+	LotsTransactionID int64 `json:"-" db:"lots_transaction_id"`
+}
+
+// WithdrawalCap ограничение на сумму списаний и активных резервов пользователя начиная с момента Since
+// при превышении ограничения Limit возвращается ошибка Err
+type WithdrawalCap struct {
+	Limit Money
+	Since time.Time
+	Err   error
+}
+
+// WithdrawalStatus определяет статус списания по сумме возвращенных баллов
+func WithdrawalStatus(sum Money, reversedSum Money) string {
+	if reversedSum == 0 {
+		return WithdrawalCompletedStatus
+	}
+	if reversedSum < sum {
+		return WithdrawalPartiallyReversedStatus
+	}
+	return WithdrawalReversedStatus
+}
+
+// WithdrawalReversal возврат баллов, списанных в счет заказа
+type WithdrawalReversal struct {
+	ID           int       `json:"id" db:"id"`
+	WithdrawalID int       `json:"withdrawal_id" db:"withdrawal_id"`
+	Sum          Money     `json:"sum" db:"sum"`
+	Reason       string    `json:"reason" db:"reason"`
+	AdminID      int       `json:"-" db:"admin_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// Виды промо-акций
+// MULTIPLIER начисляет дополнительно начисление за заказ, умноженное на Multiplier - 1,
+// FIXED_BONUS начисляет фиксированный бонус Bonus
+const (
+	CampaignTypeMultiplier = "MULTIPLIER"
+	CampaignTypeFixedBonus = "FIXED_BONUS"
+)
+
+// Campaign промо-акция, действующая с StartsAt до EndsAt
+// если FirstOrderOnly установлен, бонус начисляется только за первый обработанный заказ пользователя
+type Campaign struct {
+	ID             int       `json:"id" db:"id"`
+	Name           string    `json:"name" db:"name"`
+	Type           string    `json:"type" db:"type"`
+	Multiplier     float64   `json:"multiplier,omitempty" db:"multiplier"`
+	Bonus          Money     `json:"bonus,omitempty" db:"bonus"`
+	FirstOrderOnly bool      `json:"first_order_only" db:"first_order_only"`
+	StartsAt       time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt         time.Time `json:"ends_at" db:"ends_at"`
+	Active         bool      `json:"active" db:"active"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// BonusFor возвращает бонус по акции за заказ с начислением accrual
+func (c *Campaign) BonusFor(accrual Money) Money {
+	switch c.Type {
+	case CampaignTypeMultiplier:
+		return accrual.Multiply(c.Multiplier) - accrual
+	case CampaignTypeFixedBonus:
+		return c.Bonus
+	default:
+		return 0
+	}
+}
+
+// CampaignOrder обработанный заказ, за который могут начисляться бонусы по акциям
+type CampaignOrder struct {
+	Number  string
+	UserID  int
+	Accrual Money
+	// FirstProcessedOrder заказ загружен раньше остальных обработанных заказов пользователя
+	FirstProcessedOrder bool
+}
+
+// CampaignBonus бонус, начисленный пользователю по акции за заказ
+type CampaignBonus struct {
+	ID          int       `json:"id" db:"id"`
+	CampaignID  int       `json:"campaign_id" db:"campaign_id"`
+	OrderNumber string    `json:"order" db:"order_number"`
+	UserID      int       `json:"-" db:"user_id"`
+	Sum         Money     `json:"sum" db:"sum"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+// Состояния автоматического выключателя запросов к системе расчета баллов
+// в состоянии OPEN запросы не выполняются, в состоянии HALF_OPEN выполняется один пробный запрос
+const (
+	CircuitClosed   = "CLOSED"
+	CircuitOpen     = "OPEN"
+	CircuitHalfOpen = "HALF_OPEN"
+)
+
+// CircuitBreakerStats состояние и счетчики автоматического выключателя
+type CircuitBreakerStats struct {
+	State               string `json:"state"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	FailuresTotal       int64  `json:"failures_total"`
+	RejectedTotal       int64  `json:"rejected_total"`
+	OpenedTotal         int64  `json:"opened_total"`
+}
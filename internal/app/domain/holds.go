@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Статусы резервирования баллов
+const (
+	HoldActiveStatus   = "ACTIVE"
+	HoldCapturedStatus = "CAPTURED"
+	HoldVoidedStatus   = "VOIDED"
+	HoldExpiredStatus  = "EXPIRED"
+)
+
+// BalanceHold резервирование баллов пользователя в счет заказа до завершения оплаты
+// при подтверждении резерв превращается в списание, при отмене или по истечении срока баллы возвращаются на счет
+type BalanceHold struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"-" db:"user_id"`
+	Order      string     `json:"order" db:"order_number"`
+	Sum        Money      `json:"sum" db:"sum"`
+	Status     string     `json:"status" db:"status"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	// LotsTransactionID операция журнала, израсходовавшая партии баллов при резервировании
+	LotsTransactionID int64 `json:"-" db:"lots_transaction_id"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// IdempotentResponse сохраненный ответ на запрос, выполненный с ключом идемпотентности
+type IdempotentResponse struct {
+	StatusCode  int    `db:"status_code"`
+	ContentType string `db:"content_type"`
+	Body        []byte `db:"response_body"`
+}
+
+// IdempotencyRecord запись о запросе пользователя с ключом идемпотентности
+// если Response не задан, то запрос с данным ключом еще выполняется
+type IdempotencyRecord struct {
+	UserID      int                 `db:"user_id"`
+	Key         string              `db:"key"`
+	Fingerprint string              `db:"fingerprint"`
+	ExpiresAt   time.Time           `db:"expires_at"`
+	Response    *IdempotentResponse `db:"-"`
+}
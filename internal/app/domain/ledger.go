@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+// Счета, на которые записываются проводки
+// счета CURRENT, WITHDRAWN и HELD принадлежат пользователю, остальные счета - системные
+const (
+	LedgerAccountCurrent     = "CURRENT"
+	LedgerAccountWithdrawn   = "WITHDRAWN"
+	LedgerAccountHeld        = "HELD"
+	LedgerAccountAccruals    = "SYSTEM_ACCRUALS"
+	LedgerAccountAdjustments = "SYSTEM_ADJUSTMENTS"
+	LedgerAccountOpening     = "SYSTEM_OPENING"
+	LedgerAccountExpired     = "SYSTEM_EXPIRED"
+	LedgerAccountCampaigns   = "SYSTEM_CAMPAIGNS"
+	LedgerAccountReferrals   = "SYSTEM_REFERRALS"
+	LedgerAccountVouchers    = "SYSTEM_VOUCHERS"
+)
+
+// Виды операций, изменяющих баланс
+const (
+	LedgerOperationOpening    = "OPENING"
+	LedgerOperationAccrual    = "ACCRUAL"
+	LedgerOperationWithdrawal = "WITHDRAWAL"
+	LedgerOperationAdjustment = "ADJUSTMENT"
+	LedgerOperationReversal   = "REVERSAL"
+	LedgerOperationExpiration = "EXPIRATION"
+	LedgerOperationTransfer   = "TRANSFER"
+	LedgerOperationHold       = "HOLD"
+	LedgerOperationCapture    = "CAPTURE"
+	LedgerOperationRelease    = "RELEASE"
+	LedgerOperationCampaign   = "CAMPAIGN_BONUS"
+	LedgerOperationReferral   = "REFERRAL_BONUS"
+	LedgerOperationVoucher    = "VOUCHER_REDEMPTION"
+)
+
+// LedgerEntry запись об изменении суммы на счете
+// для системных счетов UserID равен 0
+type LedgerEntry struct {
+	ID            int64     `db:"id"`
+	TransactionID int64     `db:"transaction_id"`
+	Account       string    `db:"account"`
+	UserID        int       `db:"user_id"`
+	Amount        Money     `db:"amount"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// LedgerTransaction операция, состоящая из записей, сумма которых равна нулю
+type LedgerTransaction struct {
+	ID        int64     `db:"id"`
+	Operation string    `db:"operation"`
+	Reference string    `db:"reference"`
+	CreatedAt time.Time `db:"created_at"`
+	Entries   []*LedgerEntry
+	// PreservesExpiry операция перемещает уже начисленные баллы пользователя, а не начисляет новые:
+	// поступления на счет CURRENT получают сроки сгорания партий, израсходованных операцией LotsSourceID,
+	// а если LotsSourceID равен 0 - израсходованных этой же операцией, как при переводе баллов
+	PreservesExpiry bool
+	LotsSourceID    int64
+}
+
+// BalanceMismatch расхождение между сохраненным балансом пользователя и балансом, рассчитанным по журналу операций
+type BalanceMismatch struct {
+	UserID int
+	Cached BalanceData
+	Ledger BalanceData
+}
+
+// ReconciliationReport результат сверки балансов с журналом операций
+type ReconciliationReport struct {
+	Mismatches             []*BalanceMismatch
+	UnbalancedTransactions []int64
+}
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// minorUnitsInUnit количество минимальных единиц (сотых долей) в одном балле
+const minorUnitsInUnit = 100
+
+// Money сумма баллов, хранящаяся в целых сотых долях балла
+//
+// Правило округления: значения с точностью выше сотых (например, начисления от системы расчета баллов)
+// округляются до сотых по правилу "половина от нуля" - 0.005 превращается в 0.01, а -0.005 в -0.01
+type Money int64
+
+// NewMoney создает сумму из целой части и сотых долей балла
+func NewMoney(units int64, minorUnits int64) Money {
+	return Money(units*minorUnitsInUnit + minorUnits)
+}
+
+// ParseMoney разбирает десятичную запись суммы (например, "729.98" или "1e2")
+// и округляет ее до сотых по правилу "половина от нуля"
+func ParseMoney(s string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid money value: %q", s)
+	}
+
+	m, ok := roundRat(r)
+	if !ok {
+		return 0, fmt.Errorf("money value is out of range: %q", s)
+	}
+	return m, nil
+}
+
+// roundRat округляет число до сотых по правилу "половина от нуля"
+// возвращает false, если результат не помещается в Money
+func roundRat(r *big.Rat) (Money, bool) {
+	num := new(big.Int).Mul(r.Num(), big.NewInt(minorUnitsInUnit))
+	den := r.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	// остаток, не меньший половины знаменателя, округляем от нуля
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den) >= 0 {
+		quo.Add(quo, big.NewInt(int64(num.Sign())))
+	}
+	if !quo.IsInt64() {
+		return 0, false
+	}
+
+	return Money(quo.Int64()), true
+}
+
+// Multiply умножает сумму на коэффициент и округляет результат до сотых по правилу "половина от нуля"
+// коэффициент берется в десятичной записи, поэтому 1.1 умножает ровно на 1.1, а не на ближайшее двоичное число
+func (m Money) Multiply(factor float64) Money {
+	f, ok := new(big.Rat).SetString(strconv.FormatFloat(factor, 'f', -1, 64))
+	if !ok {
+		return m
+	}
+	r := new(big.Rat).SetFrac(big.NewInt(int64(m)), big.NewInt(minorUnitsInUnit))
+	res, ok := roundRat(r.Mul(r, f))
+	if !ok {
+		return m
+	}
+	return res
+}
+
+// MinorUnits возвращает сумму в сотых долях балла
+func (m Money) MinorUnits() int64 {
+	return int64(m)
+}
+
+// String возвращает десятичную запись суммы без незначащих нулей: "100", "12.5", "729.98"
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	units := v / minorUnitsInUnit
+	minor := v % minorUnitsInUnit
+	if minor == 0 {
+		return sign + strconv.FormatInt(units, 10)
+	}
+
+	return sign + strconv.FormatInt(units, 10) + "." + strings.TrimRight(fmt.Sprintf("%02d", minor), "0")
+}
+
+// MarshalJSON кодирует сумму числом, как раньше кодировались значения float32
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON принимает только числа JSON: строки вроде "751" отклоняются, как раньше для значений float32
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		return fmt.Errorf("money value must be a JSON number: %s", s)
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan читает сумму из колонки типа numeric
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case int64:
+		*m = Money(v * minorUnitsInUnit)
+		return nil
+	case float64:
+		parsed, err := ParseMoney(strconv.FormatFloat(v, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case []byte:
+		return m.Scan(string(v))
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	default:
+		return fmt.Errorf("can not scan %T into Money", src)
+	}
+}
+
+// Value передает сумму в базу данных в виде десятичной строки
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// UnmarshalText позволяет задавать суммы в настройках, например "1000" или "0.5"
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Money
+		wantErr bool
+	}{
+		{name: "integer", value: "100", want: NewMoney(100, 0)},
+		{name: "two decimals", value: "729.98", want: NewMoney(729, 98)},
+		{name: "one decimal", value: "12.5", want: NewMoney(12, 50)},
+		{name: "exponent", value: "1e2", want: NewMoney(100, 0)},
+		{name: "round half up", value: "0.005", want: NewMoney(0, 1)},
+		{name: "round down", value: "0.0049", want: NewMoney(0, 0)},
+		{name: "negative round half away from zero", value: "-0.005", want: NewMoney(0, -1)},
+		{name: "float32 artifact", value: "729.97998046875", want: NewMoney(729, 98)},
+		{name: "invalid value", value: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMoney(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMoney_Multiply(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  Money
+		factor float64
+		want   Money
+	}{
+		{name: "no multiplier", value: NewMoney(729, 98), factor: 1, want: NewMoney(729, 98)},
+		{name: "decimal factor", value: NewMoney(100, 0), factor: 1.1, want: NewMoney(110, 0)},
+		{name: "round half away from zero", value: NewMoney(0, 5), factor: 1.5, want: NewMoney(0, 8)},
+		{name: "round down", value: NewMoney(0, 3), factor: 1.1, want: NewMoney(0, 3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.value.Multiply(tt.factor))
+		})
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		json  string
+	}{
+		{name: "integer", money: NewMoney(500, 0), json: "500"},
+		{name: "fraction", money: NewMoney(12, 50), json: "12.5"},
+		{name: "cents", money: NewMoney(0, 7), json: "0.07"},
+		{name: "negative", money: NewMoney(-3, -25), json: "-3.25"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.money)
+			require.NoError(t, err)
+			assert.Equal(t, tt.json, string(data))
+
+			var decoded Money
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tt.money, decoded)
+		})
+	}
+}
+
+func TestMoney_UnmarshalJSON_RejectsStrings(t *testing.T) {
+	var m Money
+	assert.Error(t, json.Unmarshal([]byte(`"751"`), &m))
+	assert.Error(t, json.Unmarshal([]byte(`true`), &m))
+	assert.Equal(t, Money(0), m)
+}
+
+func TestMoney_Scan(t *testing.T) {
+	var m Money
+	require.NoError(t, m.Scan("751.10"))
+	assert.Equal(t, NewMoney(751, 10), m)
+	require.NoError(t, m.Scan([]byte("0.5")))
+	assert.Equal(t, NewMoney(0, 50), m)
+	require.NoError(t, m.Scan(int64(3)))
+	assert.Equal(t, NewMoney(3, 0), m)
+
+	value, err := NewMoney(751, 10).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "751.1", value)
+}
@@ -7,6 +7,8 @@ const (
 	OrderProcessingStatus = "PROCESSING"
 	OrderInvalidStatus    = "INVALID"
 	OrderNewStatus        = "NEW"
+	// AccrualRegisteredStatus статус системы расчета баллов: заказ зарегистрирован, но расчет еще не начат
+	AccrualRegisteredStatus = "REGISTERED"
 )
 
 type OrderDTO struct {
@@ -14,11 +16,11 @@ type OrderDTO struct {
 	UploadedAt time.Time `db:"uploaded_at" json:"uploaded_at"`
 	UserID     int       `db:"user_id" json:"-"`
 	Status     string    `db:"status" json:"status"`
-	Accrual    float32   `db:"accrual" json:"accrual,omitempty"`
+	Accrual    Money     `db:"accrual" json:"accrual,omitempty"`
 }
 
 type AccrualCalculationRes struct {
-	Order   string  `json:"order"`
-	Status  string  `json:"status"`
-	Accrual float32 `json:"accrual"`
+	Order   string `json:"order"`
+	Status  string `json:"status"`
+	Accrual Money  `json:"accrual"`
 }
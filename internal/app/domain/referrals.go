@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Статусы приглашений
+// CAP_REACHED означает, что пригласивший пользователь уже получил максимальное количество бонусов
+const (
+	ReferralPendingStatus    = "PENDING"
+	ReferralRewardedStatus   = "REWARDED"
+	ReferralCapReachedStatus = "CAP_REACHED"
+)
+
+// Referral приглашение пользователя по реферальному коду
+type Referral struct {
+	ID         int        `json:"-" db:"id"`
+	ReferrerID int        `json:"-" db:"referrer_id"`
+	ReferredID int        `json:"-" db:"referred_id"`
+	Login      string     `json:"login" db:"login"`
+	Status     string     `json:"status" db:"status"`
+	Bonus      Money      `json:"bonus,omitempty" db:"bonus"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	RewardedAt *time.Time `json:"rewarded_at,omitempty" db:"rewarded_at"`
+}
+
+// ReferralSummary реферальный код пользователя и приглашенные им пользователи
+type ReferralSummary struct {
+	ReferralCode string      `json:"referral_code"`
+	Referrals    []*Referral `json:"referrals"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// StatementLine строка выписки по счету пользователя
+// Balance - доступный баланс пользователя после операции
+type StatementLine struct {
+	Operation string    `json:"operation" db:"operation"`
+	Reference string    `json:"reference" db:"reference"`
+	Amount    Money     `json:"amount" db:"amount"`
+	Balance   Money     `json:"balance" db:"balance"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// OrderNumber номер заказа для начислений, списаний, резервов и возвратов
+	OrderNumber string `json:"order,omitempty" db:"order_number"`
+	// OrderStatus статус заказа, если заказ загружен пользователем
+	OrderStatus string `json:"order_status,omitempty" db:"order_status"`
+	// WithdrawalReversed сумма, возвращенная администратором по списанию, для операций списания
+	// и для резервирования, которое было подтверждено и стало списанием
+	WithdrawalReversed Money `json:"withdrawal_reversed,omitempty" db:"withdrawal_reversed"`
+}
+
+// StatementFilter период и страница выписки, нулевые From и To не ограничивают период
+type StatementFilter struct {
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Уровни программы лояльности
+const (
+	TierBasic    = "BASIC"
+	TierSilver   = "SILVER"
+	TierGold     = "GOLD"
+	TierPlatinum = "PLATINUM"
+)
+
+// Tier уровень программы лояльности, который присваивается пользователю,
+// заработавшему за период не меньше Threshold баллов
+// начисления пользователей этого уровня умножаются на Multiplier
+type Tier struct {
+	Name       string
+	Threshold  Money
+	Multiplier float64
+}
+
+// Tiers уровни программы лояльности, упорядоченные по возрастанию порога
+type Tiers []Tier
+
+// Validate проверяет, что пороги уровней строго возрастают, иначе TierFor и Next выбирали бы уровни не по порядку,
+// и что множители не меньше единицы, иначе уровень уменьшал бы начисления
+func (t Tiers) Validate() error {
+	for _, tier := range t {
+		if tier.Multiplier < 1 {
+			return fmt.Errorf("multiplier of tier %s (%v) must not be less than 1", tier.Name, tier.Multiplier)
+		}
+	}
+	for i := 1; i < len(t); i++ {
+		if t[i].Threshold <= t[i-1].Threshold {
+			return fmt.Errorf(
+				"threshold of tier %s (%v) must be greater than threshold of tier %s (%v)",
+				t[i].Name, t[i].Threshold, t[i-1].Name, t[i-1].Threshold,
+			)
+		}
+	}
+	return nil
+}
+
+// TierFor возвращает наивысший уровень, порог которого не превышает заработанных баллов
+func (t Tiers) TierFor(earned Money) Tier {
+	tier := Tier{Name: TierBasic, Multiplier: 1}
+	for _, candidate := range t {
+		if earned >= candidate.Threshold {
+			tier = candidate
+		}
+	}
+	return tier
+}
+
+// Get возвращает уровень по названию, для неизвестного названия - базовый уровень
+func (t Tiers) Get(name string) Tier {
+	for _, tier := range t {
+		if tier.Name == name {
+			return tier
+		}
+	}
+	return Tier{Name: TierBasic, Multiplier: 1}
+}
+
+// Next возвращает уровень, следующий за уровнем name
+func (t Tiers) Next(name string) (Tier, bool) {
+	current := t.Get(name)
+	for _, tier := range t {
+		if tier.Threshold > current.Threshold {
+			return tier, true
+		}
+	}
+	return Tier{}, false
+}
+
+// UserTierRecord уровень пользователя, рассчитанный при последнем пересчете уровней
+type UserTierRecord struct {
+	UserID       int       `db:"user_id"`
+	Tier         string    `db:"tier"`
+	Earned       Money     `db:"earned"`
+	CalculatedAt time.Time `db:"calculated_at"`
+}
+
+// UserTier текущий уровень пользователя и прогресс до следующего уровня
+type UserTier struct {
+	Tier             string     `json:"tier"`
+	Multiplier       float64    `json:"multiplier"`
+	Earned           Money      `json:"earned"`
+	NextTier         string     `json:"next_tier,omitempty"`
+	PointsToNextTier Money      `json:"points_to_next_tier,omitempty"`
+	CalculatedAt     *time.Time `json:"calculated_at,omitempty"`
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTiers_TierFor(t *testing.T) {
+	tiers := Tiers{
+		{Name: TierSilver, Threshold: NewMoney(1000, 0), Multiplier: 1.1},
+		{Name: TierGold, Threshold: NewMoney(5000, 0), Multiplier: 1.25},
+		{Name: TierPlatinum, Threshold: NewMoney(20000, 0), Multiplier: 1.5},
+	}
+	tests := []struct {
+		name     string
+		earned   Money
+		wantTier string
+		wantNext string
+	}{
+		{name: "basic", earned: NewMoney(999, 99), wantTier: TierBasic, wantNext: TierSilver},
+		{name: "exact threshold", earned: NewMoney(1000, 0), wantTier: TierSilver, wantNext: TierGold},
+		{name: "gold", earned: NewMoney(19999, 0), wantTier: TierGold, wantNext: TierPlatinum},
+		{name: "highest tier", earned: NewMoney(50000, 0), wantTier: TierPlatinum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tier := tiers.TierFor(tt.earned)
+			assert.Equal(t, tt.wantTier, tier.Name)
+			next, ok := tiers.Next(tier.Name)
+			assert.Equal(t, tt.wantNext != "", ok)
+			assert.Equal(t, tt.wantNext, next.Name)
+		})
+	}
+}
+
+func TestTiers_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tiers   Tiers
+		wantErr bool
+	}{
+		{
+			name: "ascending thresholds",
+			tiers: Tiers{
+				{Name: TierSilver, Threshold: NewMoney(1000, 0), Multiplier: 1.1},
+				{Name: TierGold, Threshold: NewMoney(5000, 0), Multiplier: 1.25},
+			},
+		},
+		{
+			name: "descending thresholds",
+			tiers: Tiers{
+				{Name: TierSilver, Threshold: NewMoney(5000, 0), Multiplier: 1.25},
+				{Name: TierGold, Threshold: NewMoney(1000, 0), Multiplier: 1.1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "equal thresholds",
+			tiers: Tiers{
+				{Name: TierSilver, Threshold: NewMoney(1000, 0), Multiplier: 1.1},
+				{Name: TierGold, Threshold: NewMoney(1000, 0), Multiplier: 1.1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "multiplier less than one",
+			tiers: Tiers{
+				{Name: TierSilver, Threshold: NewMoney(1000, 0), Multiplier: 0.9},
+				{Name: TierGold, Threshold: NewMoney(5000, 0), Multiplier: 1.25},
+			},
+			wantErr: true,
+		},
+		{
+			name: "multiplier of one",
+			tiers: Tiers{
+				{Name: TierSilver, Threshold: NewMoney(1000, 0), Multiplier: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tiers.Validate()
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
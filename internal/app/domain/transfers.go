@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+const (
+	TransferOutgoingDirection = "OUTGOING"
+	TransferIncomingDirection = "INCOMING"
+)
+
+// Transfer перевод баллов от одного пользователя другому
+// Direction задается относительно пользователя, который запрашивает историю переводов
+type Transfer struct {
+	ID          int       `json:"id" db:"id"`
+	SenderID    int       `json:"-" db:"sender_id"`
+	RecipientID int       `json:"-" db:"recipient_id"`
+	Sender      string    `json:"sender" db:"sender"`
+	Recipient   string    `json:"recipient" db:"recipient"`
+	Sum         Money     `json:"sum" db:"sum"`
+	Note        string    `json:"note,omitempty" db:"note"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	Direction   string    `json:"direction" db:"-"`
+}
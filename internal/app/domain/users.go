@@ -1,9 +1,13 @@
 package domain
 
 type UserDTO struct {
-	ID       int    `db:"id"`
-	Login    string `db:"login"`
-	Password string `db:"password"`
+	ID           int    `db:"id"`
+	Login        string `db:"login"`
+	Password     string `db:"password"`
+	IsAdmin      bool   `db:"is_admin"`
+	ReferralCode string `db:"referral_code"`
+	// ReferrerCode реферальный код пригласившего пользователя, указанный при регистрации
+	ReferrerCode string `db:"-"`
 }
 
 type TokenData struct {
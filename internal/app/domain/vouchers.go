@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// VoucherBatch партия ваучеров с одинаковыми номиналом, сроком действия и ограничением использований
+// каждый ваучер партии может быть погашен не больше UsageLimit раз, причем каждым пользователем - только один раз
+type VoucherBatch struct {
+	ID         int        `json:"id" db:"id"`
+	Value      Money      `json:"value" db:"value"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	UsageLimit int        `json:"usage_limit" db:"usage_limit"`
+	CreatedBy  int        `json:"-" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	Vouchers   []*Voucher `json:"vouchers" db:"-"`
+}
+
+// Voucher ваучер с кодом, который пользователь обменивает на баллы
+type Voucher struct {
+	ID        int    `json:"-" db:"id"`
+	BatchID   int    `json:"-" db:"batch_id"`
+	Code      string `json:"code" db:"code"`
+	UsedCount int    `json:"used_count" db:"used_count"`
+}
+
+// VoucherRedemption погашение ваучера пользователем
+type VoucherRedemption struct {
+	ID         int       `json:"-" db:"id"`
+	VoucherID  int       `json:"-" db:"voucher_id"`
+	UserID     int       `json:"-" db:"user_id"`
+	Sum        Money     `json:"sum" db:"sum"`
+	RedeemedAt time.Time `json:"redeemed_at" db:"redeemed_at"`
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"net/http"
+	"strconv"
+)
+
+const defaultDeadLetteredJobsLimit = 100
+
+type AccrualJobService interface {
+	GetDeadLetteredJobs(ctx context.Context, limit int, offset int) ([]*domain.AccrualJob, error)
+	RequeueJob(ctx context.Context, jobID int64, adminID int) (*domain.AccrualJob, error)
+}
+
+// AdminAccrualJobHandler позволяет администраторам разбирать заказы, обработка которых отложена после ошибок
+type AdminAccrualJobHandler struct {
+	authService       AuthService
+	accrualJobService AccrualJobService
+}
+
+func NewAdminAccrualJobHandler(authService AuthService, accrualJobService AccrualJobService) *AdminAccrualJobHandler {
+	return &AdminAccrualJobHandler{authService: authService, accrualJobService: accrualJobService}
+}
+
+// HandleListDeadLetteredJobs возвращает отложенные задания вместе с последней ошибкой, начиная с последних
+func (h *AdminAccrualJobHandler) HandleListDeadLetteredJobs(c *gin.Context) {
+	input := DeadLetteredJobsInput{Limit: defaultDeadLetteredJobsLimit}
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	jobs, err := h.accrualJobService.GetDeadLetteredJobs(c.Request.Context(), input.Limit, input.Offset)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if len(jobs) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// HandleRequeueJob возвращает отложенное задание в очередь, счетчики попыток сбрасываются
+func (h *AdminAccrualJobHandler) HandleRequeueJob(c *gin.Context) {
+	admin, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": "Job id is not valid"})
+		return
+	}
+
+	job, err := h.accrualJobService.RequeueJob(c.Request.Context(), jobID, admin.ID)
+	switch {
+	case errors.Is(err, repositories.ErrAccrualJobDoesNotExist):
+		c.JSON(http.StatusNotFound, gin.H{"errors": "Job does not exist"})
+		return
+	case errors.Is(err, repositories.ErrAccrualJobIsNotDeadLettered):
+		c.JSON(http.StatusConflict, gin.H{"errors": "Job is not dead-lettered"})
+		return
+	case err != nil:
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"gophermart/internal/app/repositories"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAccrualJobHandler_HandleRequeueJob(t *testing.T) {
+	type WantErrorResponseBody struct {
+		Errors string `json:"errors"`
+	}
+
+	admin := &domain.UserDTO{ID: 1, IsAdmin: true}
+	tests := []struct {
+		name              string
+		jobID             string
+		shouldCallService bool
+		requeueErr        error
+		wantStatusCode    int
+		wantErrRespBody   *WantErrorResponseBody
+	}{
+		{
+			name:              "positive test",
+			jobID:             "10",
+			shouldCallService: true,
+			wantStatusCode:    http.StatusOK,
+		},
+		{
+			name:              "job does not exist",
+			jobID:             "10",
+			shouldCallService: true,
+			requeueErr:        repositories.ErrAccrualJobDoesNotExist,
+			wantStatusCode:    http.StatusNotFound,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Job does not exist"},
+		},
+		{
+			name:              "job is not dead-lettered",
+			jobID:             "10",
+			shouldCallService: true,
+			requeueErr:        repositories.ErrAccrualJobIsNotDeadLettered,
+			wantStatusCode:    http.StatusConflict,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Job is not dead-lettered"},
+		},
+		{
+			name:            "invalid job id",
+			jobID:           "abc",
+			wantStatusCode:  http.StatusBadRequest,
+			wantErrRespBody: &WantErrorResponseBody{Errors: "Job id is not valid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/accrual/dead-letters/"+tt.jobID+"/requeue", nil)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(admin, true)
+			accrualJobServiceMock := mock_handlers.NewMockAccrualJobService(ctrl)
+			if tt.shouldCallService {
+				var job *domain.AccrualJob
+				if tt.requeueErr == nil {
+					job = &domain.AccrualJob{ID: 10, OrderNumber: "123", Stage: domain.AccrualJobRegisterStage}
+				}
+				accrualJobServiceMock.EXPECT().RequeueJob(gomock.Any(), int64(10), admin.ID).Return(job, tt.requeueErr)
+			}
+
+			r := gin.Default()
+			accrualJobHandler := NewAdminAccrualJobHandler(authServiceMock, accrualJobServiceMock)
+			r.POST("/accrual/dead-letters/:id/requeue", accrualJobHandler.HandleRequeueJob)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantErrRespBody != nil {
+				expectedResponse, err := json.Marshal(tt.wantErrRespBody)
+				require.NoError(t, err)
+				assert.Equal(t, string(expectedResponse), w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminAccrualJobHandler_HandleListDeadLetteredJobs(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/accrual/dead-letters?limit=10", nil)
+	w := httptest.NewRecorder()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	accrualJobServiceMock := mock_handlers.NewMockAccrualJobService(ctrl)
+	accrualJobServiceMock.EXPECT().GetDeadLetteredJobs(gomock.Any(), 10, 0).Return([]*domain.AccrualJob{
+		{ID: 10, OrderNumber: "123", Stage: domain.AccrualJobRegisterStage, Attempts: 10, LastError: "bad gateway", LockedBy: "token"},
+	}, nil)
+
+	r := gin.Default()
+	accrualJobHandler := NewAdminAccrualJobHandler(mock_handlers.NewMockAuthService(ctrl), accrualJobServiceMock)
+	r.GET("/accrual/dead-letters", accrualJobHandler.HandleListDeadLetteredJobs)
+	r.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"last_error":"bad gateway"`)
+	// токен захвата не показывается
+	assert.NotContains(t, w.Body.String(), "token")
+}
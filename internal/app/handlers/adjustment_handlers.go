@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"gophermart/internal/app/services"
+	"net/http"
+	"strconv"
+)
+
+const defaultAuditLogLimit = 100
+
+type AdjustmentService interface {
+	CreateAdjustment(ctx context.Context, adjustment *domain.BalanceAdjustment) error
+	ApproveAdjustment(ctx context.Context, adjustmentID int, adminID int) (*domain.BalanceAdjustment, error)
+	RejectAdjustment(ctx context.Context, adjustmentID int, adminID int) (*domain.BalanceAdjustment, error)
+	GetUserAdjustments(ctx context.Context, userID int) ([]*domain.BalanceAdjustment, error)
+	GetAuditLog(ctx context.Context, limit int, offset int) ([]*domain.AuditLogEntry, error)
+}
+
+// AdminAdjustmentHandler обрабатывает запросы администраторов на ручные корректировки баланса пользователей
+type AdminAdjustmentHandler struct {
+	authService       AuthService
+	adjustmentService AdjustmentService
+}
+
+func NewAdminAdjustmentHandler(authService AuthService, adjustmentService AdjustmentService) *AdminAdjustmentHandler {
+	return &AdminAdjustmentHandler{authService: authService, adjustmentService: adjustmentService}
+}
+
+// HandleCreateAdjustment начисляет или списывает баллы пользователя
+// если корректировка требует подтверждения вторым администратором, возвращается 202
+func (h *AdminAdjustmentHandler) HandleCreateAdjustment(c *gin.Context) {
+	admin, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": "User id is not valid"})
+		return
+	}
+
+	var input AdjustmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	adjustment := &domain.BalanceAdjustment{
+		UserID:      userID,
+		Amount:      input.Amount,
+		Reason:      input.Reason,
+		RequestedBy: admin.ID,
+	}
+	err = h.adjustmentService.CreateAdjustment(c.Request.Context(), adjustment)
+	if abortWithAdjustmentError(c, err) {
+		return
+	}
+
+	if adjustment.Status == domain.AdjustmentPendingStatus {
+		c.JSON(http.StatusAccepted, adjustment)
+		return
+	}
+	c.JSON(http.StatusCreated, adjustment)
+}
+
+func (h *AdminAdjustmentHandler) HandleListAdjustments(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": "User id is not valid"})
+		return
+	}
+
+	adjustments, err := h.adjustmentService.GetUserAdjustments(c.Request.Context(), userID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if len(adjustments) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustments)
+}
+
+// HandleApproveAdjustment подтверждает корректировку, созданную другим администратором, и применяет ее
+func (h *AdminAdjustmentHandler) HandleApproveAdjustment(c *gin.Context) {
+	h.handleReviewAdjustment(c, h.adjustmentService.ApproveAdjustment)
+}
+
+func (h *AdminAdjustmentHandler) HandleRejectAdjustment(c *gin.Context) {
+	h.handleReviewAdjustment(c, h.adjustmentService.RejectAdjustment)
+}
+
+func (h *AdminAdjustmentHandler) handleReviewAdjustment(
+	c *gin.Context, review func(ctx context.Context, adjustmentID int, adminID int) (*domain.BalanceAdjustment, error),
+) {
+	admin, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	adjustmentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": "Adjustment id is not valid"})
+		return
+	}
+
+	adjustment, err := review(c.Request.Context(), adjustmentID, admin.ID)
+	if abortWithAdjustmentError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustment)
+}
+
+// HandleGetAuditLog возвращает журнал действий администраторов, начиная с последних
+func (h *AdminAdjustmentHandler) HandleGetAuditLog(c *gin.Context) {
+	input := AuditLogInput{Limit: defaultAuditLogLimit}
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	entries, err := h.adjustmentService.GetAuditLog(c.Request.Context(), input.Limit, input.Offset)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if len(entries) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// abortWithAdjustmentError отвечает на запрос, завершившийся ошибкой, и возвращает true, если ошибка была
+func abortWithAdjustmentError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, repositories.ErrUserDoesNotExist):
+		c.JSON(http.StatusNotFound, gin.H{"errors": "User does not exist"})
+	case errors.Is(err, repositories.ErrAdjustmentDoesNotExist):
+		c.JSON(http.StatusNotFound, gin.H{"errors": "Adjustment does not exist"})
+	case errors.Is(err, repositories.ErrAdjustmentIsNotPending):
+		c.JSON(http.StatusConflict, gin.H{"errors": "Adjustment was already reviewed"})
+	case errors.Is(err, repositories.ErrAdjustmentSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{"errors": "Adjustment must be approved by another admin"})
+	case errors.Is(err, repositories.ErrCanNotWithdrawBalance):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": "Not enough points on user balance"})
+	case errors.Is(err, services.ErrInvalidAdjustment):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+	default:
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"gophermart/internal/app/repositories"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAdjustmentHandler_HandleCreateAdjustment(t *testing.T) {
+	type WantErrorResponseBody struct {
+		Errors string `json:"errors"`
+	}
+
+	admin := &domain.UserDTO{ID: 1, IsAdmin: true}
+	inputData := &AdjustmentInput{Amount: -domain.NewMoney(50, 0), Reason: "duplicate accrual"}
+	tests := []struct {
+		name              string
+		userID            string
+		reqInput          *AdjustmentInput
+		shouldCallService bool
+		createdStatus     string
+		createErr         error
+		wantStatusCode    int
+		wantErrRespBody   *WantErrorResponseBody
+	}{
+		{
+			name:              "adjustment is applied",
+			userID:            "10",
+			reqInput:          inputData,
+			shouldCallService: true,
+			createdStatus:     domain.AdjustmentAppliedStatus,
+			wantStatusCode:    http.StatusCreated,
+		},
+		{
+			name:              "adjustment needs approval",
+			userID:            "10",
+			reqInput:          inputData,
+			shouldCallService: true,
+			createdStatus:     domain.AdjustmentPendingStatus,
+			wantStatusCode:    http.StatusAccepted,
+		},
+		{
+			name:              "user does not exist",
+			userID:            "10",
+			reqInput:          inputData,
+			shouldCallService: true,
+			createErr:         repositories.ErrUserDoesNotExist,
+			wantStatusCode:    http.StatusNotFound,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "User does not exist"},
+		},
+		{
+			name:              "debit exceeds balance",
+			userID:            "10",
+			reqInput:          inputData,
+			shouldCallService: true,
+			createErr:         repositories.ErrCanNotWithdrawBalance,
+			wantStatusCode:    http.StatusUnprocessableEntity,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Not enough points on user balance"},
+		},
+		{
+			name:            "invalid user id",
+			userID:          "abc",
+			reqInput:        inputData,
+			wantStatusCode:  http.StatusBadRequest,
+			wantErrRespBody: &WantErrorResponseBody{Errors: "User id is not valid"},
+		},
+		{
+			name:           "no reason",
+			userID:         "10",
+			reqInput:       &AdjustmentInput{Amount: domain.NewMoney(50, 0)},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody, err := json.Marshal(tt.reqInput)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/users/"+tt.userID+"/adjustments", bytes.NewReader(reqBody))
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(admin, true)
+			adjustmentServiceMock := mock_handlers.NewMockAdjustmentService(ctrl)
+			if tt.shouldCallService {
+				adjustmentServiceMock.EXPECT().CreateAdjustment(gomock.Any(), &domain.BalanceAdjustment{
+					UserID:      10,
+					Amount:      tt.reqInput.Amount,
+					Reason:      tt.reqInput.Reason,
+					RequestedBy: admin.ID,
+				}).DoAndReturn(func(ctx context.Context, adjustment *domain.BalanceAdjustment) error {
+					adjustment.Status = tt.createdStatus
+					return tt.createErr
+				})
+			}
+
+			r := gin.Default()
+			adminAdjustmentHandler := NewAdminAdjustmentHandler(authServiceMock, adjustmentServiceMock)
+			r.POST("/users/:id/adjustments", adminAdjustmentHandler.HandleCreateAdjustment)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantErrRespBody != nil {
+				expectedResponse, err := json.Marshal(tt.wantErrRespBody)
+				require.NoError(t, err)
+				assert.Equal(t, string(expectedResponse), w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminAdjustmentHandler_HandleApproveAdjustment(t *testing.T) {
+	admin := &domain.UserDTO{ID: 1, IsAdmin: true}
+	tests := []struct {
+		name           string
+		approveErr     error
+		wantStatusCode int
+	}{
+		{
+			name:           "positive test",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "approval by the same admin",
+			approveErr:     repositories.ErrAdjustmentSelfApproval,
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "adjustment was already reviewed",
+			approveErr:     repositories.ErrAdjustmentIsNotPending,
+			wantStatusCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/adjustments/5/approve", nil)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(admin, true)
+			adjustmentServiceMock := mock_handlers.NewMockAdjustmentService(ctrl)
+			var adjustment *domain.BalanceAdjustment
+			if tt.approveErr == nil {
+				adjustment = &domain.BalanceAdjustment{ID: 5, Status: domain.AdjustmentAppliedStatus}
+			}
+			adjustmentServiceMock.EXPECT().ApproveAdjustment(gomock.Any(), 5, admin.ID).Return(adjustment, tt.approveErr)
+
+			r := gin.Default()
+			adminAdjustmentHandler := NewAdminAdjustmentHandler(authServiceMock, adjustmentServiceMock)
+			r.POST("/adjustments/:id/approve", adminAdjustmentHandler.HandleApproveAdjustment)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"net/http"
+	"strconv"
+)
+
+type WithdrawalReversalService interface {
+	ReverseWithdrawal(ctx context.Context, reversal *domain.WithdrawalReversal) error
+}
+
+type AdminBalanceHandler struct {
+	authService     AuthService
+	reversalService WithdrawalReversalService
+}
+
+func NewAdminBalanceHandler(authService AuthService, reversalService WithdrawalReversalService) *AdminBalanceHandler {
+	return &AdminBalanceHandler{authService: authService, reversalService: reversalService}
+}
+
+// HandleReverseWithdrawal обрабатывает POST запрос администратора на возврат баллов, списанных в счет заказа
+// если сумма не передана, то возвращается весь остаток списания
+func (h *AdminBalanceHandler) HandleReverseWithdrawal(c *gin.Context) {
+	admin, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	withdrawalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": "Withdrawal id is not valid"})
+		return
+	}
+
+	var input WithdrawalReversalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	reversal := &domain.WithdrawalReversal{
+		WithdrawalID: withdrawalID,
+		Sum:          input.Sum,
+		Reason:       input.Reason,
+		AdminID:      admin.ID,
+	}
+	err = h.reversalService.ReverseWithdrawal(c.Request.Context(), reversal)
+	if errors.Is(err, repositories.ErrWithdrawalDoesNotExist) {
+		c.JSON(http.StatusNotFound, gin.H{"errors": "Withdrawal does not exist"})
+		return
+	}
+	if errors.Is(err, repositories.ErrReversalExceedsWithdrawal) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": "Reversal sum exceeds the withdrawn sum"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reversal)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"gophermart/internal/app/repositories"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminBalanceHandler_HandleReverseWithdrawal(t *testing.T) {
+	type WantErrorResponseBody struct {
+		Errors string `json:"errors"`
+	}
+
+	admin := &domain.UserDTO{ID: 1, IsAdmin: true}
+	inputData := &WithdrawalReversalInput{Sum: domain.NewMoney(50, 0), Reason: "order cancelled"}
+	tests := []struct {
+		name                 string
+		withdrawalID         string
+		reqInput             *WithdrawalReversalInput
+		shouldCallService    bool
+		reverseWithdrawalErr error
+		wantStatusCode       int
+		wantErrRespBody      *WantErrorResponseBody
+	}{
+		{
+			name:              "positive test",
+			withdrawalID:      "10",
+			reqInput:          inputData,
+			shouldCallService: true,
+			wantStatusCode:    http.StatusCreated,
+		},
+		{
+			name:                 "withdrawal does not exist",
+			withdrawalID:         "10",
+			reqInput:             inputData,
+			shouldCallService:    true,
+			reverseWithdrawalErr: repositories.ErrWithdrawalDoesNotExist,
+			wantStatusCode:       http.StatusNotFound,
+			wantErrRespBody:      &WantErrorResponseBody{Errors: "Withdrawal does not exist"},
+		},
+		{
+			name:                 "reversal exceeds withdrawal",
+			withdrawalID:         "10",
+			reqInput:             inputData,
+			shouldCallService:    true,
+			reverseWithdrawalErr: repositories.ErrReversalExceedsWithdrawal,
+			wantStatusCode:       http.StatusUnprocessableEntity,
+			wantErrRespBody:      &WantErrorResponseBody{Errors: "Reversal sum exceeds the withdrawn sum"},
+		},
+		{
+			name:            "invalid withdrawal id",
+			withdrawalID:    "abc",
+			reqInput:        inputData,
+			wantStatusCode:  http.StatusBadRequest,
+			wantErrRespBody: &WantErrorResponseBody{Errors: "Withdrawal id is not valid"},
+		},
+		{
+			name:           "no reason",
+			withdrawalID:   "10",
+			reqInput:       &WithdrawalReversalInput{Sum: domain.NewMoney(50, 0)},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody, err := json.Marshal(tt.reqInput)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/withdrawals/"+tt.withdrawalID+"/reversals", bytes.NewReader(reqBody))
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(admin, true)
+			reversalServiceMock := mock_handlers.NewMockWithdrawalReversalService(ctrl)
+			if tt.shouldCallService {
+				reversalServiceMock.EXPECT().ReverseWithdrawal(gomock.Any(), &domain.WithdrawalReversal{
+					WithdrawalID: 10,
+					Sum:          tt.reqInput.Sum,
+					Reason:       tt.reqInput.Reason,
+					AdminID:      admin.ID,
+				}).Return(tt.reverseWithdrawalErr)
+			}
+
+			r := gin.Default()
+			adminBalanceHandler := NewAdminBalanceHandler(authServiceMock, reversalServiceMock)
+			r.POST("/withdrawals/:id/reversals", adminBalanceHandler.HandleReverseWithdrawal)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantErrRespBody != nil {
+				expectedResponse, err := json.Marshal(tt.wantErrRespBody)
+				require.NoError(t, err)
+				assert.Equal(t, string(expectedResponse), w.Body.String())
+			}
+		})
+	}
+}
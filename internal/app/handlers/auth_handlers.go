@@ -20,6 +20,10 @@ type AuthService interface {
 	GetUserFromContext(ctx context.Context) (*domain.UserDTO, bool)
 }
 
+type PasswordService interface {
+	ChangePassword(ctx context.Context, user *domain.UserDTO, oldPassword string, newPassword string) error
+}
+
 type RegistrationHandler struct {
 	registrationService RegistrationService
 }
@@ -37,14 +41,19 @@ func (h *RegistrationHandler) HandleRegistration(c *gin.Context) {
 	}
 
 	userDTO := domain.UserDTO{
-		Login:    input.Login,
-		Password: input.Password,
+		Login:        input.Login,
+		Password:     input.Password,
+		ReferrerCode: input.ReferralCode,
 	}
 	tokenData, err := h.registrationService.RegisterUser(c.Request.Context(), userDTO)
 	if errors.Is(err, services.ErrUserAlreadyExists) {
 		c.JSON(http.StatusConflict, gin.H{"errors": "User with given login already exists"})
 		return
 	}
+	if errors.Is(err, services.ErrReferralCodeDoesNotExist) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": "Referral code is not valid"})
+		return
+	}
 	if err != nil {
 		log.Error().Msg(fmt.Sprintf("can not register user: %v", err.Error()))
 		c.Status(http.StatusInternalServerError)
@@ -83,3 +92,40 @@ func (h *LoginHandler) HandleLogin(c *gin.Context) {
 	c.Header("Authorization", "Bearer "+tokenData.Token)
 	c.Status(http.StatusOK)
 }
+
+type PasswordHandler struct {
+	authService     AuthService
+	passwordService PasswordService
+}
+
+func NewPasswordHandler(authService AuthService, passwordService PasswordService) *PasswordHandler {
+	return &PasswordHandler{authService: authService, passwordService: passwordService}
+}
+
+// HandleChangePassword обрабатывает POST запрос на смену пароля пользователя
+func (h *PasswordHandler) HandleChangePassword(c *gin.Context) {
+	user, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var input changePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	err := h.passwordService.ChangePassword(c.Request.Context(), user, input.OldPassword, input.NewPassword)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		c.JSON(http.StatusUnauthorized, gin.H{"errors": "Invalid password"})
+		return
+	}
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not change password for user %d: %v", user.ID, err.Error()))
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -59,6 +59,20 @@ func TestRegistrationHandler_HandleRegistration(t *testing.T) {
 				Password: "123",
 			},
 		},
+		{
+			name:            "referral code does not exist",
+			registerUserErr: services.ErrReferralCodeDoesNotExist,
+			want: WantResponse{
+				statusCode: http.StatusUnprocessableEntity,
+				response:   `{"errors":"Referral code is not valid"}`,
+			},
+			shouldCallRegService: true,
+			regInput: registrationInput{
+				Login:        "John",
+				Password:     "123",
+				ReferralCode: "UNKNOWN",
+			},
+		},
 		{
 			name: "invalid input - no password field",
 			want: WantResponse{
@@ -96,7 +110,9 @@ func TestRegistrationHandler_HandleRegistration(t *testing.T) {
 			defer ctrl.Finish()
 			regServiceMock := mock_handlers.NewMockRegistrationService(ctrl)
 			if tt.shouldCallRegService {
-				user := domain.UserDTO{Login: tt.regInput.Login, Password: tt.regInput.Password}
+				user := domain.UserDTO{
+					Login: tt.regInput.Login, Password: tt.regInput.Password, ReferrerCode: tt.regInput.ReferralCode,
+				}
 				regServiceMock.EXPECT().RegisterUser(request.Context(), user).Return(tt.registerUserRes, tt.registerUserErr)
 			}
 			r := gin.Default()
@@ -189,3 +205,64 @@ func TestLoginHandler_HandleLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestPasswordHandler_HandleChangePassword(t *testing.T) {
+	user := &domain.UserDTO{ID: 1, Login: "John"}
+	tests := []struct {
+		name         string
+		input        changePasswordInput
+		changeErr    error
+		shouldChange bool
+		wantStatus   int
+		wantResponse string
+	}{
+		{
+			name:         "password is changed",
+			input:        changePasswordInput{OldPassword: "old", NewPassword: "new"},
+			shouldChange: true,
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:         "wrong current password",
+			input:        changePasswordInput{OldPassword: "wrong", NewPassword: "new"},
+			changeErr:    services.ErrInvalidCredentials,
+			shouldChange: true,
+			wantStatus:   http.StatusUnauthorized,
+			wantResponse: `{"errors":"Invalid password"}`,
+		},
+		{
+			name:         "no new password",
+			input:        changePasswordInput{OldPassword: "old"},
+			wantStatus:   http.StatusBadRequest,
+			wantResponse: `{"errors":"Key: 'changePasswordInput.NewPassword' Error:Field validation for 'NewPassword' failed on the 'required' tag"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBodyBytes, err := json.Marshal(&tt.input)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(reqBodyBytes))
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(request.Context()).Return(user, true)
+			passwordServiceMock := mock_handlers.NewMockPasswordService(ctrl)
+			if tt.shouldChange {
+				passwordServiceMock.EXPECT().ChangePassword(
+					request.Context(), user, tt.input.OldPassword, tt.input.NewPassword,
+				).Return(tt.changeErr)
+			}
+
+			r := gin.Default()
+			passwordHandler := NewPasswordHandler(authServiceMock, passwordServiceMock)
+			r.POST("/", passwordHandler.HandleChangePassword)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantResponse, w.Body.String())
+		})
+	}
+}
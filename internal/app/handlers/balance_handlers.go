@@ -2,17 +2,26 @@ package handlers
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"gophermart/internal/app/domain"
 	"gophermart/internal/app/repositories"
+	"gophermart/internal/app/services"
 	"net/http"
+	"time"
+)
+
+const (
+	mimeCSV               = "text/csv"
+	defaultStatementLimit = 100
 )
 
 type UserBalanceService interface {
 	WithdrawBalanceForOrder(ctx context.Context, withdrawal *domain.Withdrawal) error
 	GetBalanceWithdrawals(ctx context.Context, userID int) ([]*domain.Withdrawal, error)
 	GetUserBalance(ctx context.Context, userID int) (*domain.BalanceData, error)
+	GetStatement(ctx context.Context, userID int, filter domain.StatementFilter) ([]*domain.StatementLine, error)
 }
 
 type UserBalanceHandler struct {
@@ -73,6 +82,9 @@ func (h *UserBalanceHandler) HandleWithdrawBalance(c *gin.Context) {
 		UserID: user.ID,
 	}
 	err := h.balanceService.WithdrawBalanceForOrder(c.Request.Context(), withdrawalToCreate)
+	if abortWithWithdrawalRuleError(c, err) {
+		return
+	}
 	if errors.Is(err, repositories.ErrOrderAlreadyExists) {
 		c.JSON(http.StatusConflict, gin.H{"errors": "Order already exists"})
 		return
@@ -87,6 +99,16 @@ func (h *UserBalanceHandler) HandleWithdrawBalance(c *gin.Context) {
 	}
 }
 
+// abortWithWithdrawalRuleError отвечает кодом нарушенного правила, если списание отклонено правилами списания
+func abortWithWithdrawalRuleError(c *gin.Context, err error) bool {
+	var ruleErr *services.WithdrawalRuleError
+	if !errors.As(err, &ruleErr) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": ruleErr.Message, "code": ruleErr.Code})
+	return true
+}
+
 func (h *UserBalanceHandler) HandleListBalanceWithdrawals(c *gin.Context) {
 	user, ok := h.authService.GetUserFromContext(c.Request.Context())
 	if !ok {
@@ -106,3 +128,66 @@ func (h *UserBalanceHandler) HandleListBalanceWithdrawals(c *gin.Context) {
 
 	c.JSON(http.StatusOK, &withdrawals)
 }
+
+// HandleGetStatement возвращает выписку по счету пользователя в формате JSON или CSV в зависимости от заголовка Accept
+func (h *UserBalanceHandler) HandleGetStatement(c *gin.Context) {
+	user, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	input := StatementInput{Limit: defaultStatementLimit}
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+	filter := domain.StatementFilter{From: input.From, Limit: input.Limit, Offset: input.Offset}
+	if !input.To.IsZero() {
+		filter.To = input.To.AddDate(0, 0, 1)
+	}
+
+	format := c.NegotiateFormat(gin.MIMEJSON, mimeCSV)
+	if format == "" {
+		c.AbortWithStatus(http.StatusNotAcceptable)
+		return
+	}
+
+	lines, err := h.balanceService.GetStatement(c.Request.Context(), user.ID, filter)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if len(lines) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	if format == mimeCSV {
+		writeStatementCSV(c, lines)
+		return
+	}
+	c.JSON(http.StatusOK, lines)
+}
+
+func writeStatementCSV(c *gin.Context, lines []*domain.StatementLine) {
+	c.Header("Content-Type", mimeCSV)
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{
+		"created_at", "operation", "reference", "amount", "balance", "order", "order_status", "withdrawal_reversed",
+	})
+	for _, line := range lines {
+		_ = w.Write([]string{
+			line.CreatedAt.Format(time.RFC3339),
+			line.Operation,
+			line.Reference,
+			line.Amount.String(),
+			line.Balance.String(),
+			line.OrderNumber,
+			line.OrderStatus,
+			line.WithdrawalReversed.String(),
+		})
+	}
+	w.Flush()
+}
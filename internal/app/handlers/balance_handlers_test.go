@@ -10,6 +10,7 @@ import (
 	"gophermart/internal/app/domain"
 	mock_handlers "gophermart/internal/app/handlers/mocks"
 	"gophermart/internal/app/repositories"
+	"gophermart/internal/app/services"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -76,6 +77,7 @@ func TestUserBalanceHandler_HandleGetUserBalance(t *testing.T) {
 
 func TestUserBalanceHandler_HandleWithdrawBalance(t *testing.T) {
 	type WantErrorResponseBody struct {
+		Code   string `json:"code,omitempty"`
 		Errors string `json:"errors"`
 	}
 
@@ -109,6 +111,16 @@ func TestUserBalanceHandler_HandleWithdrawBalance(t *testing.T) {
 			wantStatusCode:             http.StatusPaymentRequired,
 			reqInput:                   inputData,
 		},
+		{
+			name:                       "daily limit exceeded",
+			withdrawBalanceForOrderRes: services.ErrWithdrawalDailyLimitExceeded,
+			wantErrRespBody: &WantErrorResponseBody{
+				Code:   "DAILY_LIMIT_EXCEEDED",
+				Errors: "Daily withdrawal limit exceeded",
+			},
+			wantStatusCode: http.StatusUnprocessableEntity,
+			reqInput:       inputData,
+		},
 		{
 			name:           "sum is negative",
 			wantStatusCode: http.StatusBadRequest,
@@ -218,3 +230,107 @@ func TestUserBalanceHandler_HandleListBalanceWithdrawals(t *testing.T) {
 		})
 	}
 }
+
+func TestUserBalanceHandler_HandleGetStatement(t *testing.T) {
+	createdAt := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	lines := []*domain.StatementLine{
+		{
+			Operation:   domain.LedgerOperationAccrual,
+			Reference:   "2377225624",
+			Amount:      domain.NewMoney(100, 50),
+			Balance:     domain.NewMoney(100, 50),
+			CreatedAt:   createdAt,
+			OrderNumber: "2377225624",
+			OrderStatus: domain.OrderProcessedStatus,
+		},
+		{
+			Operation:          domain.LedgerOperationWithdrawal,
+			Reference:          "2377225625",
+			Amount:             -domain.NewMoney(40, 0),
+			Balance:            domain.NewMoney(60, 50),
+			CreatedAt:          createdAt.Add(time.Hour),
+			OrderNumber:        "2377225625",
+			WithdrawalReversed: domain.NewMoney(10, 0),
+		},
+	}
+	user := &domain.UserDTO{ID: 1}
+	tests := []struct {
+		name            string
+		query           string
+		accept          string
+		shouldCall      bool
+		wantFilter      domain.StatementFilter
+		wantStatusCode  int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:       "json statement",
+			query:      "?from=2022-03-01&to=2022-03-31&limit=10&offset=5",
+			accept:     "application/json",
+			shouldCall: true,
+			wantFilter: domain.StatementFilter{
+				From:   time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+				Limit:  10,
+				Offset: 5,
+			},
+			wantStatusCode:  http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+		},
+		{
+			name:            "csv statement",
+			accept:          "text/csv",
+			shouldCall:      true,
+			wantFilter:      domain.StatementFilter{Limit: defaultStatementLimit},
+			wantStatusCode:  http.StatusOK,
+			wantContentType: "text/csv",
+			wantBody: "created_at,operation,reference,amount,balance,order,order_status,withdrawal_reversed\n" +
+				"2022-03-01T12:00:00Z,ACCRUAL,2377225624,100.5,100.5,2377225624,PROCESSED,0\n" +
+				"2022-03-01T13:00:00Z,WITHDRAWAL,2377225625,-40,60.5,2377225625,,10\n",
+		},
+		{
+			name:           "invalid limit",
+			query:          "?limit=0",
+			accept:         "application/json",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported format",
+			accept:         "application/xml",
+			wantStatusCode: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/statement"+tt.query, nil)
+			request.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(user, true)
+			balanceServiceMock := mock_handlers.NewMockUserBalanceService(ctrl)
+			if tt.shouldCall {
+				balanceServiceMock.EXPECT().GetStatement(gomock.Any(), user.ID, tt.wantFilter).Return(lines, nil)
+			}
+
+			r := gin.Default()
+			userBalanceHandler := NewUserBalanceHandler(
+				mock_handlers.NewMockOrderNumberValidator(ctrl), authServiceMock, balanceServiceMock,
+			)
+			r.GET("/statement", userBalanceHandler.HandleGetStatement)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantContentType != "" {
+				assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+			}
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"gophermart/internal/app/services"
+	"net/http"
+	"strconv"
+)
+
+type CampaignService interface {
+	CreateCampaign(ctx context.Context, campaign *domain.Campaign) error
+	UpdateCampaign(ctx context.Context, campaign *domain.Campaign) error
+	DeleteCampaign(ctx context.Context, campaignID int) error
+	GetCampaign(ctx context.Context, campaignID int) (*domain.Campaign, error)
+	GetCampaigns(ctx context.Context) ([]*domain.Campaign, error)
+}
+
+// AdminCampaignHandler обрабатывает запросы администраторов на управление промо-акциями
+type AdminCampaignHandler struct {
+	campaignService CampaignService
+}
+
+func NewAdminCampaignHandler(campaignService CampaignService) *AdminCampaignHandler {
+	return &AdminCampaignHandler{campaignService: campaignService}
+}
+
+func (h *AdminCampaignHandler) HandleListCampaigns(c *gin.Context) {
+	campaigns, err := h.campaignService.GetCampaigns(c.Request.Context())
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if len(campaigns) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, campaigns)
+}
+
+func (h *AdminCampaignHandler) HandleGetCampaign(c *gin.Context) {
+	campaignID, ok := campaignIDParam(c)
+	if !ok {
+		return
+	}
+
+	campaign, err := h.campaignService.GetCampaign(c.Request.Context(), campaignID)
+	if abortWithCampaignError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+func (h *AdminCampaignHandler) HandleCreateCampaign(c *gin.Context) {
+	var input CampaignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	campaign := input.toCampaign()
+	err := h.campaignService.CreateCampaign(c.Request.Context(), campaign)
+	if abortWithCampaignError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, campaign)
+}
+
+func (h *AdminCampaignHandler) HandleUpdateCampaign(c *gin.Context) {
+	campaignID, ok := campaignIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input CampaignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	campaign := input.toCampaign()
+	campaign.ID = campaignID
+	err := h.campaignService.UpdateCampaign(c.Request.Context(), campaign)
+	if abortWithCampaignError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+func (h *AdminCampaignHandler) HandleDeleteCampaign(c *gin.Context) {
+	campaignID, ok := campaignIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := h.campaignService.DeleteCampaign(c.Request.Context(), campaignID)
+	if abortWithCampaignError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func campaignIDParam(c *gin.Context) (int, bool) {
+	campaignID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": "Campaign id is not valid"})
+		return 0, false
+	}
+	return campaignID, true
+}
+
+// abortWithCampaignError отвечает на запрос, завершившийся ошибкой, и возвращает true, если ошибка была
+func abortWithCampaignError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, repositories.ErrCampaignDoesNotExist):
+		c.JSON(http.StatusNotFound, gin.H{"errors": "Campaign does not exist"})
+	case errors.Is(err, repositories.ErrCampaignHasBonuses):
+		c.JSON(http.StatusConflict, gin.H{"errors": "Campaign has awarded bonuses, deactivate it instead"})
+	case errors.Is(err, services.ErrInvalidCampaign):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+	default:
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}
+	return true
+}
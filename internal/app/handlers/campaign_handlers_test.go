@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"gophermart/internal/app/repositories"
+	"gophermart/internal/app/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdminCampaignHandler_HandleCreateCampaign(t *testing.T) {
+	type WantErrorResponseBody struct {
+		Errors string `json:"errors"`
+	}
+
+	startsAt := time.Date(2022, 3, 5, 0, 0, 0, 0, time.UTC)
+	inputData := &CampaignInput{
+		Name:     "double points this weekend",
+		Type:     domain.CampaignTypeMultiplier,
+		StartsAt: startsAt,
+		EndsAt:   startsAt.Add(48 * time.Hour),
+	}
+	tests := []struct {
+		name              string
+		reqInput          *CampaignInput
+		shouldCallService bool
+		createErr         error
+		wantStatusCode    int
+		wantErrRespBody   *WantErrorResponseBody
+	}{
+		{
+			name:              "positive test",
+			reqInput:          inputData,
+			shouldCallService: true,
+			wantStatusCode:    http.StatusCreated,
+		},
+		{
+			name:              "invalid campaign",
+			reqInput:          inputData,
+			shouldCallService: true,
+			createErr:         fmt.Errorf("%w: multiplier must be greater than 1", services.ErrInvalidCampaign),
+			wantStatusCode:    http.StatusUnprocessableEntity,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "invalid campaign: multiplier must be greater than 1"},
+		},
+		{
+			name: "unknown type",
+			reqInput: &CampaignInput{
+				Name: "unknown", Type: "CASHBACK", StartsAt: startsAt, EndsAt: startsAt.Add(time.Hour),
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody, err := json.Marshal(tt.reqInput)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/campaigns", bytes.NewReader(reqBody))
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			campaignServiceMock := mock_handlers.NewMockCampaignService(ctrl)
+			if tt.shouldCallService {
+				// по умолчанию акция создается включенной
+				campaignServiceMock.EXPECT().CreateCampaign(gomock.Any(), &domain.Campaign{
+					Name:     tt.reqInput.Name,
+					Type:     tt.reqInput.Type,
+					StartsAt: tt.reqInput.StartsAt,
+					EndsAt:   tt.reqInput.EndsAt,
+					Active:   true,
+				}).Return(tt.createErr)
+			}
+
+			r := gin.Default()
+			campaignHandler := NewAdminCampaignHandler(campaignServiceMock)
+			r.POST("/campaigns", campaignHandler.HandleCreateCampaign)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantErrRespBody != nil {
+				expectedResponse, err := json.Marshal(tt.wantErrRespBody)
+				require.NoError(t, err)
+				assert.Equal(t, string(expectedResponse), w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminCampaignHandler_HandleDeleteCampaign(t *testing.T) {
+	tests := []struct {
+		name           string
+		deleteErr      error
+		wantStatusCode int
+	}{
+		{name: "positive test", wantStatusCode: http.StatusNoContent},
+		{name: "campaign does not exist", deleteErr: repositories.ErrCampaignDoesNotExist, wantStatusCode: http.StatusNotFound},
+		{name: "campaign has bonuses", deleteErr: repositories.ErrCampaignHasBonuses, wantStatusCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodDelete, "/campaigns/7", nil)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			campaignServiceMock := mock_handlers.NewMockCampaignService(ctrl)
+			campaignServiceMock.EXPECT().DeleteCampaign(gomock.Any(), 7).Return(tt.deleteErr)
+
+			r := gin.Default()
+			campaignHandler := NewAdminCampaignHandler(campaignServiceMock)
+			r.DELETE("/campaigns/:id", campaignHandler.HandleDeleteCampaign)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+		})
+	}
+}
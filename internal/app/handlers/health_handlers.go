@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	healthCheckTimeout = 2 * time.Second
+	mimeMetrics        = "text/plain; version=0.0.4"
+)
+
+// Общее состояние сервиса
+// DEGRADED означает, что сервис отвечает на запросы, но заказы не обрабатываются из-за недоступности системы расчета
+const (
+	healthStatusOK          = "OK"
+	healthStatusDegraded    = "DEGRADED"
+	healthStatusUnavailable = "UNAVAILABLE"
+)
+
+type DatabasePinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type AccrualSystemMonitor interface {
+	CircuitBreakerStats() domain.CircuitBreakerStats
+}
+
+type HealthHandler struct {
+	db             DatabasePinger
+	accrualMonitor AccrualSystemMonitor
+}
+
+func NewHealthHandler(db DatabasePinger, accrualMonitor AccrualSystemMonitor) *HealthHandler {
+	return &HealthHandler{db: db, accrualMonitor: accrualMonitor}
+}
+
+// HandleHealth проверяет доступность базы данных и состояние выключателя запросов к системе расчета баллов
+// если база данных недоступна, возвращается 503
+func (h *HealthHandler) HandleHealth(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	accrualStats := h.accrualMonitor.CircuitBreakerStats()
+	status, database := healthStatusOK, healthStatusOK
+	if accrualStats.State != domain.CircuitClosed {
+		status = healthStatusDegraded
+	}
+	if err := h.db.PingContext(ctx); err != nil {
+		status, database = healthStatusUnavailable, healthStatusUnavailable
+	}
+
+	statusCode := http.StatusOK
+	if status == healthStatusUnavailable {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.JSON(statusCode, gin.H{"status": status, "database": database, "accrual": accrualStats})
+}
+
+// HandleMetrics возвращает метрики в текстовом формате Prometheus
+func (h *HealthHandler) HandleMetrics(c *gin.Context) {
+	stats := h.accrualMonitor.CircuitBreakerStats()
+
+	var b strings.Builder
+	b.WriteString("# HELP gophermart_accrual_circuit_state Accrual system circuit breaker state, 1 for the current state.\n")
+	b.WriteString("# TYPE gophermart_accrual_circuit_state gauge\n")
+	for _, state := range []string{domain.CircuitClosed, domain.CircuitOpen, domain.CircuitHalfOpen} {
+		value := 0
+		if state == stats.State {
+			value = 1
+		}
+		fmt.Fprintf(&b, "gophermart_accrual_circuit_state{state=%q} %d\n", state, value)
+	}
+	writeMetric(&b, "gophermart_accrual_circuit_consecutive_failures", "gauge",
+		"Accrual system requests failed in a row.", int64(stats.ConsecutiveFailures))
+	writeMetric(&b, "gophermart_accrual_requests_failed_total", "counter",
+		"Accrual system requests failed with a network or server error.", stats.FailuresTotal)
+	writeMetric(&b, "gophermart_accrual_requests_rejected_total", "counter",
+		"Accrual system requests rejected by the open circuit breaker.", stats.RejectedTotal)
+	writeMetric(&b, "gophermart_accrual_circuit_opened_total", "counter",
+		"Times the accrual system circuit breaker opened.", stats.OpenedTotal)
+
+	c.Data(http.StatusOK, mimeMetrics, []byte(b.String()))
+}
+
+func writeMetric(b *strings.Builder, name string, metricType string, help string, value int64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, metricType, name, value)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthHandler_HandleHealth(t *testing.T) {
+	tests := []struct {
+		name           string
+		pingErr        error
+		circuitState   string
+		wantStatusCode int
+		wantStatus     string
+	}{
+		{
+			name:           "everything is ok",
+			circuitState:   domain.CircuitClosed,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     `"status":"OK"`,
+		},
+		{
+			name:           "accrual circuit is open",
+			circuitState:   domain.CircuitOpen,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     `"status":"DEGRADED"`,
+		},
+		{
+			name:           "database is unavailable",
+			pingErr:        errors.New("connection refused"),
+			circuitState:   domain.CircuitClosed,
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantStatus:     `"status":"UNAVAILABLE"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			dbMock := mock_handlers.NewMockDatabasePinger(ctrl)
+			dbMock.EXPECT().PingContext(gomock.Any()).Return(tt.pingErr)
+			monitorMock := mock_handlers.NewMockAccrualSystemMonitor(ctrl)
+			monitorMock.EXPECT().CircuitBreakerStats().Return(domain.CircuitBreakerStats{State: tt.circuitState})
+
+			r := gin.Default()
+			healthHandler := NewHealthHandler(dbMock, monitorMock)
+			r.GET("/api/health", healthHandler.HandleHealth)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantStatus)
+		})
+	}
+}
+
+func TestHealthHandler_HandleMetrics(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	monitorMock := mock_handlers.NewMockAccrualSystemMonitor(ctrl)
+	monitorMock.EXPECT().CircuitBreakerStats().Return(domain.CircuitBreakerStats{
+		State: domain.CircuitOpen, ConsecutiveFailures: 5, FailuresTotal: 7, RejectedTotal: 3, OpenedTotal: 1,
+	})
+
+	r := gin.Default()
+	healthHandler := NewHealthHandler(mock_handlers.NewMockDatabasePinger(ctrl), monitorMock)
+	r.GET("/metrics", healthHandler.HandleMetrics)
+	r.ServeHTTP(w, request)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	for _, line := range []string{
+		`gophermart_accrual_circuit_state{state="CLOSED"} 0`,
+		`gophermart_accrual_circuit_state{state="OPEN"} 1`,
+		`gophermart_accrual_circuit_consecutive_failures 5`,
+		`gophermart_accrual_requests_failed_total 7`,
+		`gophermart_accrual_requests_rejected_total 3`,
+		`gophermart_accrual_circuit_opened_total 1`,
+	} {
+		assert.True(t, strings.Contains(body, line+"\n"), line)
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"net/http"
+	"strconv"
+)
+
+type HoldService interface {
+	AuthorizeHold(ctx context.Context, hold *domain.BalanceHold) error
+	CaptureHold(ctx context.Context, userID int, holdID int) (*domain.BalanceHold, error)
+	VoidHold(ctx context.Context, userID int, holdID int) (*domain.BalanceHold, error)
+}
+
+type HoldHandler struct {
+	orderNumberValidator OrderNumberValidator
+	authService          AuthService
+	holdService          HoldService
+}
+
+func NewHoldHandler(
+	orderNumberValidator OrderNumberValidator, authService AuthService, holdService HoldService,
+) *HoldHandler {
+	return &HoldHandler{
+		orderNumberValidator: orderNumberValidator,
+		authService:          authService,
+		holdService:          holdService,
+	}
+}
+
+// HandleAuthorizeHold обрабатывает POST запрос на резервирование баллов в счет заказа до завершения оплаты
+func (h *HoldHandler) HandleAuthorizeHold(c *gin.Context) {
+	user, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var input BalanceHoldInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if !h.orderNumberValidator.Validate(input.OrderNumber) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": "Order number is not valid"})
+		return
+	}
+
+	hold := &domain.BalanceHold{
+		UserID: user.ID,
+		Order:  input.OrderNumber,
+		Sum:    input.Sum,
+	}
+	err := h.holdService.AuthorizeHold(c.Request.Context(), hold)
+	if abortWithWithdrawalRuleError(c, err) {
+		return
+	}
+	if errors.Is(err, repositories.ErrOrderAlreadyExists) {
+		c.JSON(http.StatusConflict, gin.H{"errors": "Order already exists"})
+		return
+	}
+	if errors.Is(err, repositories.ErrCanNotWithdrawBalance) {
+		c.JSON(http.StatusPaymentRequired, gin.H{"errors": "Not enough points in user's balance"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// HandleCaptureHold обрабатывает POST запрос на списание зарезервированных баллов
+func (h *HoldHandler) HandleCaptureHold(c *gin.Context) {
+	h.resolveHold(c, h.holdService.CaptureHold)
+}
+
+// HandleVoidHold обрабатывает POST запрос на отмену резерва и возврат баллов на счет
+func (h *HoldHandler) HandleVoidHold(c *gin.Context) {
+	h.resolveHold(c, h.holdService.VoidHold)
+}
+
+func (h *HoldHandler) resolveHold(
+	c *gin.Context, resolve func(ctx context.Context, userID int, holdID int) (*domain.BalanceHold, error),
+) {
+	user, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	holdID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": "Hold id is not valid"})
+		return
+	}
+
+	hold, err := resolve(c.Request.Context(), user.ID, holdID)
+	if errors.Is(err, repositories.ErrHoldDoesNotExist) {
+		c.JSON(http.StatusNotFound, gin.H{"errors": "Hold does not exist"})
+		return
+	}
+	if errors.Is(err, repositories.ErrHoldIsNotActive) {
+		c.JSON(http.StatusConflict, gin.H{"errors": "Hold is not active"})
+		return
+	}
+	if errors.Is(err, repositories.ErrOrderAlreadyExists) {
+		c.JSON(http.StatusConflict, gin.H{"errors": "Order already exists"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
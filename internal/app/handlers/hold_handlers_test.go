@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"gophermart/internal/app/repositories"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHoldHandler_HandleAuthorizeHold(t *testing.T) {
+	type WantErrorResponseBody struct {
+		Errors string `json:"errors"`
+	}
+
+	user := &domain.UserDTO{ID: 1}
+	inputData := &BalanceHoldInput{OrderNumber: "2377225624", Sum: domain.NewMoney(50, 0)}
+	tests := []struct {
+		name              string
+		reqInput          *BalanceHoldInput
+		orderNumberValid  bool
+		shouldCallService bool
+		authorizeHoldErr  error
+		wantStatusCode    int
+		wantErrRespBody   *WantErrorResponseBody
+	}{
+		{
+			name:              "positive test",
+			reqInput:          inputData,
+			orderNumberValid:  true,
+			shouldCallService: true,
+			wantStatusCode:    http.StatusCreated,
+		},
+		{
+			name:              "not enough points",
+			reqInput:          inputData,
+			orderNumberValid:  true,
+			shouldCallService: true,
+			authorizeHoldErr:  repositories.ErrCanNotWithdrawBalance,
+			wantStatusCode:    http.StatusPaymentRequired,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Not enough points in user's balance"},
+		},
+		{
+			name:              "order already exists",
+			reqInput:          inputData,
+			orderNumberValid:  true,
+			shouldCallService: true,
+			authorizeHoldErr:  repositories.ErrOrderAlreadyExists,
+			wantStatusCode:    http.StatusConflict,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Order already exists"},
+		},
+		{
+			name:            "invalid order number",
+			reqInput:        inputData,
+			wantStatusCode:  http.StatusUnprocessableEntity,
+			wantErrRespBody: &WantErrorResponseBody{Errors: "Order number is not valid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody, err := json.Marshal(tt.reqInput)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/balance/holds", bytes.NewReader(reqBody))
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(user, true)
+			orderValidatorMock := mock_handlers.NewMockOrderNumberValidator(ctrl)
+			orderValidatorMock.EXPECT().Validate(tt.reqInput.OrderNumber).Return(tt.orderNumberValid)
+			holdServiceMock := mock_handlers.NewMockHoldService(ctrl)
+			if tt.shouldCallService {
+				holdServiceMock.EXPECT().AuthorizeHold(gomock.Any(), &domain.BalanceHold{
+					UserID: user.ID,
+					Order:  tt.reqInput.OrderNumber,
+					Sum:    tt.reqInput.Sum,
+				}).Return(tt.authorizeHoldErr)
+			}
+
+			r := gin.Default()
+			holdHandler := NewHoldHandler(orderValidatorMock, authServiceMock, holdServiceMock)
+			r.POST("/balance/holds", holdHandler.HandleAuthorizeHold)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantErrRespBody != nil {
+				expectedResponse, err := json.Marshal(tt.wantErrRespBody)
+				require.NoError(t, err)
+				assert.Equal(t, string(expectedResponse), w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHoldHandler_HandleCaptureHold(t *testing.T) {
+	type WantErrorResponseBody struct {
+		Errors string `json:"errors"`
+	}
+
+	user := &domain.UserDTO{ID: 1}
+	tests := []struct {
+		name              string
+		holdID            string
+		shouldCallService bool
+		captureHoldErr    error
+		wantStatusCode    int
+		wantErrRespBody   *WantErrorResponseBody
+	}{
+		{
+			name:              "positive test",
+			holdID:            "5",
+			shouldCallService: true,
+			wantStatusCode:    http.StatusOK,
+		},
+		{
+			name:              "hold does not exist",
+			holdID:            "5",
+			shouldCallService: true,
+			captureHoldErr:    repositories.ErrHoldDoesNotExist,
+			wantStatusCode:    http.StatusNotFound,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Hold does not exist"},
+		},
+		{
+			name:              "hold is not active",
+			holdID:            "5",
+			shouldCallService: true,
+			captureHoldErr:    repositories.ErrHoldIsNotActive,
+			wantStatusCode:    http.StatusConflict,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Hold is not active"},
+		},
+		{
+			name:            "invalid hold id",
+			holdID:          "abc",
+			wantStatusCode:  http.StatusBadRequest,
+			wantErrRespBody: &WantErrorResponseBody{Errors: "Hold id is not valid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/balance/holds/"+tt.holdID+"/capture", nil)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(user, true)
+			holdServiceMock := mock_handlers.NewMockHoldService(ctrl)
+			if tt.shouldCallService {
+				holdServiceMock.EXPECT().CaptureHold(gomock.Any(), user.ID, 5).
+					Return(&domain.BalanceHold{ID: 5, Status: domain.HoldCapturedStatus}, tt.captureHoldErr)
+			}
+
+			r := gin.Default()
+			holdHandler := NewHoldHandler(mock_handlers.NewMockOrderNumberValidator(ctrl), authServiceMock, holdServiceMock)
+			r.POST("/balance/holds/:id/capture", holdHandler.HandleCaptureHold)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantErrRespBody != nil {
+				expectedResponse, err := json.Marshal(tt.wantErrRespBody)
+				require.NoError(t, err)
+				assert.Equal(t, string(expectedResponse), w.Body.String())
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package handlers
 
+import (
+	"gophermart/internal/app/domain"
+	"time"
+)
+
 type registrationInput struct {
-	Login    string `json:"login" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Login        string `json:"login" binding:"required"`
+	Password     string `json:"password" binding:"required"`
+	ReferralCode string `json:"referral_code,omitempty" binding:"omitempty,max=16"`
 }
 
 type loginInput struct {
@@ -10,7 +16,98 @@ type loginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type changePasswordInput struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
 type BalanceWithdrawalInput struct {
-	OrderNumber string  `json:"order" binding:"required"`
-	Sum         float32 `json:"sum" binding:"required,numeric,gt=0"`
+	OrderNumber string       `json:"order" binding:"required"`
+	Sum         domain.Money `json:"sum" binding:"required,numeric,gt=0"`
+}
+
+type BalanceHoldInput struct {
+	OrderNumber string       `json:"order" binding:"required"`
+	Sum         domain.Money `json:"sum" binding:"required,gt=0"`
+}
+
+// StatementInput параметры выписки, даты периода включаются в выписку целиком
+type StatementInput struct {
+	From   time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To     time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	Limit  int       `form:"limit" binding:"min=1,max=1000"`
+	Offset int       `form:"offset" binding:"omitempty,min=0"`
+}
+
+type WithdrawalReversalInput struct {
+	Sum    domain.Money `json:"sum" binding:"gte=0"`
+	Reason string       `json:"reason" binding:"required,max=512"`
+}
+
+type TransferInput struct {
+	Login string       `json:"login" binding:"required"`
+	Sum   domain.Money `json:"sum" binding:"required,gt=0"`
+	Note  string       `json:"note" binding:"max=256"`
+}
+
+type CampaignInput struct {
+	Name           string       `json:"name" binding:"required,max=64"`
+	Type           string       `json:"type" binding:"required,oneof=MULTIPLIER FIXED_BONUS"`
+	Multiplier     float64      `json:"multiplier"`
+	Bonus          domain.Money `json:"bonus"`
+	FirstOrderOnly bool         `json:"first_order_only"`
+	StartsAt       time.Time    `json:"starts_at" binding:"required"`
+	EndsAt         time.Time    `json:"ends_at" binding:"required"`
+	Active         *bool        `json:"active"`
+}
+
+// toCampaign возвращает акцию с параметрами из запроса, по умолчанию акция включена
+func (input *CampaignInput) toCampaign() *domain.Campaign {
+	campaign := &domain.Campaign{
+		Name:           input.Name,
+		Type:           input.Type,
+		Multiplier:     input.Multiplier,
+		Bonus:          input.Bonus,
+		FirstOrderOnly: input.FirstOrderOnly,
+		StartsAt:       input.StartsAt,
+		EndsAt:         input.EndsAt,
+		Active:         true,
+	}
+	if input.Active != nil {
+		campaign.Active = *input.Active
+	}
+	return campaign
+}
+
+// VoucherBatchInput параметры выпускаемой партии ваучеров, по умолчанию ваучер можно погасить один раз
+type VoucherBatchInput struct {
+	Count      int          `json:"count" binding:"required,min=1,max=10000"`
+	Value      domain.Money `json:"value" binding:"required,gt=0"`
+	ExpiresAt  time.Time    `json:"expires_at" binding:"required"`
+	UsageLimit int          `json:"usage_limit" binding:"min=1"`
+}
+
+type VoucherRedemptionInput struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// AdjustmentInput корректировка баланса: положительная сумма начисляет баллы, отрицательная - списывает
+type AdjustmentInput struct {
+	Amount domain.Money `json:"amount" binding:"required"`
+	Reason string       `json:"reason" binding:"required,max=256"`
+}
+
+// AccrualWorkerPoolInput новое количество воркеров, обрабатывающих заказы
+type AccrualWorkerPoolInput struct {
+	Size *int `json:"size" binding:"required,min=0,max=64"`
+}
+
+type DeadLetteredJobsInput struct {
+	Limit  int `form:"limit" binding:"min=1,max=1000"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+type AuditLogInput struct {
+	Limit  int `form:"limit" binding:"min=1,max=1000"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
 }
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/handlers (interfaces: AccrualJobService)
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAccrualJobService is a mock of AccrualJobService interface.
+type MockAccrualJobService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualJobServiceMockRecorder
+}
+
+// MockAccrualJobServiceMockRecorder is the mock recorder for MockAccrualJobService.
+type MockAccrualJobServiceMockRecorder struct {
+	mock *MockAccrualJobService
+}
+
+// NewMockAccrualJobService creates a new mock instance.
+func NewMockAccrualJobService(ctrl *gomock.Controller) *MockAccrualJobService {
+	mock := &MockAccrualJobService{ctrl: ctrl}
+	mock.recorder = &MockAccrualJobServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualJobService) EXPECT() *MockAccrualJobServiceMockRecorder {
+	return m.recorder
+}
+
+// GetDeadLetteredJobs mocks base method.
+func (m *MockAccrualJobService) GetDeadLetteredJobs(arg0 context.Context, arg1, arg2 int) ([]*domain.AccrualJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetteredJobs", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.AccrualJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetteredJobs indicates an expected call of GetDeadLetteredJobs.
+func (mr *MockAccrualJobServiceMockRecorder) GetDeadLetteredJobs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetteredJobs", reflect.TypeOf((*MockAccrualJobService)(nil).GetDeadLetteredJobs), arg0, arg1, arg2)
+}
+
+// RequeueJob mocks base method.
+func (m *MockAccrualJobService) RequeueJob(arg0 context.Context, arg1 int64, arg2 int) (*domain.AccrualJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueJob", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.AccrualJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueJob indicates an expected call of RequeueJob.
+func (mr *MockAccrualJobServiceMockRecorder) RequeueJob(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueJob", reflect.TypeOf((*MockAccrualJobService)(nil).RequeueJob), arg0, arg1, arg2)
+}
@@ -0,0 +1,60 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/handlers (interfaces: AccrualWorkerPool)
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAccrualWorkerPool is a mock of AccrualWorkerPool interface.
+type MockAccrualWorkerPool struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualWorkerPoolMockRecorder
+}
+
+// MockAccrualWorkerPoolMockRecorder is the mock recorder for MockAccrualWorkerPool.
+type MockAccrualWorkerPoolMockRecorder struct {
+	mock *MockAccrualWorkerPool
+}
+
+// NewMockAccrualWorkerPool creates a new mock instance.
+func NewMockAccrualWorkerPool(ctrl *gomock.Controller) *MockAccrualWorkerPool {
+	mock := &MockAccrualWorkerPool{ctrl: ctrl}
+	mock.recorder = &MockAccrualWorkerPoolMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualWorkerPool) EXPECT() *MockAccrualWorkerPoolMockRecorder {
+	return m.recorder
+}
+
+// Resize mocks base method.
+func (m *MockAccrualWorkerPool) Resize(arg0 int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Resize", arg0)
+}
+
+// Resize indicates an expected call of Resize.
+func (mr *MockAccrualWorkerPoolMockRecorder) Resize(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resize", reflect.TypeOf((*MockAccrualWorkerPool)(nil).Resize), arg0)
+}
+
+// Size mocks base method.
+func (m *MockAccrualWorkerPool) Size() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Size")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Size indicates an expected call of Size.
+func (mr *MockAccrualWorkerPoolMockRecorder) Size() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Size", reflect.TypeOf((*MockAccrualWorkerPool)(nil).Size))
+}
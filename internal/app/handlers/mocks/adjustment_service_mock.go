@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/handlers (interfaces: AdjustmentService)
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAdjustmentService is a mock of AdjustmentService interface.
+type MockAdjustmentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdjustmentServiceMockRecorder
+}
+
+// MockAdjustmentServiceMockRecorder is the mock recorder for MockAdjustmentService.
+type MockAdjustmentServiceMockRecorder struct {
+	mock *MockAdjustmentService
+}
+
+// NewMockAdjustmentService creates a new mock instance.
+func NewMockAdjustmentService(ctrl *gomock.Controller) *MockAdjustmentService {
+	mock := &MockAdjustmentService{ctrl: ctrl}
+	mock.recorder = &MockAdjustmentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdjustmentService) EXPECT() *MockAdjustmentServiceMockRecorder {
+	return m.recorder
+}
+
+// ApproveAdjustment mocks base method.
+func (m *MockAdjustmentService) ApproveAdjustment(arg0 context.Context, arg1, arg2 int) (*domain.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveAdjustment", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveAdjustment indicates an expected call of ApproveAdjustment.
+func (mr *MockAdjustmentServiceMockRecorder) ApproveAdjustment(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveAdjustment", reflect.TypeOf((*MockAdjustmentService)(nil).ApproveAdjustment), arg0, arg1, arg2)
+}
+
+// CreateAdjustment mocks base method.
+func (m *MockAdjustmentService) CreateAdjustment(arg0 context.Context, arg1 *domain.BalanceAdjustment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdjustment", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAdjustment indicates an expected call of CreateAdjustment.
+func (mr *MockAdjustmentServiceMockRecorder) CreateAdjustment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdjustment", reflect.TypeOf((*MockAdjustmentService)(nil).CreateAdjustment), arg0, arg1)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockAdjustmentService) GetAuditLog(arg0 context.Context, arg1, arg2 int) ([]*domain.AuditLogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.AuditLogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockAdjustmentServiceMockRecorder) GetAuditLog(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockAdjustmentService)(nil).GetAuditLog), arg0, arg1, arg2)
+}
+
+// GetUserAdjustments mocks base method.
+func (m *MockAdjustmentService) GetUserAdjustments(arg0 context.Context, arg1 int) ([]*domain.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAdjustments", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAdjustments indicates an expected call of GetUserAdjustments.
+func (mr *MockAdjustmentServiceMockRecorder) GetUserAdjustments(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAdjustments", reflect.TypeOf((*MockAdjustmentService)(nil).GetUserAdjustments), arg0, arg1)
+}
+
+// RejectAdjustment mocks base method.
+func (m *MockAdjustmentService) RejectAdjustment(arg0 context.Context, arg1, arg2 int) (*domain.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectAdjustment", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectAdjustment indicates an expected call of RejectAdjustment.
+func (mr *MockAdjustmentServiceMockRecorder) RejectAdjustment(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectAdjustment", reflect.TypeOf((*MockAdjustmentService)(nil).RejectAdjustment), arg0, arg1, arg2)
+}
@@ -50,6 +50,21 @@ func (mr *MockUserBalanceServiceMockRecorder) GetBalanceWithdrawals(arg0, arg1 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceWithdrawals", reflect.TypeOf((*MockUserBalanceService)(nil).GetBalanceWithdrawals), arg0, arg1)
 }
 
+// GetStatement mocks base method.
+func (m *MockUserBalanceService) GetStatement(arg0 context.Context, arg1 int, arg2 domain.StatementFilter) ([]*domain.StatementLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.StatementLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockUserBalanceServiceMockRecorder) GetStatement(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockUserBalanceService)(nil).GetStatement), arg0, arg1, arg2)
+}
+
 // GetUserBalance mocks base method.
 func (m *MockUserBalanceService) GetUserBalance(arg0 context.Context, arg1 int) (*domain.BalanceData, error) {
 	m.ctrl.T.Helper()
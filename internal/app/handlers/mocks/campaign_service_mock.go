@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/handlers (interfaces: CampaignService)
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCampaignService is a mock of CampaignService interface.
+type MockCampaignService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCampaignServiceMockRecorder
+}
+
+// MockCampaignServiceMockRecorder is the mock recorder for MockCampaignService.
+type MockCampaignServiceMockRecorder struct {
+	mock *MockCampaignService
+}
+
+// NewMockCampaignService creates a new mock instance.
+func NewMockCampaignService(ctrl *gomock.Controller) *MockCampaignService {
+	mock := &MockCampaignService{ctrl: ctrl}
+	mock.recorder = &MockCampaignServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCampaignService) EXPECT() *MockCampaignServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateCampaign mocks base method.
+func (m *MockCampaignService) CreateCampaign(arg0 context.Context, arg1 *domain.Campaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockCampaignServiceMockRecorder) CreateCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockCampaignService)(nil).CreateCampaign), arg0, arg1)
+}
+
+// DeleteCampaign mocks base method.
+func (m *MockCampaignService) DeleteCampaign(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCampaign indicates an expected call of DeleteCampaign.
+func (mr *MockCampaignServiceMockRecorder) DeleteCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCampaign", reflect.TypeOf((*MockCampaignService)(nil).DeleteCampaign), arg0, arg1)
+}
+
+// GetCampaign mocks base method.
+func (m *MockCampaignService) GetCampaign(arg0 context.Context, arg1 int) (*domain.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaign", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaign indicates an expected call of GetCampaign.
+func (mr *MockCampaignServiceMockRecorder) GetCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaign", reflect.TypeOf((*MockCampaignService)(nil).GetCampaign), arg0, arg1)
+}
+
+// GetCampaigns mocks base method.
+func (m *MockCampaignService) GetCampaigns(arg0 context.Context) ([]*domain.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaigns", arg0)
+	ret0, _ := ret[0].([]*domain.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaigns indicates an expected call of GetCampaigns.
+func (mr *MockCampaignServiceMockRecorder) GetCampaigns(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaigns", reflect.TypeOf((*MockCampaignService)(nil).GetCampaigns), arg0)
+}
+
+// UpdateCampaign mocks base method.
+func (m *MockCampaignService) UpdateCampaign(arg0 context.Context, arg1 *domain.Campaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCampaign indicates an expected call of UpdateCampaign.
+func (mr *MockCampaignServiceMockRecorder) UpdateCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCampaign", reflect.TypeOf((*MockCampaignService)(nil).UpdateCampaign), arg0, arg1)
+}
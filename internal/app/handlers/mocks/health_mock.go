@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/handlers (interfaces: DatabasePinger,AccrualSystemMonitor)
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDatabasePinger is a mock of DatabasePinger interface.
+type MockDatabasePinger struct {
+	ctrl     *gomock.Controller
+	recorder *MockDatabasePingerMockRecorder
+}
+
+// MockDatabasePingerMockRecorder is the mock recorder for MockDatabasePinger.
+type MockDatabasePingerMockRecorder struct {
+	mock *MockDatabasePinger
+}
+
+// NewMockDatabasePinger creates a new mock instance.
+func NewMockDatabasePinger(ctrl *gomock.Controller) *MockDatabasePinger {
+	mock := &MockDatabasePinger{ctrl: ctrl}
+	mock.recorder = &MockDatabasePingerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDatabasePinger) EXPECT() *MockDatabasePingerMockRecorder {
+	return m.recorder
+}
+
+// PingContext mocks base method.
+func (m *MockDatabasePinger) PingContext(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PingContext", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PingContext indicates an expected call of PingContext.
+func (mr *MockDatabasePingerMockRecorder) PingContext(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PingContext", reflect.TypeOf((*MockDatabasePinger)(nil).PingContext), arg0)
+}
+
+// MockAccrualSystemMonitor is a mock of AccrualSystemMonitor interface.
+type MockAccrualSystemMonitor struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualSystemMonitorMockRecorder
+}
+
+// MockAccrualSystemMonitorMockRecorder is the mock recorder for MockAccrualSystemMonitor.
+type MockAccrualSystemMonitorMockRecorder struct {
+	mock *MockAccrualSystemMonitor
+}
+
+// NewMockAccrualSystemMonitor creates a new mock instance.
+func NewMockAccrualSystemMonitor(ctrl *gomock.Controller) *MockAccrualSystemMonitor {
+	mock := &MockAccrualSystemMonitor{ctrl: ctrl}
+	mock.recorder = &MockAccrualSystemMonitorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualSystemMonitor) EXPECT() *MockAccrualSystemMonitorMockRecorder {
+	return m.recorder
+}
+
+// CircuitBreakerStats mocks base method.
+func (m *MockAccrualSystemMonitor) CircuitBreakerStats() domain.CircuitBreakerStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CircuitBreakerStats")
+	ret0, _ := ret[0].(domain.CircuitBreakerStats)
+	return ret0
+}
+
+// CircuitBreakerStats indicates an expected call of CircuitBreakerStats.
+func (mr *MockAccrualSystemMonitorMockRecorder) CircuitBreakerStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CircuitBreakerStats", reflect.TypeOf((*MockAccrualSystemMonitor)(nil).CircuitBreakerStats))
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/handlers (interfaces: ReferralService)
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReferralService is a mock of ReferralService interface.
+type MockReferralService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReferralServiceMockRecorder
+}
+
+// MockReferralServiceMockRecorder is the mock recorder for MockReferralService.
+type MockReferralServiceMockRecorder struct {
+	mock *MockReferralService
+}
+
+// NewMockReferralService creates a new mock instance.
+func NewMockReferralService(ctrl *gomock.Controller) *MockReferralService {
+	mock := &MockReferralService{ctrl: ctrl}
+	mock.recorder = &MockReferralServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReferralService) EXPECT() *MockReferralServiceMockRecorder {
+	return m.recorder
+}
+
+// GetReferralSummary mocks base method.
+func (m *MockReferralService) GetReferralSummary(arg0 context.Context, arg1 *domain.UserDTO) (*domain.ReferralSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralSummary", arg0, arg1)
+	ret0, _ := ret[0].(*domain.ReferralSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralSummary indicates an expected call of GetReferralSummary.
+func (mr *MockReferralServiceMockRecorder) GetReferralSummary(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralSummary", reflect.TypeOf((*MockReferralService)(nil).GetReferralSummary), arg0, arg1)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/handlers (interfaces: TierService)
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTierService is a mock of TierService interface.
+type MockTierService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTierServiceMockRecorder
+}
+
+// MockTierServiceMockRecorder is the mock recorder for MockTierService.
+type MockTierServiceMockRecorder struct {
+	mock *MockTierService
+}
+
+// NewMockTierService creates a new mock instance.
+func NewMockTierService(ctrl *gomock.Controller) *MockTierService {
+	mock := &MockTierService{ctrl: ctrl}
+	mock.recorder = &MockTierServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTierService) EXPECT() *MockTierServiceMockRecorder {
+	return m.recorder
+}
+
+// GetUserTier mocks base method.
+func (m *MockTierService) GetUserTier(arg0 context.Context, arg1 int) (*domain.UserTier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTier", arg0, arg1)
+	ret0, _ := ret[0].(*domain.UserTier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTier indicates an expected call of GetUserTier.
+func (mr *MockTierServiceMockRecorder) GetUserTier(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTier", reflect.TypeOf((*MockTierService)(nil).GetUserTier), arg0, arg1)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/handlers (interfaces: VoucherService)
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVoucherService is a mock of VoucherService interface.
+type MockVoucherService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVoucherServiceMockRecorder
+}
+
+// MockVoucherServiceMockRecorder is the mock recorder for MockVoucherService.
+type MockVoucherServiceMockRecorder struct {
+	mock *MockVoucherService
+}
+
+// NewMockVoucherService creates a new mock instance.
+func NewMockVoucherService(ctrl *gomock.Controller) *MockVoucherService {
+	mock := &MockVoucherService{ctrl: ctrl}
+	mock.recorder = &MockVoucherServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVoucherService) EXPECT() *MockVoucherServiceMockRecorder {
+	return m.recorder
+}
+
+// GenerateVouchers mocks base method.
+func (m *MockVoucherService) GenerateVouchers(arg0 context.Context, arg1 *domain.VoucherBatch, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateVouchers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GenerateVouchers indicates an expected call of GenerateVouchers.
+func (mr *MockVoucherServiceMockRecorder) GenerateVouchers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateVouchers", reflect.TypeOf((*MockVoucherService)(nil).GenerateVouchers), arg0, arg1, arg2)
+}
+
+// GetVoucherBatch mocks base method.
+func (m *MockVoucherService) GetVoucherBatch(arg0 context.Context, arg1 int) (*domain.VoucherBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVoucherBatch", arg0, arg1)
+	ret0, _ := ret[0].(*domain.VoucherBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVoucherBatch indicates an expected call of GetVoucherBatch.
+func (mr *MockVoucherServiceMockRecorder) GetVoucherBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVoucherBatch", reflect.TypeOf((*MockVoucherService)(nil).GetVoucherBatch), arg0, arg1)
+}
+
+// RedeemVoucher mocks base method.
+func (m *MockVoucherService) RedeemVoucher(arg0 context.Context, arg1 string, arg2 int) (*domain.VoucherRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemVoucher", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.VoucherRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemVoucher indicates an expected call of RedeemVoucher.
+func (mr *MockVoucherServiceMockRecorder) RedeemVoucher(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemVoucher", reflect.TypeOf((*MockVoucherService)(nil).RedeemVoucher), arg0, arg1, arg2)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/handlers (interfaces: WithdrawalReversalService)
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWithdrawalReversalService is a mock of WithdrawalReversalService interface.
+type MockWithdrawalReversalService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWithdrawalReversalServiceMockRecorder
+}
+
+// MockWithdrawalReversalServiceMockRecorder is the mock recorder for MockWithdrawalReversalService.
+type MockWithdrawalReversalServiceMockRecorder struct {
+	mock *MockWithdrawalReversalService
+}
+
+// NewMockWithdrawalReversalService creates a new mock instance.
+func NewMockWithdrawalReversalService(ctrl *gomock.Controller) *MockWithdrawalReversalService {
+	mock := &MockWithdrawalReversalService{ctrl: ctrl}
+	mock.recorder = &MockWithdrawalReversalServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWithdrawalReversalService) EXPECT() *MockWithdrawalReversalServiceMockRecorder {
+	return m.recorder
+}
+
+// ReverseWithdrawal mocks base method.
+func (m *MockWithdrawalReversalService) ReverseWithdrawal(arg0 context.Context, arg1 *domain.WithdrawalReversal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseWithdrawal", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReverseWithdrawal indicates an expected call of ReverseWithdrawal.
+func (mr *MockWithdrawalReversalServiceMockRecorder) ReverseWithdrawal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseWithdrawal", reflect.TypeOf((*MockWithdrawalReversalService)(nil).ReverseWithdrawal), arg0, arg1)
+}
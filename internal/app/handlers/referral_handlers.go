@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"net/http"
+)
+
+type ReferralService interface {
+	GetReferralSummary(ctx context.Context, user *domain.UserDTO) (*domain.ReferralSummary, error)
+}
+
+type ReferralHandler struct {
+	authService     AuthService
+	referralService ReferralService
+}
+
+func NewReferralHandler(authService AuthService, referralService ReferralService) *ReferralHandler {
+	return &ReferralHandler{authService: authService, referralService: referralService}
+}
+
+// HandleListReferrals возвращает реферальный код пользователя и приглашенных им пользователей со статусом бонуса
+func (h *ReferralHandler) HandleListReferrals(c *gin.Context) {
+	user, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	summary, err := h.referralService.GetReferralSummary(c.Request.Context(), user)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
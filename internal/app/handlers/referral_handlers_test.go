@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReferralHandler_HandleListReferrals(t *testing.T) {
+	user := &domain.UserDTO{ID: 1, ReferralCode: "ABCD2345"}
+	createdAt := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		summary        *domain.ReferralSummary
+		summaryErr     error
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name: "positive test",
+			summary: &domain.ReferralSummary{
+				ReferralCode: user.ReferralCode,
+				Referrals: []*domain.Referral{
+					{Login: "friend", Status: domain.ReferralPendingStatus, CreatedAt: createdAt},
+				},
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody: `{"referral_code":"ABCD2345","referrals":[` +
+				`{"login":"friend","status":"PENDING","created_at":"2022-05-01T10:00:00Z"}]}`,
+		},
+		{
+			name:           "service error",
+			summaryErr:     errors.New("unexpected error"),
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/referrals", nil)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(user, true)
+			referralServiceMock := mock_handlers.NewMockReferralService(ctrl)
+			referralServiceMock.EXPECT().GetReferralSummary(gomock.Any(), user).Return(tt.summary, tt.summaryErr)
+
+			r := gin.Default()
+			referralHandler := NewReferralHandler(authServiceMock, referralServiceMock)
+			r.GET("/referrals", referralHandler.HandleListReferrals)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
 	"gophermart/internal/app/configs"
 	"gophermart/internal/app/middlewares"
 	"gophermart/internal/app/repositories"
@@ -10,15 +12,37 @@ import (
 )
 
 func InitRouter(
-	db *sqlx.DB, cfg *configs.Config, orderService *services.OrderService, userService *services.UserService,
+	db *sqlx.DB,
+	cfg *configs.Config,
+	orderService *services.OrderService,
+	userService *services.UserService,
+	holdService *services.HoldService,
+	tierService *services.TierService,
+	withdrawalRules *services.WithdrawalRules,
+	campaignService *services.CampaignService,
+	referralService *services.ReferralService,
+	accrualCalculator *services.AccrualCalculationService,
+	accrualJobService *services.AccrualJobService,
+	idempotencyService *services.IdempotencyService,
+	accrualWorkerPool AccrualWorkerPool,
 ) *gin.Engine {
 	r := gin.Default()
+	// IP клиента берем из заголовков запроса только от доверенных прокси, иначе ограничение частоты запросов
+	// по IP обходится подменой X-Forwarded-For
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Error().Msg(fmt.Sprintf("invalid trusted proxies '%v', no proxies are trusted: %s", cfg.TrustedProxies, err))
+		_ = r.SetTrustedProxies(nil)
+	}
 	r.Use(middlewares.DecompressingRequestMiddleware())
 	r.Use(middlewares.CompressingResponseMiddleware())
 	tokenService := services.NewAuthJWTTokenService(cfg.AuthSecretKey)
 	registrationService := services.NewRegistrationService(userService, tokenService)
 	authService := services.NewAuthService(userService, tokenService)
 
+	healthHandler := NewHealthHandler(db, accrualCalculator)
+	r.GET("/api/health", healthHandler.HandleHealth)
+	r.GET("/metrics", healthHandler.HandleMetrics)
+
 	apiGroup := r.Group("/api/user")
 	registrationHandler := NewRegistrationHandler(registrationService)
 	apiGroup.POST("/register", registrationHandler.HandleRegistration)
@@ -28,18 +52,96 @@ func InitRouter(
 
 	needAuthURLsGroup := apiGroup.Group("")
 	needAuthURLsGroup.Use(middlewares.TokenAuthMiddleware(userService, authService))
+	needAuthURLsGroup.Use(middlewares.IdempotencyMiddleware(authService, idempotencyService))
+
+	passwordHandler := NewPasswordHandler(authService, userService)
+	needAuthURLsGroup.POST("/password", passwordHandler.HandleChangePassword)
 
 	orderNumberValidator := services.NewOrderNumberValidator()
 	orderHandler := NewOrderHandler(authService, orderService, orderNumberValidator)
 	needAuthURLsGroup.POST("/orders", orderHandler.HandleCreateOrder)
 	needAuthURLsGroup.GET("/orders", orderHandler.HandleListOrders)
 
-	balanceRepository := repositories.NewBalanceRepository(db)
-	balanceService := services.NewUserBalanceService(balanceRepository)
+	ledgerRepository := repositories.NewLedgerRepository(db, cfg.PointsLifetime)
+	balanceRepository := repositories.NewBalanceRepository(db, ledgerRepository)
+	balanceService := services.NewUserBalanceService(
+		balanceRepository, cfg.PointsExpiringSoonPeriod, withdrawalRules,
+	)
 	balanceHandler := NewUserBalanceHandler(orderNumberValidator, authService, balanceService)
 	needAuthURLsGroup.POST("/balance/withdraw", balanceHandler.HandleWithdrawBalance)
 	needAuthURLsGroup.GET("/withdrawals", balanceHandler.HandleListBalanceWithdrawals)
 	needAuthURLsGroup.GET("/balance", balanceHandler.HandleGetUserBalance)
+	needAuthURLsGroup.GET("/balance/statement", balanceHandler.HandleGetStatement)
+
+	tierHandler := NewTierHandler(authService, tierService)
+	needAuthURLsGroup.GET("/tier", tierHandler.HandleGetUserTier)
+
+	referralHandler := NewReferralHandler(authService, referralService)
+	needAuthURLsGroup.GET("/referrals", referralHandler.HandleListReferrals)
+
+	voucherService := services.NewVoucherService(repositories.NewVoucherRepository(db, ledgerRepository))
+	voucherHandler := NewVoucherHandler(authService, voucherService)
+	// ограничиваем частоту погашений, чтобы коды ваучеров нельзя было подобрать перебором
+	voucherRateLimiter := middlewares.NewRateLimiter(cfg.VoucherRedeemRateLimit, cfg.VoucherRedeemRateWindow)
+	needAuthURLsGroup.POST(
+		"/vouchers/redeem",
+		middlewares.RateLimitMiddleware(authService, voucherRateLimiter),
+		voucherHandler.HandleRedeemVoucher,
+	)
+
+	holdHandler := NewHoldHandler(orderNumberValidator, authService, holdService)
+	needAuthURLsGroup.POST("/balance/holds", holdHandler.HandleAuthorizeHold)
+	needAuthURLsGroup.POST("/balance/holds/:id/capture", holdHandler.HandleCaptureHold)
+	needAuthURLsGroup.POST("/balance/holds/:id/void", holdHandler.HandleVoidHold)
+
+	transferRepository := repositories.NewTransferRepository(db, ledgerRepository)
+	transferService := services.NewTransferService(transferRepository, userService, services.TransferLimits{
+		MinSum:     cfg.TransferMinSum,
+		MaxSum:     cfg.TransferMaxSum,
+		DailyLimit: cfg.TransferDailyLimit,
+	}, withdrawalRules)
+	transferHandler := NewTransferHandler(authService, transferService)
+	needAuthURLsGroup.POST("/balance/transfer", transferHandler.HandleCreateTransfer)
+	needAuthURLsGroup.GET("/transfers", transferHandler.HandleListTransfers)
+
+	adminGroup := r.Group("/api/admin")
+	adminGroup.Use(middlewares.TokenAuthMiddleware(userService, authService))
+	adminGroup.Use(middlewares.AdminMiddleware(authService))
+	adminGroup.Use(middlewares.IdempotencyMiddleware(authService, idempotencyService))
+
+	adminBalanceHandler := NewAdminBalanceHandler(authService, balanceService)
+	adminGroup.POST("/withdrawals/:id/reversals", adminBalanceHandler.HandleReverseWithdrawal)
+
+	adjustmentService := services.NewAdjustmentService(
+		repositories.NewAdjustmentRepository(db, ledgerRepository),
+		repositories.NewAuditLogRepository(db),
+		cfg.AdjustmentApprovalThreshold,
+	)
+	adminAdjustmentHandler := NewAdminAdjustmentHandler(authService, adjustmentService)
+	adminGroup.POST("/users/:id/adjustments", adminAdjustmentHandler.HandleCreateAdjustment)
+	adminGroup.GET("/users/:id/adjustments", adminAdjustmentHandler.HandleListAdjustments)
+	adminGroup.POST("/adjustments/:id/approve", adminAdjustmentHandler.HandleApproveAdjustment)
+	adminGroup.POST("/adjustments/:id/reject", adminAdjustmentHandler.HandleRejectAdjustment)
+	adminGroup.GET("/audit-log", adminAdjustmentHandler.HandleGetAuditLog)
+
+	adminCampaignHandler := NewAdminCampaignHandler(campaignService)
+	adminGroup.GET("/campaigns", adminCampaignHandler.HandleListCampaigns)
+	adminGroup.POST("/campaigns", adminCampaignHandler.HandleCreateCampaign)
+	adminGroup.GET("/campaigns/:id", adminCampaignHandler.HandleGetCampaign)
+	adminGroup.PUT("/campaigns/:id", adminCampaignHandler.HandleUpdateCampaign)
+	adminGroup.DELETE("/campaigns/:id", adminCampaignHandler.HandleDeleteCampaign)
+
+	adminVoucherHandler := NewAdminVoucherHandler(authService, voucherService)
+	adminGroup.POST("/vouchers/batches", adminVoucherHandler.HandleCreateVoucherBatch)
+	adminGroup.GET("/vouchers/batches/:id/export", adminVoucherHandler.HandleExportVoucherBatch)
+
+	adminWorkerPoolHandler := NewAdminWorkerPoolHandler(authService, accrualWorkerPool)
+	adminGroup.GET("/accrual/workers", adminWorkerPoolHandler.HandleGetAccrualWorkers)
+	adminGroup.PUT("/accrual/workers", adminWorkerPoolHandler.HandleResizeAccrualWorkers)
+
+	adminAccrualJobHandler := NewAdminAccrualJobHandler(authService, accrualJobService)
+	adminGroup.GET("/accrual/dead-letters", adminAccrualJobHandler.HandleListDeadLetteredJobs)
+	adminGroup.POST("/accrual/dead-letters/:id/requeue", adminAccrualJobHandler.HandleRequeueJob)
 
 	return r
 }
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"net/http"
+)
+
+type TierService interface {
+	GetUserTier(ctx context.Context, userID int) (*domain.UserTier, error)
+}
+
+type TierHandler struct {
+	authService AuthService
+	tierService TierService
+}
+
+func NewTierHandler(authService AuthService, tierService TierService) *TierHandler {
+	return &TierHandler{authService: authService, tierService: tierService}
+}
+
+// HandleGetUserTier возвращает уровень пользователя в программе лояльности и прогресс до следующего уровня
+func (h *TierHandler) HandleGetUserTier(c *gin.Context) {
+	user, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userTier, err := h.tierService.GetUserTier(c.Request.Context(), user.ID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, userTier)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTierHandler_HandleGetUserTier(t *testing.T) {
+	user := &domain.UserDTO{ID: 1}
+	calculatedAt := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		authorized     bool
+		userTier       *domain.UserTier
+		userTierErr    error
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:       "positive test",
+			authorized: true,
+			userTier: &domain.UserTier{
+				Tier:             domain.TierSilver,
+				Multiplier:       1.1,
+				Earned:           domain.NewMoney(1500, 0),
+				NextTier:         domain.TierGold,
+				PointsToNextTier: domain.NewMoney(3500, 0),
+				CalculatedAt:     &calculatedAt,
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody: `{"tier":"SILVER","multiplier":1.1,"earned":1500,"next_tier":"GOLD",` +
+				`"points_to_next_tier":3500,"calculated_at":"2022-05-01T10:00:00Z"}`,
+		},
+		{
+			name:           "tier is not calculated yet",
+			authorized:     true,
+			userTier:       &domain.UserTier{Tier: domain.TierBasic, Multiplier: 1},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"tier":"BASIC","multiplier":1,"earned":0}`,
+		},
+		{
+			name:           "unauthorized",
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "service error",
+			authorized:     true,
+			userTierErr:    errors.New("unexpected error"),
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/tier", nil)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			tierServiceMock := mock_handlers.NewMockTierService(ctrl)
+			if tt.authorized {
+				authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(user, true)
+				tierServiceMock.EXPECT().GetUserTier(gomock.Any(), user.ID).Return(tt.userTier, tt.userTierErr)
+			} else {
+				authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(nil, false)
+			}
+
+			r := gin.Default()
+			tierHandler := NewTierHandler(authServiceMock, tierServiceMock)
+			r.GET("/tier", tierHandler.HandleGetUserTier)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
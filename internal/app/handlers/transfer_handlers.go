@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"gophermart/internal/app/services"
+	"net/http"
+)
+
+type TransferService interface {
+	TransferPoints(
+		ctx context.Context, sender *domain.UserDTO, recipientLogin string, sum domain.Money, note string,
+	) (*domain.Transfer, error)
+	GetUserTransfers(ctx context.Context, userID int) ([]*domain.Transfer, error)
+}
+
+type TransferHandler struct {
+	authService     AuthService
+	transferService TransferService
+}
+
+func NewTransferHandler(authService AuthService, transferService TransferService) *TransferHandler {
+	return &TransferHandler{authService: authService, transferService: transferService}
+}
+
+// HandleCreateTransfer обрабатывает POST запрос на перевод баллов другому пользователю
+func (h *TransferHandler) HandleCreateTransfer(c *gin.Context) {
+	user, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var input TransferInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	transfer, err := h.transferService.TransferPoints(c.Request.Context(), user, input.Login, input.Sum, input.Note)
+	if errors.Is(err, services.ErrTransferRecipientNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"errors": "Recipient does not exist"})
+		return
+	}
+	if errors.Is(err, services.ErrSelfTransfer) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": "Can not transfer points to yourself"})
+		return
+	}
+	if errors.Is(err, services.ErrTransferLimitExceeded) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": "Transfer limit exceeded"})
+		return
+	}
+	if abortWithWithdrawalRuleError(c, err) {
+		return
+	}
+	if errors.Is(err, repositories.ErrCanNotWithdrawBalance) {
+		c.JSON(http.StatusPaymentRequired, gin.H{"errors": "Not enough points in user's balance"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// HandleListTransfers возвращает отправленные и полученные пользователем переводы
+func (h *TransferHandler) HandleListTransfers(c *gin.Context) {
+	user, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	transfers, err := h.transferService.GetUserTransfers(c.Request.Context(), user.ID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if len(transfers) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
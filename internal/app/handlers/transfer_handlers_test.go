@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"gophermart/internal/app/repositories"
+	"gophermart/internal/app/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransferHandler_HandleCreateTransfer(t *testing.T) {
+	type WantErrorResponseBody struct {
+		Code   string `json:"code,omitempty"`
+		Errors string `json:"errors"`
+	}
+
+	user := &domain.UserDTO{ID: 1, Login: "sender"}
+	inputData := &TransferInput{Login: "recipient", Sum: domain.NewMoney(50, 0), Note: "for lunch"}
+	tests := []struct {
+		name              string
+		reqInput          *TransferInput
+		shouldCallService bool
+		transferErr       error
+		wantStatusCode    int
+		wantErrRespBody   *WantErrorResponseBody
+	}{
+		{
+			name:              "positive test",
+			reqInput:          inputData,
+			shouldCallService: true,
+			wantStatusCode:    http.StatusOK,
+		},
+		{
+			name:              "recipient does not exist",
+			reqInput:          inputData,
+			shouldCallService: true,
+			transferErr:       services.ErrTransferRecipientNotFound,
+			wantStatusCode:    http.StatusNotFound,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Recipient does not exist"},
+		},
+		{
+			name:              "transfer to yourself",
+			reqInput:          inputData,
+			shouldCallService: true,
+			transferErr:       services.ErrSelfTransfer,
+			wantStatusCode:    http.StatusUnprocessableEntity,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Can not transfer points to yourself"},
+		},
+		{
+			name:              "limit exceeded",
+			reqInput:          inputData,
+			shouldCallService: true,
+			transferErr:       services.ErrTransferLimitExceeded,
+			wantStatusCode:    http.StatusUnprocessableEntity,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Transfer limit exceeded"},
+		},
+		{
+			name:              "password was changed recently",
+			reqInput:          inputData,
+			shouldCallService: true,
+			transferErr:       services.ErrWithdrawalPasswordCooldown,
+			wantStatusCode:    http.StatusUnprocessableEntity,
+			wantErrRespBody: &WantErrorResponseBody{
+				Code:   "PASSWORD_CHANGE_COOLDOWN",
+				Errors: services.ErrWithdrawalPasswordCooldown.Message,
+			},
+		},
+		{
+			name:              "not enough points",
+			reqInput:          inputData,
+			shouldCallService: true,
+			transferErr:       repositories.ErrCanNotWithdrawBalance,
+			wantStatusCode:    http.StatusPaymentRequired,
+			wantErrRespBody:   &WantErrorResponseBody{Errors: "Not enough points in user's balance"},
+		},
+		{
+			name:           "no recipient login",
+			reqInput:       &TransferInput{Sum: domain.NewMoney(50, 0)},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "negative sum",
+			reqInput:       &TransferInput{Login: "recipient", Sum: domain.NewMoney(-50, 0)},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody, err := json.Marshal(tt.reqInput)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/balance/transfer", bytes.NewReader(reqBody))
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(user, true)
+			transferServiceMock := mock_handlers.NewMockTransferService(ctrl)
+			if tt.shouldCallService {
+				transferServiceMock.EXPECT().TransferPoints(
+					gomock.Any(), user, tt.reqInput.Login, tt.reqInput.Sum, tt.reqInput.Note,
+				).Return(&domain.Transfer{}, tt.transferErr)
+			}
+
+			r := gin.Default()
+			transferHandler := NewTransferHandler(authServiceMock, transferServiceMock)
+			r.POST("/balance/transfer", transferHandler.HandleCreateTransfer)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantErrRespBody != nil {
+				expectedResponse, err := json.Marshal(tt.wantErrRespBody)
+				require.NoError(t, err)
+				assert.Equal(t, string(expectedResponse), w.Body.String())
+			}
+		})
+	}
+}
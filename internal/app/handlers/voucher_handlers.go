@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"gophermart/internal/app/services"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type VoucherService interface {
+	GenerateVouchers(ctx context.Context, batch *domain.VoucherBatch, count int) error
+	GetVoucherBatch(ctx context.Context, batchID int) (*domain.VoucherBatch, error)
+	RedeemVoucher(ctx context.Context, code string, userID int) (*domain.VoucherRedemption, error)
+}
+
+type VoucherHandler struct {
+	authService    AuthService
+	voucherService VoucherService
+}
+
+func NewVoucherHandler(authService AuthService, voucherService VoucherService) *VoucherHandler {
+	return &VoucherHandler{authService: authService, voucherService: voucherService}
+}
+
+// HandleRedeemVoucher гасит ваучер и начисляет его номинал на баланс пользователя
+func (h *VoucherHandler) HandleRedeemVoucher(c *gin.Context) {
+	user, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var input VoucherRedemptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	redemption, err := h.voucherService.RedeemVoucher(c.Request.Context(), input.Code, user.ID)
+	switch {
+	case errors.Is(err, repositories.ErrVoucherDoesNotExist):
+		c.JSON(http.StatusNotFound, gin.H{"errors": "Voucher does not exist"})
+		return
+	case errors.Is(err, repositories.ErrVoucherAlreadyRedeemed):
+		c.JSON(http.StatusConflict, gin.H{"errors": "Voucher was already redeemed"})
+		return
+	case errors.Is(err, repositories.ErrVoucherExpired):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": "Voucher has expired"})
+		return
+	case errors.Is(err, repositories.ErrVoucherUsageLimitReached):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": "Voucher usage limit reached"})
+		return
+	case err != nil:
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, redemption)
+}
+
+// AdminVoucherHandler обрабатывает запросы администраторов на выпуск ваучеров
+type AdminVoucherHandler struct {
+	authService    AuthService
+	voucherService VoucherService
+}
+
+func NewAdminVoucherHandler(authService AuthService, voucherService VoucherService) *AdminVoucherHandler {
+	return &AdminVoucherHandler{authService: authService, voucherService: voucherService}
+}
+
+// HandleCreateVoucherBatch выпускает партию ваучеров и возвращает их коды
+func (h *AdminVoucherHandler) HandleCreateVoucherBatch(c *gin.Context) {
+	admin, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	input := VoucherBatchInput{UsageLimit: 1}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	batch := &domain.VoucherBatch{
+		Value:      input.Value,
+		ExpiresAt:  input.ExpiresAt,
+		UsageLimit: input.UsageLimit,
+		CreatedBy:  admin.ID,
+	}
+	err := h.voucherService.GenerateVouchers(c.Request.Context(), batch, input.Count)
+	if errors.Is(err, services.ErrInvalidVoucherBatch) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, batch)
+}
+
+// HandleExportVoucherBatch выгружает ваучеры партии в формате CSV для печати
+func (h *AdminVoucherHandler) HandleExportVoucherBatch(c *gin.Context) {
+	batchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": "Voucher batch id is not valid"})
+		return
+	}
+
+	batch, err := h.voucherService.GetVoucherBatch(c.Request.Context(), batchID)
+	if errors.Is(err, repositories.ErrVoucherBatchDoesNotExist) {
+		c.JSON(http.StatusNotFound, gin.H{"errors": "Voucher batch does not exist"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", mimeCSV)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="vouchers-%d.csv"`, batch.ID))
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"code", "value", "expires_at", "usage_limit", "used_count"})
+	for _, voucher := range batch.Vouchers {
+		_ = w.Write([]string{
+			voucher.Code,
+			batch.Value.String(),
+			batch.ExpiresAt.Format(time.RFC3339),
+			strconv.Itoa(batch.UsageLimit),
+			strconv.Itoa(voucher.UsedCount),
+		})
+	}
+	w.Flush()
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"gophermart/internal/app/repositories"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVoucherHandler_HandleRedeemVoucher(t *testing.T) {
+	user := &domain.UserDTO{ID: 1}
+	redeemedAt := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name              string
+		reqInput          *VoucherRedemptionInput
+		shouldCallService bool
+		redemption        *domain.VoucherRedemption
+		redeemErr         error
+		wantStatusCode    int
+		wantBody          string
+	}{
+		{
+			name:              "positive test",
+			reqInput:          &VoucherRedemptionInput{Code: "ABCD2345EFGH"},
+			shouldCallService: true,
+			redemption:        &domain.VoucherRedemption{Sum: domain.NewMoney(500, 0), RedeemedAt: redeemedAt},
+			wantStatusCode:    http.StatusOK,
+			wantBody:          `{"sum":500,"redeemed_at":"2022-05-01T10:00:00Z"}`,
+		},
+		{
+			name:              "voucher does not exist",
+			reqInput:          &VoucherRedemptionInput{Code: "ABCD2345EFGH"},
+			shouldCallService: true,
+			redeemErr:         repositories.ErrVoucherDoesNotExist,
+			wantStatusCode:    http.StatusNotFound,
+			wantBody:          `{"errors":"Voucher does not exist"}`,
+		},
+		{
+			name:              "voucher was already redeemed",
+			reqInput:          &VoucherRedemptionInput{Code: "ABCD2345EFGH"},
+			shouldCallService: true,
+			redeemErr:         repositories.ErrVoucherAlreadyRedeemed,
+			wantStatusCode:    http.StatusConflict,
+			wantBody:          `{"errors":"Voucher was already redeemed"}`,
+		},
+		{
+			name:              "voucher has expired",
+			reqInput:          &VoucherRedemptionInput{Code: "ABCD2345EFGH"},
+			shouldCallService: true,
+			redeemErr:         repositories.ErrVoucherExpired,
+			wantStatusCode:    http.StatusUnprocessableEntity,
+			wantBody:          `{"errors":"Voucher has expired"}`,
+		},
+		{
+			name:           "no code",
+			reqInput:       &VoucherRedemptionInput{},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody, err := json.Marshal(tt.reqInput)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/vouchers/redeem", bytes.NewReader(reqBody))
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(user, true)
+			voucherServiceMock := mock_handlers.NewMockVoucherService(ctrl)
+			if tt.shouldCallService {
+				voucherServiceMock.EXPECT().RedeemVoucher(
+					gomock.Any(), tt.reqInput.Code, user.ID,
+				).Return(tt.redemption, tt.redeemErr)
+			}
+
+			r := gin.Default()
+			voucherHandler := NewVoucherHandler(authServiceMock, voucherServiceMock)
+			r.POST("/vouchers/redeem", voucherHandler.HandleRedeemVoucher)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminVoucherHandler_HandleExportVoucherBatch(t *testing.T) {
+	batch := &domain.VoucherBatch{
+		ID:         3,
+		Value:      domain.NewMoney(500, 0),
+		ExpiresAt:  time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+		UsageLimit: 1,
+		Vouchers: []*domain.Voucher{
+			{Code: "ABCD2345EFGH"},
+			{Code: "JKLM6789NPQR", UsedCount: 1},
+		},
+	}
+	tests := []struct {
+		name           string
+		batchID        string
+		shouldCall     bool
+		getBatchErr    error
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:           "positive test",
+			batchID:        "3",
+			shouldCall:     true,
+			wantStatusCode: http.StatusOK,
+			wantBody: "code,value,expires_at,usage_limit,used_count\n" +
+				"ABCD2345EFGH,500,2022-12-31T00:00:00Z,1,0\n" +
+				"JKLM6789NPQR,500,2022-12-31T00:00:00Z,1,1\n",
+		},
+		{
+			name:           "batch does not exist",
+			batchID:        "3",
+			shouldCall:     true,
+			getBatchErr:    repositories.ErrVoucherBatchDoesNotExist,
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       `{"errors":"Voucher batch does not exist"}`,
+		},
+		{
+			name:           "invalid batch id",
+			batchID:        "abc",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       `{"errors":"Voucher batch id is not valid"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/vouchers/batches/"+tt.batchID+"/export", nil)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			voucherServiceMock := mock_handlers.NewMockVoucherService(ctrl)
+			if tt.shouldCall {
+				voucherServiceMock.EXPECT().GetVoucherBatch(gomock.Any(), batch.ID).Return(batch, tt.getBatchErr)
+			}
+
+			r := gin.Default()
+			adminVoucherHandler := NewAdminVoucherHandler(authServiceMock, voucherServiceMock)
+			r.GET("/vouchers/batches/:id/export", adminVoucherHandler.HandleExportVoucherBatch)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"net/http"
+)
+
+// AccrualWorkerPool пул воркеров, обрабатывающих заказы, размер которого можно менять во время работы
+type AccrualWorkerPool interface {
+	Size() int
+	Resize(size int)
+}
+
+// AdminWorkerPoolHandler позволяет администраторам менять количество воркеров, обрабатывающих заказы
+type AdminWorkerPoolHandler struct {
+	authService AuthService
+	pool        AccrualWorkerPool
+}
+
+func NewAdminWorkerPoolHandler(authService AuthService, pool AccrualWorkerPool) *AdminWorkerPoolHandler {
+	return &AdminWorkerPoolHandler{authService: authService, pool: pool}
+}
+
+func (h *AdminWorkerPoolHandler) HandleGetAccrualWorkers(c *gin.Context) {
+	c.JSON(http.StatusOK, &domain.AccrualWorkerPoolStatus{Size: h.pool.Size()})
+}
+
+// HandleResizeAccrualWorkers меняет количество воркеров, 0 приостанавливает обработку заказов
+func (h *AdminWorkerPoolHandler) HandleResizeAccrualWorkers(c *gin.Context) {
+	admin, ok := h.authService.GetUserFromContext(c.Request.Context())
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var input AccrualWorkerPoolInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	log.Info().Msg(fmt.Sprintf("admin %d resizes accrual worker pool to %d", admin.ID, *input.Size))
+	h.pool.Resize(*input.Size)
+	c.JSON(http.StatusOK, &domain.AccrualWorkerPoolStatus{Size: h.pool.Size()})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	mock_handlers "gophermart/internal/app/handlers/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminWorkerPoolHandler_HandleResizeAccrualWorkers(t *testing.T) {
+	admin := &domain.UserDTO{ID: 1, IsAdmin: true}
+	tests := []struct {
+		name           string
+		reqBody        string
+		wantResize     bool
+		wantSize       int
+		wantStatusCode int
+		wantRespBody   string
+	}{
+		{
+			name:           "positive test",
+			reqBody:        `{"size": 5}`,
+			wantResize:     true,
+			wantSize:       5,
+			wantStatusCode: http.StatusOK,
+			wantRespBody:   `{"size":5}`,
+		},
+		{
+			name:           "pool is paused",
+			reqBody:        `{"size": 0}`,
+			wantResize:     true,
+			wantSize:       0,
+			wantStatusCode: http.StatusOK,
+			wantRespBody:   `{"size":0}`,
+		},
+		{
+			name:           "no size",
+			reqBody:        `{}`,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "size is too big",
+			reqBody:        `{"size": 1000}`,
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, "/accrual/workers", bytes.NewBufferString(tt.reqBody))
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_handlers.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(admin, true)
+			poolMock := mock_handlers.NewMockAccrualWorkerPool(ctrl)
+			if tt.wantResize {
+				poolMock.EXPECT().Resize(tt.wantSize)
+				poolMock.EXPECT().Size().Return(tt.wantSize)
+			}
+
+			r := gin.Default()
+			workerPoolHandler := NewAdminWorkerPoolHandler(authServiceMock, poolMock)
+			r.PUT("/accrual/workers", workerPoolHandler.HandleResizeAccrualWorkers)
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantRespBody != "" {
+				assert.Equal(t, tt.wantRespBody, w.Body.String())
+			}
+		})
+	}
+}
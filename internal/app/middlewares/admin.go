@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// AdminMiddleware пропускает запрос дальше, только если он выполняется администратором
+// должен использоваться после TokenAuthMiddleware
+func AdminMiddleware(authService AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := authService.GetUserFromContext(c.Request.Context())
+		if !ok {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if !user.IsAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errors": "Admin privileges required"})
+			return
+		}
+
+		c.Next()
+	}
+}
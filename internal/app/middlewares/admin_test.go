@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	mock_middlewares "gophermart/internal/app/middlewares/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		user           *domain.UserDTO
+		userInContext  bool
+		wantStatusCode int
+	}{
+		{
+			name:           "no user in context",
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "user is not admin",
+			user:           &domain.UserDTO{ID: 1},
+			userInContext:  true,
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "user is admin",
+			user:           &domain.UserDTO{ID: 1, IsAdmin: true},
+			userInContext:  true,
+			wantStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_middlewares.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(tt.user, tt.userInContext)
+
+			router := gin.Default()
+			router.Use(AdminMiddleware(authServiceMock))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			router.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+		})
+	}
+}
@@ -19,6 +19,7 @@ type UserService interface {
 type AuthService interface {
 	AddUserToContext(ctx context.Context, user *domain.UserDTO) context.Context
 	ParseUserToken(tokenString string) (string, error)
+	GetUserFromContext(ctx context.Context) (*domain.UserDTO, bool)
 }
 
 func TokenAuthMiddleware(userService UserService, authService AuthService) gin.HandlerFunc {
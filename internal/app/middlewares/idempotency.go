@@ -0,0 +1,158 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/services"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLen     = 255
+	// idempotencyStoreTimeout время на сохранение ответа или освобождение ключа после выполнения запроса
+	idempotencyStoreTimeout = 5 * time.Second
+)
+
+type IdempotencyService interface {
+	StartRequest(ctx context.Context, userID int, key string, fingerprint string) (*domain.IdempotentResponse, error)
+	SaveResponse(ctx context.Context, userID int, key string, response *domain.IdempotentResponse) error
+	ReleaseKey(ctx context.Context, userID int, key string) error
+}
+
+// bodyRecordingWriter дублирует тело ответа в буфер, чтобы его можно было сохранить
+type bodyRecordingWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyRecordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// requestFingerprint вычисляет отпечаток запроса по методу, пути и телу
+func requestFingerprint(method string, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// storeContext возвращает контекст для сохранения результата запроса
+// контекст запроса не подходит: он отменяется, если клиент отключился, не дождавшись ответа,
+// и тогда ключ остался бы занятым до истечения срока его хранения
+func storeContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), idempotencyStoreTimeout)
+}
+
+// releaseIdempotencyKey освобождает ключ, чтобы клиент мог повторить запрос
+func releaseIdempotencyKey(idempotencyService IdempotencyService, userID int, key string) {
+	ctx, cancel := storeContext()
+	defer cancel()
+	if err := idempotencyService.ReleaseKey(ctx, userID, key); err != nil {
+		log.Error().Msg(fmt.Sprintf("can not release idempotency key: %v", err.Error()))
+	}
+}
+
+// IdempotencyMiddleware обрабатывает POST запросы с заголовком Idempotency-Key:
+// ответ на запрос сохраняется, и при повторе запроса с тем же ключом возвращается сохраненный ответ
+func IdempotencyMiddleware(authService AuthService, idempotencyService IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > idempotencyKeyMaxLen {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": "Idempotency key is too long"})
+			return
+		}
+
+		user, ok := authService.GetUserFromContext(c.Request.Context())
+		if !ok {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		// читаем тело запроса и подменяем его, чтобы хэндлер смог прочитать его повторно
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		savedResponse, err := idempotencyService.StartRequest(c.Request.Context(), user.ID, key, fingerprint)
+		if errors.Is(err, services.ErrIdempotencyKeyReused) {
+			c.AbortWithStatusJSON(
+				http.StatusUnprocessableEntity,
+				gin.H{"errors": "Idempotency key was already used with other request"},
+			)
+			return
+		}
+		if errors.Is(err, services.ErrIdempotentRequestInProgress) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"errors": "Request with this idempotency key is in progress"})
+			return
+		}
+		if err != nil {
+			log.Error().Msg(fmt.Sprintf("can not start idempotent request: %v", err.Error()))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		// запрос уже выполнялся - возвращаем сохраненный ответ
+		if savedResponse != nil {
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(savedResponse.StatusCode, savedResponse.ContentType, savedResponse.Body)
+			c.Abort()
+			return
+		}
+
+		recordingWriter := &bodyRecordingWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recordingWriter
+		handled := false
+		// если хэндлер завершился паникой, ответ не сохраняется, а ключ освобождается
+		defer func() {
+			if !handled {
+				releaseIdempotencyKey(idempotencyService, user.ID, key)
+			}
+		}()
+		c.Next()
+		handled = true
+
+		// при ошибке сервера ответ не сохраняем, чтобы клиент мог повторить запрос
+		if recordingWriter.Status() >= http.StatusInternalServerError {
+			releaseIdempotencyKey(idempotencyService, user.ID, key)
+			return
+		}
+		response := &domain.IdempotentResponse{
+			StatusCode:  recordingWriter.Status(),
+			ContentType: recordingWriter.Header().Get("Content-Type"),
+			Body:        recordingWriter.body.Bytes(),
+		}
+		ctx, cancel := storeContext()
+		defer cancel()
+		if err := idempotencyService.SaveResponse(ctx, user.ID, key, response); err != nil {
+			log.Error().Msg(fmt.Sprintf("can not save idempotent response: %v", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	mock_middlewares "gophermart/internal/app/middlewares/mocks"
+	"gophermart/internal/app/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	user := &domain.UserDTO{ID: 1}
+	key := "abc"
+	reqBody := `{"order":"2377225624","sum":751}`
+	savedResponse := &domain.IdempotentResponse{
+		StatusCode:  http.StatusPaymentRequired,
+		ContentType: "application/json; charset=utf-8",
+		Body:        []byte(`{"errors":"Not enough points in user's balance"}`),
+	}
+
+	tests := []struct {
+		name              string
+		method            string
+		key               string
+		startRequestRes   *domain.IdempotentResponse
+		startRequestErr   error
+		handlerStatusCode int
+		shouldCallHandler bool
+		shouldSave        bool
+		shouldRelease     bool
+		wantStatusCode    int
+		wantBody          string
+	}{
+		{
+			name:              "no idempotency key",
+			method:            http.MethodPost,
+			handlerStatusCode: http.StatusOK,
+			shouldCallHandler: true,
+			wantStatusCode:    http.StatusOK,
+			wantBody:          "handled",
+		},
+		{
+			name:              "not a POST request",
+			method:            http.MethodGet,
+			key:               key,
+			handlerStatusCode: http.StatusOK,
+			shouldCallHandler: true,
+			wantStatusCode:    http.StatusOK,
+			wantBody:          "handled",
+		},
+		{
+			name:              "first request with key",
+			method:            http.MethodPost,
+			key:               key,
+			handlerStatusCode: http.StatusOK,
+			shouldCallHandler: true,
+			shouldSave:        true,
+			wantStatusCode:    http.StatusOK,
+			wantBody:          "handled",
+		},
+		{
+			name:              "first request failed with server error",
+			method:            http.MethodPost,
+			key:               key,
+			handlerStatusCode: http.StatusInternalServerError,
+			shouldCallHandler: true,
+			shouldRelease:     true,
+			wantStatusCode:    http.StatusInternalServerError,
+			wantBody:          "handled",
+		},
+		{
+			name:            "retry replays saved response",
+			method:          http.MethodPost,
+			key:             key,
+			startRequestRes: savedResponse,
+			wantStatusCode:  savedResponse.StatusCode,
+			wantBody:        string(savedResponse.Body),
+		},
+		{
+			name:            "key reused with other body",
+			method:          http.MethodPost,
+			key:             key,
+			startRequestErr: services.ErrIdempotencyKeyReused,
+			wantStatusCode:  http.StatusUnprocessableEntity,
+			wantBody:        `{"errors":"Idempotency key was already used with other request"}`,
+		},
+		{
+			name:            "request with key is in progress",
+			method:          http.MethodPost,
+			key:             key,
+			startRequestErr: services.ErrIdempotentRequestInProgress,
+			wantStatusCode:  http.StatusConflict,
+			wantBody:        `{"errors":"Request with this idempotency key is in progress"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, "/", bytes.NewReader([]byte(reqBody)))
+			if tt.key != "" {
+				request.Header.Set(IdempotencyKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+
+			// создаем моки
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_middlewares.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(user, true).AnyTimes()
+			idempotencyServiceMock := mock_middlewares.NewMockIdempotencyService(ctrl)
+			if tt.key != "" && tt.method == http.MethodPost {
+				fingerprint := requestFingerprint(tt.method, "/", []byte(reqBody))
+				idempotencyServiceMock.EXPECT().StartRequest(
+					gomock.Any(), user.ID, tt.key, fingerprint,
+				).Return(tt.startRequestRes, tt.startRequestErr)
+			}
+			if tt.shouldSave {
+				idempotencyServiceMock.EXPECT().SaveResponse(gomock.Any(), user.ID, tt.key, &domain.IdempotentResponse{
+					StatusCode:  tt.handlerStatusCode,
+					ContentType: "text/plain; charset=utf-8",
+					Body:        []byte("handled"),
+				}).Return(nil)
+			}
+			if tt.shouldRelease {
+				idempotencyServiceMock.EXPECT().ReleaseKey(gomock.Any(), user.ID, tt.key).Return(nil)
+			}
+
+			handlerCalled := false
+			router := gin.Default()
+			router.Use(IdempotencyMiddleware(authServiceMock, idempotencyServiceMock))
+			handler := func(c *gin.Context) {
+				handlerCalled = true
+				c.String(tt.handlerStatusCode, "handled")
+			}
+			router.POST("/", handler)
+			router.GET("/", handler)
+			router.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.shouldCallHandler, handlerCalled)
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_RequestContextCanceled(t *testing.T) {
+	user := &domain.UserDTO{ID: 1}
+	key := "abc"
+	reqBody := `{"order":"2377225624","sum":751}`
+
+	tests := []struct {
+		name          string
+		handler       func(c *gin.Context, cancel context.CancelFunc)
+		shouldSave    bool
+		shouldRelease bool
+	}{
+		{
+			name: "client disconnected before response was saved",
+			handler: func(c *gin.Context, cancel context.CancelFunc) {
+				cancel()
+				c.String(http.StatusOK, "handled")
+			},
+			shouldSave: true,
+		},
+		{
+			name: "client disconnected before key was released",
+			handler: func(c *gin.Context, cancel context.CancelFunc) {
+				cancel()
+				c.String(http.StatusInternalServerError, "handled")
+			},
+			shouldRelease: true,
+		},
+		{
+			name: "handler panics",
+			handler: func(c *gin.Context, cancel context.CancelFunc) {
+				panic("handler failed")
+			},
+			shouldRelease: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(reqBody))).WithContext(ctx)
+			request.Header.Set(IdempotencyKeyHeader, key)
+			w := httptest.NewRecorder()
+
+			// создаем моки
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			authServiceMock := mock_middlewares.NewMockAuthService(ctrl)
+			authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(user, true)
+			idempotencyServiceMock := mock_middlewares.NewMockIdempotencyService(ctrl)
+			idempotencyServiceMock.EXPECT().StartRequest(gomock.Any(), user.ID, key, gomock.Any()).Return(nil, nil)
+			// результат запроса сохраняется и после отмены контекста запроса
+			if tt.shouldSave {
+				idempotencyServiceMock.EXPECT().SaveResponse(gomock.Any(), user.ID, key, gomock.Any()).
+					Do(func(ctx context.Context, userID int, key string, response *domain.IdempotentResponse) {
+						assert.NoError(t, ctx.Err())
+					}).Return(nil)
+			}
+			if tt.shouldRelease {
+				idempotencyServiceMock.EXPECT().ReleaseKey(gomock.Any(), user.ID, key).
+					Do(func(ctx context.Context, userID int, key string) {
+						assert.NoError(t, ctx.Err())
+					}).Return(nil)
+			}
+
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.Use(IdempotencyMiddleware(authServiceMock, idempotencyServiceMock))
+			router.POST("/", func(c *gin.Context) {
+				tt.handler(c, cancel)
+			})
+			router.ServeHTTP(w, request)
+		})
+	}
+}
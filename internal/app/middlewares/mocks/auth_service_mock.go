@@ -49,6 +49,21 @@ func (mr *MockAuthServiceMockRecorder) AddUserToContext(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserToContext", reflect.TypeOf((*MockAuthService)(nil).AddUserToContext), arg0, arg1)
 }
 
+// GetUserFromContext mocks base method.
+func (m *MockAuthService) GetUserFromContext(arg0 context.Context) (*domain.UserDTO, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFromContext", arg0)
+	ret0, _ := ret[0].(*domain.UserDTO)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetUserFromContext indicates an expected call of GetUserFromContext.
+func (mr *MockAuthServiceMockRecorder) GetUserFromContext(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFromContext", reflect.TypeOf((*MockAuthService)(nil).GetUserFromContext), arg0)
+}
+
 // ParseUserToken mocks base method.
 func (m *MockAuthService) ParseUserToken(arg0 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateWindow количество запросов, выполненных начиная с момента start
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimiter ограничивает количество запросов по ключу: не больше limit запросов за window
+// счетчики хранятся в памяти процесса, limit равный 0 снимает ограничение
+type RateLimiter struct {
+	limit       int
+	window      time.Duration
+	now         func() time.Time
+	mu          sync.Mutex
+	windows     map[string]*rateWindow
+	lastCleanup time.Time
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, now: time.Now, windows: make(map[string]*rateWindow)}
+}
+
+// Allow учитывает запрос с ключом key и проверяет, не превышено ли ограничение
+// если ограничение превышено, возвращает время, через которое можно повторить запрос
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	// удаляем истекшие счетчики, чтобы они не накапливались в памяти
+	if now.Sub(l.lastCleanup) >= l.window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// RateLimitMiddleware ограничивает частоту запросов каждого пользователя и каждого IP адреса
+// при превышении ограничения возвращает 429 с заголовком Retry-After
+// должен использоваться после TokenAuthMiddleware
+func RateLimitMiddleware(authService AuthService, limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := authService.GetUserFromContext(c.Request.Context())
+		if !ok {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		for _, key := range []string{fmt.Sprintf("user:%d", user.ID), "ip:" + c.ClientIP()} {
+			allowed, retryAfter := limiter.Allow(key)
+			if !allowed {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"errors": "Too many requests"})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,90 @@
+package middlewares
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	mock_middlewares "gophermart/internal/app/middlewares/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		allowed, _ := limiter.Allow("user:1")
+		assert.True(t, allowed)
+	}
+	now = now.Add(20 * time.Second)
+	allowed, retryAfter := limiter.Allow("user:1")
+	assert.False(t, allowed)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	// ограничение считается отдельно для каждого ключа
+	allowed, _ = limiter.Allow("user:2")
+	assert.True(t, allowed)
+
+	// после окончания периода запросы снова разрешены
+	now = now.Add(40 * time.Second)
+	allowed, _ = limiter.Allow("user:1")
+	assert.True(t, allowed)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	authServiceMock := mock_middlewares.NewMockAuthService(ctrl)
+	authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(&domain.UserDTO{ID: 1}, true).Times(3)
+
+	router := gin.Default()
+	router.Use(RateLimitMiddleware(authServiceMock, NewRateLimiter(2, time.Minute)))
+	router.POST("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	wantStatusCodes := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for _, wantStatusCode := range wantStatusCodes {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+		assert.Equal(t, wantStatusCode, w.Code)
+		if wantStatusCode == http.StatusTooManyRequests {
+			assert.Equal(t, "60", w.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestRateLimitMiddleware_IgnoresForwardedForFromUntrustedProxy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	authServiceMock := mock_middlewares.NewMockAuthService(ctrl)
+	gomock.InOrder(
+		authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(&domain.UserDTO{ID: 1}, true),
+		authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(&domain.UserDTO{ID: 2}, true),
+		authServiceMock.EXPECT().GetUserFromContext(gomock.Any()).Return(&domain.UserDTO{ID: 3}, true),
+	)
+
+	router := gin.Default()
+	assert.NoError(t, router.SetTrustedProxies(nil))
+	router.Use(RateLimitMiddleware(authServiceMock, NewRateLimiter(2, time.Minute)))
+	router.POST("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// разные пользователи с одного адреса подменяют X-Forwarded-For, но ограничение по IP все равно срабатывает
+	wantStatusCodes := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i, wantStatusCode := range wantStatusCodes {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i+1))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, wantStatusCode, w.Code)
+	}
+}
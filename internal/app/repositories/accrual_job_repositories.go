@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+const (
+	accrualJobEntityType = "accrual_job"
+	accrualJobColumns    = `id, order_number, stage, attempts, polls, next_attempt_at, locked_until,
+		COALESCE(locked_by, '') AS locked_by, last_error, dead_lettered_at, created_at`
+)
+
+type AccrualJobRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccrualJobRepository(db *sqlx.DB) *AccrualJobRepository {
+	return &AccrualJobRepository{db: db}
+}
+
+// insertAccrualJob ставит заказ в очередь на обработку в рамках транзакции tx,
+// чтобы заказ и задание на его обработку сохранялись вместе
+func insertAccrualJob(ctx context.Context, tx *sql.Tx, orderNumber string, now time.Time) error {
+	query := `INSERT INTO accrual_job (order_number, stage, next_attempt_at, created_at) VALUES ($1, $2, $3, $3)`
+	_, err := tx.ExecContext(ctx, query, orderNumber, domain.AccrualJobRegisterStage, now)
+	return err
+}
+
+// ClaimAccrualJobs захватывает до limit заданий, время попытки которых наступило к моменту now,
+// и не выдает их другим воркерам до lockedUntil, отложенные после ошибок задания не захватываются
+// задания, которые в этот момент захватывает другой воркер, пропускаются, а не ожидаются
+// изменить захваченные задания можно только с токеном захвата owner
+func (r *AccrualJobRepository) ClaimAccrualJobs(
+	ctx context.Context, owner string, now time.Time, lockedUntil time.Time, limit int,
+) ([]*domain.AccrualJob, error) {
+	query := `UPDATE accrual_job SET locked_until = $2, locked_by = $4
+		WHERE id IN (
+			SELECT id FROM accrual_job
+			WHERE next_attempt_at <= $1 AND (locked_until IS NULL OR locked_until <= $1) AND dead_lettered_at IS NULL
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + accrualJobColumns + `
+	`
+
+	var jobs []*domain.AccrualJob
+	if err := r.db.SelectContext(ctx, &jobs, query, now, lockedUntil, limit, owner); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// RescheduleAccrualJob сохраняет этап, счетчики попыток, последнюю ошибку и время следующей попытки задания
+// и снимает с него захват воркером
+// если задание уже захвачено другим воркером, то оно не меняется, и возвращается ErrAccrualJobLeaseLost
+func (r *AccrualJobRepository) RescheduleAccrualJob(ctx context.Context, job *domain.AccrualJob) error {
+	query := `UPDATE accrual_job
+		SET stage = $1, attempts = $2, polls = $3, next_attempt_at = $4, last_error = $5, dead_lettered_at = $6,
+			locked_until = NULL, locked_by = NULL
+		WHERE id = $7 AND locked_by = $8
+	`
+	res, err := r.db.ExecContext(
+		ctx, query, job.Stage, job.Attempts, job.Polls, job.NextAttemptAt, job.LastError, job.DeadLetteredAt,
+		job.ID, job.LockedBy,
+	)
+	if err := checkAccrualJobLease(res, err); err != nil {
+		return err
+	}
+	job.LockedUntil = nil
+	job.LockedBy = ""
+	return nil
+}
+
+// DeleteAccrualJob удаляет задание после того, как заказ получил окончательный статус
+func (r *AccrualJobRepository) DeleteAccrualJob(ctx context.Context, job *domain.AccrualJob) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM accrual_job WHERE id = $1 AND locked_by = $2`, job.ID, job.LockedBy)
+	return checkAccrualJobLease(res, err)
+}
+
+// GetDeadLetteredAccrualJobs возвращает задания, отложенные после слишком большого количества ошибок,
+// начиная с последних
+func (r *AccrualJobRepository) GetDeadLetteredAccrualJobs(
+	ctx context.Context, limit int, offset int,
+) ([]*domain.AccrualJob, error) {
+	query := `SELECT ` + accrualJobColumns + ` FROM accrual_job
+		WHERE dead_lettered_at IS NOT NULL
+		ORDER BY dead_lettered_at DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	var jobs []*domain.AccrualJob
+	if err := r.db.SelectContext(ctx, &jobs, query, limit, offset); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// RequeueAccrualJob возвращает отложенное задание в очередь со сброшенными счетчиками попыток
+// и сохраняет действие администратора adminID в журнале аудита
+func (r *AccrualJobRepository) RequeueAccrualJob(
+	ctx context.Context, jobID int64, adminID int, now time.Time,
+) (*domain.AccrualJob, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var job domain.AccrualJob
+	query := `UPDATE accrual_job
+		SET attempts = 0, polls = 0, next_attempt_at = $2, last_error = '', dead_lettered_at = NULL,
+			locked_until = NULL, locked_by = NULL
+		WHERE id = $1 AND dead_lettered_at IS NOT NULL
+		RETURNING ` + accrualJobColumns + `
+	`
+	err = tx.QueryRowContext(ctx, query, jobID, now).Scan(
+		&job.ID, &job.OrderNumber, &job.Stage, &job.Attempts, &job.Polls, &job.NextAttemptAt, &job.LockedUntil,
+		&job.LockedBy, &job.LastError, &job.DeadLetteredAt, &job.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		query := `SELECT EXISTS(SELECT 1 FROM accrual_job WHERE id = $1)`
+		if err := tx.QueryRowContext(ctx, query, jobID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrAccrualJobIsNotDeadLettered
+		}
+		return nil, ErrAccrualJobDoesNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertAuditLogEntry(ctx, tx, &domain.AuditLogEntry{
+		ActorID:    adminID,
+		Action:     domain.AuditActionAccrualJobRequeued,
+		EntityType: accrualJobEntityType,
+		EntityID:   int(job.ID),
+		Details:    fmt.Sprintf("order %s", job.OrderNumber),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, tx.Commit()
+}
+
+// checkAccrualJobLease возвращает ErrAccrualJobLeaseLost, если запрос не изменил задание из-за чужого захвата
+func checkAccrualJobLease(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAccrualJobLeaseLost
+	}
+	return nil
+}
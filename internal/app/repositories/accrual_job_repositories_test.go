@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"testing"
+	"time"
+)
+
+func TestAccrualJobRepository_ClaimAndReschedule(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, 0)
+	orderRepository := NewOrderRepository(db)
+	jobRepository := NewAccrualJobRepository(db)
+
+	// задание создается вместе с заказом
+	now := time.Now()
+	orderNumber := fmt.Sprintf("%d", now.UnixNano())
+	_, created, err := orderRepository.GetOrCreateOrder(ctx, domain.OrderDTO{Number: orderNumber, UploadedAt: now, UserID: userID})
+	require.NoError(t, err)
+	require.True(t, created)
+	_, created, err = orderRepository.GetOrCreateOrder(ctx, domain.OrderDTO{Number: orderNumber, UploadedAt: now, UserID: userID})
+	require.NoError(t, err)
+	require.False(t, created)
+
+	claim := func(owner string, at time.Time) *domain.AccrualJob {
+		jobs, err := jobRepository.ClaimAccrualJobs(ctx, owner, at, at.Add(time.Minute), 1000)
+		require.NoError(t, err)
+		for _, job := range jobs {
+			if job.OrderNumber == orderNumber {
+				return job
+			}
+		}
+		return nil
+	}
+
+	job := claim("first", now)
+	require.NotNil(t, job)
+	assert.Equal(t, domain.AccrualJobRegisterStage, job.Stage)
+	assert.Equal(t, "first", job.LockedBy)
+	// захваченное задание не выдается другому воркеру до окончания захвата
+	assert.Nil(t, claim("second", now))
+
+	job.Stage = domain.AccrualJobPollStage
+	job.Attempts = 1
+	job.Polls = 2
+	job.NextAttemptAt = now.Add(time.Second)
+	require.NoError(t, jobRepository.RescheduleAccrualJob(ctx, job))
+	assert.Nil(t, claim("second", now))
+
+	job = claim("second", now.Add(time.Second))
+	require.NotNil(t, job)
+	assert.Equal(t, domain.AccrualJobPollStage, job.Stage)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, 2, job.Polls)
+
+	// задание, захват которого истек, достается другому воркеру, а прежний воркер не может его изменить
+	reclaimed := claim("third", now.Add(2*time.Minute))
+	require.NotNil(t, reclaimed)
+	assert.ErrorIs(t, jobRepository.RescheduleAccrualJob(ctx, job), ErrAccrualJobLeaseLost)
+	assert.ErrorIs(t, jobRepository.DeleteAccrualJob(ctx, job), ErrAccrualJobLeaseLost)
+
+	require.NoError(t, jobRepository.DeleteAccrualJob(ctx, reclaimed))
+	assert.Nil(t, claim("fourth", now.Add(time.Hour)))
+}
+
+func TestAccrualJobRepository_DeadLetterAndRequeue(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, 0)
+	adminID := createTestUser(t, db, 0)
+	orderRepository := NewOrderRepository(db)
+	jobRepository := NewAccrualJobRepository(db)
+
+	now := time.Now()
+	orderNumber := fmt.Sprintf("%d", now.UnixNano())
+	_, _, err := orderRepository.GetOrCreateOrder(ctx, domain.OrderDTO{Number: orderNumber, UploadedAt: now, UserID: userID})
+	require.NoError(t, err)
+
+	jobs, err := jobRepository.ClaimAccrualJobs(ctx, "owner", now, now.Add(time.Minute), 1000)
+	require.NoError(t, err)
+	var job *domain.AccrualJob
+	for _, j := range jobs {
+		if j.OrderNumber == orderNumber {
+			job = j
+		} else {
+			// задания других тестов возвращаются в очередь
+			require.NoError(t, jobRepository.RescheduleAccrualJob(ctx, j))
+		}
+	}
+	require.NotNil(t, job)
+
+	// еще не отложенное задание нельзя вернуть в очередь
+	_, err = jobRepository.RequeueAccrualJob(ctx, job.ID, adminID, now)
+	assert.ErrorIs(t, err, ErrAccrualJobIsNotDeadLettered)
+	_, err = jobRepository.RequeueAccrualJob(ctx, -1, adminID, now)
+	assert.ErrorIs(t, err, ErrAccrualJobDoesNotExist)
+
+	job.Attempts = 10
+	job.LastError = "bad gateway"
+	job.DeadLetteredAt = &now
+	require.NoError(t, jobRepository.RescheduleAccrualJob(ctx, job))
+
+	// отложенное задание не захватывается воркерами и попадает в список отложенных
+	jobs, err = jobRepository.ClaimAccrualJobs(ctx, "owner", now.Add(time.Hour), now.Add(2*time.Hour), 1000)
+	require.NoError(t, err)
+	for _, j := range jobs {
+		assert.NotEqual(t, orderNumber, j.OrderNumber)
+		require.NoError(t, jobRepository.RescheduleAccrualJob(ctx, j))
+	}
+	deadLettered, err := jobRepository.GetDeadLetteredAccrualJobs(ctx, 1000, 0)
+	require.NoError(t, err)
+	var found bool
+	for _, j := range deadLettered {
+		if j.ID == job.ID {
+			found = true
+			assert.Equal(t, "bad gateway", j.LastError)
+		}
+	}
+	assert.True(t, found)
+
+	requeued, err := jobRepository.RequeueAccrualJob(ctx, job.ID, adminID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, requeued.Attempts)
+	assert.Equal(t, "", requeued.LastError)
+	assert.Nil(t, requeued.DeadLetteredAt)
+
+	entries, err := NewAuditLogRepository(db).GetAuditLog(ctx, 10, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	assert.Equal(t, domain.AuditActionAccrualJobRequeued, entries[0].Action)
+}
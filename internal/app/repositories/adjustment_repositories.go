@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+const adjustmentColumns = `id, user_id, amount, reason, status, requested_by, reviewed_by, created_at, reviewed_at`
+
+// adjustmentEntityType тип сущности корректировки в журнале аудита
+const adjustmentEntityType = "balance_adjustment"
+
+type AdjustmentRepository struct {
+	db               *sqlx.DB
+	ledgerRepository *LedgerRepository
+}
+
+func NewAdjustmentRepository(db *sqlx.DB, ledgerRepository *LedgerRepository) *AdjustmentRepository {
+	return &AdjustmentRepository{db: db, ledgerRepository: ledgerRepository}
+}
+
+// CreateAdjustment сохраняет корректировку баланса
+// корректировка сразу применяется, если сумма по модулю корректировок этого администратора для этого пользователя
+// за последние сутки вместе с новой не превышает approvalThreshold, иначе ожидает подтверждения вторым администратором
+// так крупную корректировку нельзя провести без подтверждения, разбив ее на несколько мелких
+func (r *AdjustmentRepository) CreateAdjustment(
+	ctx context.Context, adjustment *domain.BalanceAdjustment, approvalThreshold domain.Money,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// блокируем пользователя, чтобы параллельные корректировки учитывали друг друга в сумме за сутки
+	var userID int
+	query := `SELECT id FROM auth_user WHERE id = $1 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, adjustment.UserID).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUserDoesNotExist
+	}
+	if err != nil {
+		return err
+	}
+
+	adjustment.CreatedAt = time.Now()
+	var adjustedSum domain.Money
+	query = `SELECT COALESCE(SUM(ABS(amount)), 0) FROM balance_adjustment
+		WHERE requested_by = $1 AND user_id = $2 AND status <> $3 AND created_at >= $4
+	`
+	err = tx.QueryRowContext(
+		ctx, query, adjustment.RequestedBy, adjustment.UserID, domain.AdjustmentRejectedStatus,
+		adjustment.CreatedAt.Add(-24*time.Hour),
+	).Scan(&adjustedSum)
+	if err != nil {
+		return err
+	}
+	amount := adjustment.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	apply := adjustedSum+amount <= approvalThreshold
+
+	adjustment.Status = domain.AdjustmentPendingStatus
+	query = `INSERT INTO balance_adjustment (user_id, amount, reason, status, requested_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+	`
+	err = tx.QueryRowContext(
+		ctx, query, adjustment.UserID, adjustment.Amount, adjustment.Reason, adjustment.Status,
+		adjustment.RequestedBy, adjustment.CreatedAt,
+	).Scan(&adjustment.ID)
+	if err != nil {
+		return err
+	}
+
+	err = insertAuditLogEntry(ctx, tx, &domain.AuditLogEntry{
+		ActorID:    adjustment.RequestedBy,
+		Action:     domain.AuditActionAdjustmentRequested,
+		EntityType: adjustmentEntityType,
+		EntityID:   adjustment.ID,
+		Details:    fmt.Sprintf("user %d, amount %v: %s", adjustment.UserID, adjustment.Amount, adjustment.Reason),
+	})
+	if err != nil {
+		return err
+	}
+
+	if apply {
+		if err := r.applyAdjustment(ctx, tx, adjustment, adjustment.RequestedBy); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ApproveAdjustment подтверждает и применяет корректировку, ожидающую подтверждения
+// корректировку не может подтвердить администратор, который ее создал
+func (r *AdjustmentRepository) ApproveAdjustment(
+	ctx context.Context, adjustmentID int, adminID int,
+) (*domain.BalanceAdjustment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	adjustment, err := lockPendingAdjustment(ctx, tx, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.RequestedBy == adminID {
+		return nil, ErrAdjustmentSelfApproval
+	}
+	if err := r.applyAdjustment(ctx, tx, adjustment, adminID); err != nil {
+		return nil, err
+	}
+
+	return adjustment, tx.Commit()
+}
+
+// RejectAdjustment отклоняет корректировку, ожидающую подтверждения
+func (r *AdjustmentRepository) RejectAdjustment(
+	ctx context.Context, adjustmentID int, adminID int,
+) (*domain.BalanceAdjustment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	adjustment, err := lockPendingAdjustment(ctx, tx, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+	err = reviewAdjustment(ctx, tx, adjustment, domain.AdjustmentRejectedStatus, adminID)
+	if err != nil {
+		return nil, err
+	}
+	err = insertAuditLogEntry(ctx, tx, &domain.AuditLogEntry{
+		ActorID:    adminID,
+		Action:     domain.AuditActionAdjustmentRejected,
+		EntityType: adjustmentEntityType,
+		EntityID:   adjustment.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, tx.Commit()
+}
+
+// GetUserAdjustments возвращает корректировки баланса пользователя, начиная с последних
+func (r *AdjustmentRepository) GetUserAdjustments(ctx context.Context, userID int) ([]*domain.BalanceAdjustment, error) {
+	query := `SELECT ` + adjustmentColumns + ` FROM balance_adjustment
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	var adjustments []*domain.BalanceAdjustment
+	if err := r.db.SelectContext(ctx, &adjustments, query, userID); err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+// lockPendingAdjustment блокирует корректировку, чтобы ее нельзя было рассмотреть дважды
+func lockPendingAdjustment(ctx context.Context, tx *sql.Tx, adjustmentID int) (*domain.BalanceAdjustment, error) {
+	var adjustment domain.BalanceAdjustment
+	query := `SELECT id, user_id, amount, reason, status, requested_by, created_at
+		FROM balance_adjustment WHERE id = $1 FOR UPDATE
+	`
+	err := tx.QueryRowContext(ctx, query, adjustmentID).Scan(
+		&adjustment.ID, &adjustment.UserID, &adjustment.Amount, &adjustment.Reason, &adjustment.Status,
+		&adjustment.RequestedBy, &adjustment.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAdjustmentDoesNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.Status != domain.AdjustmentPendingStatus {
+		return nil, ErrAdjustmentIsNotPending
+	}
+	return &adjustment, nil
+}
+
+// reviewAdjustment сохраняет решение администратора adminID по корректировке
+func reviewAdjustment(
+	ctx context.Context, tx *sql.Tx, adjustment *domain.BalanceAdjustment, status string, adminID int,
+) error {
+	now := time.Now()
+	adjustment.Status = status
+	adjustment.ReviewedBy = &adminID
+	adjustment.ReviewedAt = &now
+	query := `UPDATE balance_adjustment SET status = $1, reviewed_by = $2, reviewed_at = $3 WHERE id = $4`
+	_, err := tx.ExecContext(ctx, query, adjustment.Status, adjustment.ReviewedBy, adjustment.ReviewedAt, adjustment.ID)
+	return err
+}
+
+// applyAdjustment проводит корректировку по журналу операций
+// списание, превышающее доступный баланс, завершается ошибкой ErrCanNotWithdrawBalance
+func (r *AdjustmentRepository) applyAdjustment(
+	ctx context.Context, tx *sql.Tx, adjustment *domain.BalanceAdjustment, adminID int,
+) error {
+	err := r.ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationAdjustment,
+		Reference: adjustment.Reason,
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountAdjustments, Amount: -adjustment.Amount},
+			{Account: domain.LedgerAccountCurrent, UserID: adjustment.UserID, Amount: adjustment.Amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := reviewAdjustment(ctx, tx, adjustment, domain.AdjustmentAppliedStatus, adminID); err != nil {
+		return err
+	}
+	return insertAuditLogEntry(ctx, tx, &domain.AuditLogEntry{
+		ActorID:    adminID,
+		Action:     domain.AuditActionAdjustmentApplied,
+		EntityType: adjustmentEntityType,
+		EntityID:   adjustment.ID,
+		Details:    fmt.Sprintf("user %d, amount %v", adjustment.UserID, adjustment.Amount),
+	})
+}
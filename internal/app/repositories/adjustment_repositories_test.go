@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"testing"
+)
+
+func TestAdjustmentRepository_FourEyesApproval(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	ledgerRepository := NewLedgerRepository(db, 0)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+	adjustmentRepository := NewAdjustmentRepository(db, ledgerRepository)
+	threshold := domain.NewMoney(1000, 0)
+
+	userID := createTestUser(t, db, domain.NewMoney(100, 0))
+	firstAdminID := createTestUser(t, db, 0)
+	secondAdminID := createTestUser(t, db, 0)
+
+	// списание больше баланса не применяется
+	err := adjustmentRepository.CreateAdjustment(ctx, &domain.BalanceAdjustment{
+		UserID: userID, Amount: -domain.NewMoney(150, 0), Reason: "too much", RequestedBy: firstAdminID,
+	}, threshold)
+	assert.ErrorIs(t, err, ErrCanNotWithdrawBalance)
+
+	applied := &domain.BalanceAdjustment{
+		UserID: userID, Amount: -domain.NewMoney(30, 0), Reason: "duplicate accrual", RequestedBy: firstAdminID,
+	}
+	require.NoError(t, adjustmentRepository.CreateAdjustment(ctx, applied, threshold))
+	assert.Equal(t, domain.AdjustmentAppliedStatus, applied.Status)
+
+	pending := &domain.BalanceAdjustment{
+		UserID: userID, Amount: domain.NewMoney(5000, 0), Reason: "compensation", RequestedBy: firstAdminID,
+	}
+	require.NoError(t, adjustmentRepository.CreateAdjustment(ctx, pending, threshold))
+	assert.Equal(t, domain.AdjustmentPendingStatus, pending.Status)
+
+	_, err = adjustmentRepository.ApproveAdjustment(ctx, pending.ID, firstAdminID)
+	assert.ErrorIs(t, err, ErrAdjustmentSelfApproval)
+	approved, err := adjustmentRepository.ApproveAdjustment(ctx, pending.ID, secondAdminID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AdjustmentAppliedStatus, approved.Status)
+	_, err = adjustmentRepository.RejectAdjustment(ctx, pending.ID, secondAdminID)
+	assert.ErrorIs(t, err, ErrAdjustmentIsNotPending)
+
+	balance, err := balanceRepository.GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(5070, 0), balance.Current)
+
+	// корректировки попадают в выписку пользователя с причиной в описании
+	lines, err := balanceRepository.GetStatement(ctx, userID, domain.StatementFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	assert.Equal(t, "duplicate accrual", lines[1].Reference)
+	assert.Equal(t, "compensation", lines[2].Reference)
+}
+
+func TestAdjustmentRepository_CreateAdjustment_RunningTotal(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	adjustmentRepository := NewAdjustmentRepository(db, NewLedgerRepository(db, 0))
+	threshold := domain.NewMoney(1000, 0)
+	userID := createTestUser(t, db, 0)
+	adminID := createTestUser(t, db, 0)
+	secondAdminID := createTestUser(t, db, 0)
+
+	newAdjustment := func(adminID int, amount domain.Money) *domain.BalanceAdjustment {
+		adjustment := &domain.BalanceAdjustment{UserID: userID, Amount: amount, Reason: "support ticket", RequestedBy: adminID}
+		require.NoError(t, adjustmentRepository.CreateAdjustment(ctx, adjustment, threshold))
+		return adjustment
+	}
+
+	// корректировки одного администратора за сутки складываются по модулю
+	assert.Equal(t, domain.AdjustmentAppliedStatus, newAdjustment(adminID, domain.NewMoney(600, 0)).Status)
+	pending := newAdjustment(adminID, -domain.NewMoney(500, 0))
+	assert.Equal(t, domain.AdjustmentPendingStatus, pending.Status)
+
+	// отклоненная корректировка в сумме не учитывается
+	_, err := adjustmentRepository.RejectAdjustment(ctx, pending.ID, secondAdminID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AdjustmentAppliedStatus, newAdjustment(adminID, -domain.NewMoney(400, 0)).Status)
+
+	// корректировки другого администратора считаются отдельно
+	assert.Equal(t, domain.AdjustmentAppliedStatus, newAdjustment(secondAdminID, domain.NewMoney(1000, 0)).Status)
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+type AuditLogRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditLogRepository(db *sqlx.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// insertAuditLogEntry сохраняет запись журнала аудита в рамках транзакции tx,
+// чтобы действие и запись о нем сохранялись вместе
+func insertAuditLogEntry(ctx context.Context, tx *sql.Tx, entry *domain.AuditLogEntry) error {
+	entry.CreatedAt = time.Now()
+	query := `INSERT INTO audit_log (actor_id, action, entity_type, entity_id, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+	`
+	return tx.QueryRowContext(
+		ctx, query, entry.ActorID, entry.Action, entry.EntityType, entry.EntityID, entry.Details, entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+// GetAuditLog возвращает записи журнала аудита, начиная с последних
+func (r *AuditLogRepository) GetAuditLog(ctx context.Context, limit int, offset int) ([]*domain.AuditLogEntry, error) {
+	query := `SELECT id, actor_id, action, entity_type, entity_id, details, created_at
+		FROM audit_log
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	var entries []*domain.AuditLogEntry
+	if err := r.db.SelectContext(ctx, &entries, query, limit, offset); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx"
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
 	"gophermart/internal/app/domain"
@@ -12,53 +14,154 @@ import (
 )
 
 type BalanceRepository struct {
-	db *sqlx.DB
+	db               *sqlx.DB
+	ledgerRepository *LedgerRepository
 }
 
-func NewBalanceRepository(db *sqlx.DB) *BalanceRepository {
-	return &BalanceRepository{db: db}
+func NewBalanceRepository(db *sqlx.DB, ledgerRepository *LedgerRepository) *BalanceRepository {
+	return &BalanceRepository{db: db, ledgerRepository: ledgerRepository}
 }
 
-func (r *BalanceRepository) WithdrawBalanceForOrder(ctx context.Context, withdrawal *domain.Withdrawal) error {
+// WithdrawBalanceForOrder списывает баллы в счет заказа, если списание не превышает ограничений caps
+func (r *BalanceRepository) WithdrawBalanceForOrder(
+	ctx context.Context, withdrawal *domain.Withdrawal, caps []domain.WithdrawalCap,
+) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// пытаемся вычесть сумму заказа из баланса пользователя
-	// для этого сначала проверяем значение баланса
-	var balance float32
-	query := `SELECT current FROM user_balance where user_id=$1`
-	if err := r.db.QueryRowContext(ctx, query, withdrawal.UserID).Scan(&balance); err != nil {
+	if err := checkOrderOwner(ctx, tx, withdrawal.Order, withdrawal.UserID); err != nil {
+		return err
+	}
+	// заказ с активным резервом оплачивается подтверждением резерва, иначе баллы за него были бы списаны дважды
+	// номер заказа заблокирован, поэтому резерв не может появиться до конца транзакции
+	var holdExists bool
+	query := `SELECT EXISTS (SELECT 1 FROM balance_hold WHERE order_number=$1 AND status=$2)`
+	if err := tx.QueryRowContext(ctx, query, withdrawal.Order, domain.HoldActiveStatus).Scan(&holdExists); err != nil {
+		return err
+	}
+	if holdExists {
+		return ErrOrderAlreadyExists
+	}
+	if err := checkWithdrawalCaps(ctx, tx, withdrawal.UserID, withdrawal.Sum, caps); err != nil {
+		return err
+	}
+	if err := insertWithdrawal(ctx, tx, withdrawal); err != nil {
 		return err
 	}
 	wSum := withdrawal.Sum
-	if balance < wSum {
-		return ErrCanNotWithdrawBalance
+
+	// переводим сумму заказа со счета доступных баллов пользователя на счет списанных
+	// журнал не допускает отрицательного баланса, поэтому параллельные списания не могут увести баланс в минус
+	transaction := &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationWithdrawal,
+		Reference: withdrawal.Order,
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountCurrent, UserID: withdrawal.UserID, Amount: -wSum},
+			{Account: domain.LedgerAccountWithdrawn, UserID: withdrawal.UserID, Amount: wSum},
+		},
+	}
+	if err := r.ledgerRepository.PostTransaction(ctx, tx, transaction); err != nil {
+		return err
+	}
+	// запоминаем операцию, израсходовавшую партии баллов, чтобы при возврате списания вернуть их прежние сроки сгорания
+	withdrawal.LotsTransactionID = transaction.ID
+	query = `UPDATE withdrawal SET lots_transaction_id = $1 WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, withdrawal.LotsTransactionID, withdrawal.ID); err != nil {
+		return err
 	}
 
-	query = `UPDATE user_balance SET current=current-$1, withdrawn=withdrawn+$1 WHERE user_id=$2`
-	_, err = r.db.ExecContext(ctx, query, &wSum, &withdrawal.UserID)
-	if err != nil {
+	return tx.Commit()
+}
+
+// lockOrderNumber блокирует номер заказа до конца транзакции tx
+// загрузка заказа и списание баллов по одному номеру выполняются по очереди: без блокировки проверка владельца
+// не увидела бы заказ, который другой пользователь загружает параллельно, - незафиксированную вставку
+// не видит даже SELECT ... FOR SHARE
+func lockOrderNumber(ctx context.Context, tx *sql.Tx, orderNumber string) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, orderNumber)
+	return err
+}
+
+// checkOrderOwner проверяет, что номер заказа не принадлежит другому пользователю
+// номер заказа, загруженного другим пользователем, нельзя использовать для списания
+// номер заказа остается заблокированным до конца транзакции tx
+func checkOrderOwner(ctx context.Context, tx *sql.Tx, orderNumber string, userID int) error {
+	if err := lockOrderNumber(ctx, tx, orderNumber); err != nil {
 		return err
 	}
 
-	// записываем в историю withdrawal
-	query = `INSERT INTO withdrawal (processed_at, sum, order_number, user_id) VALUES ($1, $2, $3, $4)`
-	_, err = r.db.ExecContext(ctx, query, time.Now(), &wSum, &withdrawal.Order, &withdrawal.UserID)
+	var orderUserID int
+	query := `SELECT user_id FROM user_order WHERE number=$1 FOR SHARE`
+	err := tx.QueryRowContext(ctx, query, orderNumber).Scan(&orderUserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil && orderUserID != userID {
+		return ErrOrderAlreadyExists
+	}
+	return nil
+}
+
+// checkWithdrawalCaps проверяет, что списание суммы sum не превышает ограничений caps
+// баланс пользователя блокируется до конца транзакции tx, чтобы параллельные списания и резервы
+// не могли вместе превысить ограничения
+func checkWithdrawalCaps(ctx context.Context, tx *sql.Tx, userID int, sum domain.Money, caps []domain.WithdrawalCap) error {
+	if len(caps) == 0 {
+		return nil
+	}
+
+	query := `SELECT user_id FROM user_balance WHERE user_id = $1 FOR UPDATE`
+	rows, err := tx.QueryContext(ctx, query, userID)
 	if err != nil {
 		return err
 	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
 
-	return tx.Commit()
+	for _, c := range caps {
+		withdrawnSum, err := getWithdrawnSumSince(ctx, tx, userID, c.Since)
+		if err != nil {
+			return err
+		}
+		if withdrawnSum+sum > c.Limit {
+			return c.Err
+		}
+	}
+	return nil
+}
+
+// insertWithdrawal записывает списание в историю withdrawal
+// номер заказа уникален, поэтому один заказ нельзя оплатить баллами дважды
+func insertWithdrawal(ctx context.Context, tx *sql.Tx, withdrawal *domain.Withdrawal) error {
+	withdrawal.ProcessedAt = time.Now()
+	query := `INSERT INTO withdrawal (processed_at, sum, order_number, user_id, lots_transaction_id)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id
+	`
+	lotsTransactionID := sql.NullInt64{Int64: withdrawal.LotsTransactionID, Valid: withdrawal.LotsTransactionID != 0}
+	err := tx.QueryRowContext(
+		ctx, query, withdrawal.ProcessedAt, withdrawal.Sum, withdrawal.Order, withdrawal.UserID, lotsTransactionID,
+	).Scan(&withdrawal.ID)
+	var pgErr pgx.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrOrderAlreadyExists
+		}
+	}
+	return err
 }
 
 func (r *BalanceRepository) GetBalanceWithdrawals(ctx context.Context, userID int) ([]*domain.Withdrawal, error) {
-	query := `SELECT *
-		FROM withdrawal
-		WHERE user_id = $1
-  		ORDER BY processed_at
+	query := `SELECT w.id, w.order_number, w.sum, w.processed_at, w.user_id, COALESCE(r.reversed_sum, 0) AS reversed_sum
+		FROM withdrawal w
+		LEFT JOIN (
+			SELECT withdrawal_id, SUM(sum) AS reversed_sum FROM withdrawal_reversal GROUP BY withdrawal_id
+		) r ON r.withdrawal_id = w.id
+		WHERE w.user_id = $1
+  		ORDER BY w.processed_at
 	`
 
 	rows, err := r.db.QueryxContext(ctx, query, &userID)
@@ -76,6 +179,7 @@ func (r *BalanceRepository) GetBalanceWithdrawals(ctx context.Context, userID in
 		if err != nil {
 			return nil, err
 		}
+		withdrawal.Status = domain.WithdrawalStatus(withdrawal.Sum, withdrawal.ReversedSum)
 		withdrawals = append(withdrawals, &withdrawal)
 	}
 
@@ -87,8 +191,72 @@ func (r *BalanceRepository) GetBalanceWithdrawals(ctx context.Context, userID in
 	return withdrawals, nil
 }
 
+// ReverseWithdrawal возвращает пользователю баллы, списанные в счет заказа, полностью или частично
+// если сумма возврата не задана, то возвращается весь остаток списания
+func (r *BalanceRepository) ReverseWithdrawal(ctx context.Context, reversal *domain.WithdrawalReversal) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// блокируем списание, чтобы параллельные возвраты не превысили его сумму
+	var withdrawal domain.Withdrawal
+	query := `SELECT id, order_number, sum, user_id, COALESCE(lots_transaction_id, 0) FROM withdrawal WHERE id=$1 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, reversal.WithdrawalID).Scan(
+		&withdrawal.ID, &withdrawal.Order, &withdrawal.Sum, &withdrawal.UserID, &withdrawal.LotsTransactionID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWithdrawalDoesNotExist
+	}
+	if err != nil {
+		return err
+	}
+
+	var reversedSum domain.Money
+	query = `SELECT COALESCE(SUM(sum), 0) FROM withdrawal_reversal WHERE withdrawal_id=$1`
+	if err := tx.QueryRowContext(ctx, query, withdrawal.ID).Scan(&reversedSum); err != nil {
+		return err
+	}
+	remainingSum := withdrawal.Sum - reversedSum
+	if reversal.Sum == 0 {
+		reversal.Sum = remainingSum
+	}
+	if remainingSum <= 0 || reversal.Sum > remainingSum {
+		return ErrReversalExceedsWithdrawal
+	}
+
+	reversal.CreatedAt = time.Now()
+	query = `INSERT INTO withdrawal_reversal (withdrawal_id, sum, reason, admin_id, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id
+	`
+	err = tx.QueryRowContext(
+		ctx, query, reversal.WithdrawalID, reversal.Sum, reversal.Reason, reversal.AdminID, reversal.CreatedAt,
+	).Scan(&reversal.ID)
+	if err != nil {
+		return err
+	}
+
+	// возвращаем баллы со счета списанных на счет доступных с прежними сроками сгорания
+	err = r.ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation:       domain.LedgerOperationReversal,
+		Reference:       withdrawal.Order,
+		PreservesExpiry: true,
+		LotsSourceID:    withdrawal.LotsTransactionID,
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountWithdrawn, UserID: withdrawal.UserID, Amount: -reversal.Sum},
+			{Account: domain.LedgerAccountCurrent, UserID: withdrawal.UserID, Amount: reversal.Sum},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *BalanceRepository) GetUserBalance(ctx context.Context, userID int) (*domain.BalanceData, error) {
-	query := `SELECT current, withdrawn
+	query := `SELECT current, withdrawn, held
 		FROM user_balance
 		WHERE user_id = $1
 	`
@@ -102,3 +270,102 @@ func (r *BalanceRepository) GetUserBalance(ctx context.Context, userID int) (*do
 
 	return balanceData, nil
 }
+
+// GetStatement возвращает выписку по счету доступных баллов пользователя в порядке времени операций
+// баланс после каждой операции считается по всем записям журнала, а не только по записям выбранного периода
+// к операциям по заказу добавляются номер и статус заказа, к списаниям - сумма возвратов по ним
+func (r *BalanceRepository) GetStatement(
+	ctx context.Context, userID int, filter domain.StatementFilter,
+) ([]*domain.StatementLine, error) {
+	query := `SELECT s.operation, s.reference, s.amount, s.balance, s.created_at, s.order_number,
+			COALESCE(o.status, '') AS order_status,
+			CASE WHEN s.operation = $8 OR s.operation = $11 AND EXISTS (
+				SELECT 1 FROM balance_hold h
+				WHERE h.user_id = $1 AND h.order_number = s.order_number AND h.status = $13
+			) THEN COALESCE((
+				SELECT SUM(wr.sum) FROM withdrawal_reversal wr
+				JOIN withdrawal w ON w.id = wr.withdrawal_id
+				WHERE w.order_number = s.order_number AND w.user_id = $1
+			), 0) ELSE 0 END AS withdrawal_reversed
+		FROM (
+			SELECT lt.operation, lt.reference, le.amount, le.created_at, le.id,
+				SUM(le.amount) OVER (ORDER BY le.created_at, le.id) AS balance,
+				CASE WHEN lt.operation IN ($7, $8, $9, $10, $11, $12) THEN lt.reference ELSE '' END AS order_number
+			FROM ledger_entry le
+			JOIN ledger_transaction lt ON lt.id = le.transaction_id
+			WHERE le.user_id = $1 AND le.account = $2
+		) s
+		LEFT JOIN user_order o ON o.number = s.order_number AND o.user_id = $1
+		WHERE ($3::timestamptz IS NULL OR s.created_at >= $3) AND ($4::timestamptz IS NULL OR s.created_at < $4)
+		ORDER BY s.created_at, s.id
+		LIMIT $5 OFFSET $6
+	`
+
+	var lines []*domain.StatementLine
+	err := r.db.SelectContext(
+		ctx, &lines, query, userID, domain.LedgerAccountCurrent,
+		nullableTime(filter.From), nullableTime(filter.To), filter.Limit, filter.Offset,
+		// операции, у которых в журнале указан номер заказа
+		domain.LedgerOperationAccrual, domain.LedgerOperationWithdrawal, domain.LedgerOperationCapture,
+		domain.LedgerOperationReversal, domain.LedgerOperationHold, domain.LedgerOperationRelease,
+		// оплата резервом отражается на текущем счете строкой резервирования, поэтому возврат по ней
+		// показывается у резервирования подтвержденного резерва
+		domain.HoldCapturedStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// nullableTime возвращает NULL для нулевого времени
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// getWithdrawnSumSince возвращает сумму списаний и активных резервов пользователя начиная с момента since
+func getWithdrawnSumSince(ctx context.Context, tx *sql.Tx, userID int, since time.Time) (domain.Money, error) {
+	query := `SELECT
+			(SELECT COALESCE(SUM(sum), 0) FROM withdrawal WHERE user_id = $1 AND processed_at >= $2) +
+			(SELECT COALESCE(SUM(sum), 0) FROM balance_hold WHERE user_id = $1 AND status = $3 AND created_at >= $2)
+	`
+
+	var sum domain.Money
+	err := tx.QueryRowContext(ctx, query, userID, since, domain.HoldActiveStatus).Scan(&sum)
+	if err != nil {
+		return 0, err
+	}
+	return sum, nil
+}
+
+// GetPasswordChangedAt возвращает время последней смены пароля пользователя или nil, если пароль не менялся
+func (r *BalanceRepository) GetPasswordChangedAt(ctx context.Context, userID int) (*time.Time, error) {
+	query := `SELECT password_changed_at FROM auth_user WHERE id = $1`
+
+	var changedAt sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&changedAt); err != nil {
+		return nil, err
+	}
+	if !changedAt.Valid {
+		return nil, nil
+	}
+	return &changedAt.Time, nil
+}
+
+// GetExpiringPoints возвращает баллы пользователя, которые сгорят до момента before
+func (r *BalanceRepository) GetExpiringPoints(ctx context.Context, userID int, before time.Time) ([]*domain.ExpiringPoints, error) {
+	query := `SELECT SUM(remaining) AS sum, expires_at FROM points_lot
+		WHERE user_id = $1 AND remaining > 0 AND expires_at <= $2
+		GROUP BY expires_at
+		ORDER BY expires_at
+	`
+
+	var expiring []*domain.ExpiringPoints
+	if err := r.db.SelectContext(ctx, &expiring, query, userID, before); err != nil {
+		return nil, err
+	}
+	return expiring, nil
+}
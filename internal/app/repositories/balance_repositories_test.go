@@ -0,0 +1,301 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	_ "github.com/jackc/pgx/stdlib"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// initTestDB подключается к тестовой базе данных, адрес которой задается переменной окружения TEST_DATABASE_URI
+// если переменная не задана, то тест пропускается
+func initTestDB(t *testing.T) *sqlx.DB {
+	connStr := os.Getenv("TEST_DATABASE_URI")
+	if connStr == "" {
+		t.Skip("TEST_DATABASE_URI is not set")
+	}
+	db, err := InitDB(connStr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db
+}
+
+// createTestUser создает пользователя с уникальным логином и заданным балансом
+func createTestUser(t *testing.T, db *sqlx.DB, balance domain.Money) int {
+	ctx := context.Background()
+	login := fmt.Sprintf("test_user_%d", time.Now().UnixNano())
+	userRepository := NewUserRepository(db, NewOrderRepository(db), NewLedgerRepository(db, 0))
+	require.NoError(t, userRepository.CreateUser(ctx, domain.UserDTO{Login: login, Password: "123"}))
+	user, err := userRepository.GetUserByLogin(ctx, login)
+	require.NoError(t, err)
+
+	if balance == 0 {
+		return user.ID
+	}
+
+	// начисляем баллы через журнал операций, чтобы баланс совпадал с журналом
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	err = NewLedgerRepository(db, 0).PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationAdjustment,
+		Reference: "test balance",
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountAdjustments, Amount: -balance},
+			{Account: domain.LedgerAccountCurrent, UserID: user.ID, Amount: balance},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	return user.ID
+}
+
+func TestBalanceRepository_WithdrawBalanceForOrder_Concurrent(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	const (
+		withdrawalsNum   = 50
+		wantSucceededNum = 10
+	)
+	initialBalance := domain.NewMoney(100, 0)
+	withdrawalSum := domain.NewMoney(10, 0)
+	userID := createTestUser(t, db, initialBalance)
+	balanceRepository := NewBalanceRepository(db, NewLedgerRepository(db, 0))
+
+	// параллельно выполняем списания, суммарно превышающие баланс пользователя
+	var wg sync.WaitGroup
+	errs := make(chan error, withdrawalsNum)
+	for i := 0; i < withdrawalsNum; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			withdrawal := &domain.Withdrawal{
+				Order:  fmt.Sprintf("%d-%d", userID, i),
+				Sum:    withdrawalSum,
+				UserID: userID,
+			}
+			errs <- balanceRepository.WithdrawBalanceForOrder(ctx, withdrawal, nil)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.True(t, errors.Is(err, ErrCanNotWithdrawBalance), "unexpected error: %v", err)
+	}
+	assert.Equal(t, wantSucceededNum, succeeded)
+
+	// баланс не должен уйти в минус, а сумма списаний должна совпадать с историей
+	balance, err := balanceRepository.GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Money(0), balance.Current)
+	assert.Equal(t, initialBalance, balance.Withdrawn)
+
+	withdrawals, err := balanceRepository.GetBalanceWithdrawals(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, withdrawals, wantSucceededNum)
+}
+
+func TestBalanceRepository_WithdrawBalanceForOrder_OrderNumberUniqueness(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, domain.NewMoney(100, 0))
+	otherUserID := createTestUser(t, db, domain.NewMoney(100, 0))
+	balanceRepository := NewBalanceRepository(db, NewLedgerRepository(db, 0))
+
+	// один и тот же заказ нельзя оплатить баллами дважды
+	orderNumber := fmt.Sprintf("%d-withdrawal", userID)
+	withdrawal := &domain.Withdrawal{Order: orderNumber, Sum: domain.NewMoney(10, 0), UserID: userID}
+	require.NoError(t, balanceRepository.WithdrawBalanceForOrder(ctx, withdrawal, nil))
+	err := balanceRepository.WithdrawBalanceForOrder(ctx, withdrawal, nil)
+	assert.ErrorIs(t, err, ErrOrderAlreadyExists)
+	withdrawal.UserID = otherUserID
+	err = balanceRepository.WithdrawBalanceForOrder(ctx, withdrawal, nil)
+	assert.ErrorIs(t, err, ErrOrderAlreadyExists)
+
+	// номер заказа, загруженного другим пользователем, нельзя использовать для списания
+	uploadedOrderNumber := fmt.Sprintf("%d-uploaded", userID)
+	_, _, err = NewOrderRepository(db).GetOrCreateOrder(ctx, domain.OrderDTO{
+		Number: uploadedOrderNumber, UploadedAt: time.Now(), UserID: userID,
+	})
+	require.NoError(t, err)
+	err = balanceRepository.WithdrawBalanceForOrder(ctx, &domain.Withdrawal{
+		Order: uploadedOrderNumber, Sum: domain.NewMoney(10, 0), UserID: otherUserID,
+	}, nil)
+	assert.ErrorIs(t, err, ErrOrderAlreadyExists)
+
+	balance, err := balanceRepository.GetUserBalance(ctx, otherUserID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(100, 0), balance.Current)
+}
+
+func TestBalanceRepository_GetStatement(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, domain.NewMoney(100, 0))
+	balanceRepository := NewBalanceRepository(db, NewLedgerRepository(db, 0))
+	for i := 1; i <= 2; i++ {
+		err := balanceRepository.WithdrawBalanceForOrder(ctx, &domain.Withdrawal{
+			Order: fmt.Sprintf("%d-statement-%d", userID, i), Sum: domain.NewMoney(int64(10*i), 0), UserID: userID,
+		}, nil)
+		require.NoError(t, err)
+	}
+
+	lines, err := balanceRepository.GetStatement(ctx, userID, domain.StatementFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	assert.Equal(t, domain.NewMoney(100, 0), lines[0].Balance)
+	assert.Equal(t, domain.NewMoney(90, 0), lines[1].Balance)
+	assert.Equal(t, domain.NewMoney(70, 0), lines[2].Balance)
+	// у списания указан номер заказа, заказ не загружался пользователем, поэтому статуса нет
+	assert.Equal(t, fmt.Sprintf("%d-statement-1", userID), lines[1].OrderNumber)
+	assert.Empty(t, lines[1].OrderStatus)
+	assert.Empty(t, lines[0].OrderNumber)
+
+	// баланс на странице выписки учитывает операции с предыдущих страниц
+	lines, err = balanceRepository.GetStatement(ctx, userID, domain.StatementFilter{Limit: 1, Offset: 2})
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, -domain.NewMoney(20, 0), lines[0].Amount)
+	assert.Equal(t, domain.NewMoney(70, 0), lines[0].Balance)
+}
+
+func TestBalanceRepository_GetStatement_ReversedCapturedHold(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, domain.NewMoney(100, 0))
+	adminID := createTestUser(t, db, 0)
+	ledgerRepository := NewLedgerRepository(db, 0)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+	holdRepository := NewHoldRepository(db, ledgerRepository)
+
+	now := time.Now()
+	hold := &domain.BalanceHold{
+		UserID:    userID,
+		Order:     fmt.Sprintf("%d-statement-hold", userID),
+		Sum:       domain.NewMoney(30, 0),
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Minute),
+	}
+	require.NoError(t, holdRepository.CreateHold(ctx, hold, nil))
+	_, err := holdRepository.CaptureHold(ctx, userID, hold.ID, now)
+	require.NoError(t, err)
+
+	var withdrawalID int
+	err = db.GetContext(ctx, &withdrawalID, `SELECT id FROM withdrawal WHERE order_number = $1`, hold.Order)
+	require.NoError(t, err)
+	require.NoError(t, balanceRepository.ReverseWithdrawal(ctx, &domain.WithdrawalReversal{
+		WithdrawalID: withdrawalID, Sum: domain.NewMoney(10, 0), Reason: "refund", AdminID: adminID, CreatedAt: now,
+	}))
+
+	// подтверждение резерва не затрагивает текущий счет, поэтому возврат показывается у строки резервирования
+	lines, err := balanceRepository.GetStatement(ctx, userID, domain.StatementFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	assert.Equal(t, domain.LedgerOperationHold, lines[1].Operation)
+	assert.Equal(t, hold.Order, lines[1].OrderNumber)
+	assert.Equal(t, domain.NewMoney(10, 0), lines[1].WithdrawalReversed)
+	assert.Equal(t, domain.LedgerOperationReversal, lines[2].Operation)
+	assert.Zero(t, lines[2].WithdrawalReversed)
+}
+
+func TestBalanceRepository_WithdrawBalanceForOrder_ConcurrentOrderUpload(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, domain.NewMoney(100, 0))
+	otherUserID := createTestUser(t, db, 0)
+	balanceRepository := NewBalanceRepository(db, NewLedgerRepository(db, 0))
+	orderNumber := fmt.Sprintf("%d-uploading", otherUserID)
+
+	// другой пользователь загружает заказ, но еще не зафиксировал транзакцию
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.NoError(t, lockOrderNumber(ctx, tx, orderNumber))
+	_, err = tx.ExecContext(
+		ctx, `INSERT INTO user_order (number, uploaded_at, user_id) VALUES ($1, $2, $3)`,
+		orderNumber, time.Now(), otherUserID,
+	)
+	require.NoError(t, err)
+
+	// списание дожидается загрузки заказа и видит, что заказ чужой
+	errs := make(chan error, 1)
+	go func() {
+		errs <- balanceRepository.WithdrawBalanceForOrder(ctx, &domain.Withdrawal{
+			Order: orderNumber, Sum: domain.NewMoney(10, 0), UserID: userID,
+		}, nil)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, tx.Commit())
+	assert.ErrorIs(t, <-errs, ErrOrderAlreadyExists)
+}
+
+func TestBalanceRepository_WithdrawBalanceForOrder_ConcurrentCaps(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, domain.NewMoney(1000, 0))
+	ledgerRepository := NewLedgerRepository(db, 0)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+	holdRepository := NewHoldRepository(db, ledgerRepository)
+	errCapExceeded := errors.New("cap exceeded")
+	now := time.Now()
+	caps := []domain.WithdrawalCap{{Limit: domain.NewMoney(100, 0), Since: now.Add(-time.Hour), Err: errCapExceeded}}
+
+	// параллельные списания и резервы не должны вместе превысить ограничение
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			orderNumber := fmt.Sprintf("%d-cap-%d", userID, i)
+			if i%2 == 0 {
+				errs <- balanceRepository.WithdrawBalanceForOrder(ctx, &domain.Withdrawal{
+					Order: orderNumber, Sum: domain.NewMoney(30, 0), UserID: userID,
+				}, caps)
+				return
+			}
+			errs <- holdRepository.CreateHold(ctx, &domain.BalanceHold{
+				UserID: userID, Order: orderNumber, Sum: domain.NewMoney(30, 0),
+				CreatedAt: now, ExpiresAt: now.Add(time.Minute),
+			}, caps)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, errCapExceeded)
+	}
+	assert.Equal(t, 3, succeeded)
+
+	balance, err := balanceRepository.GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(910, 0), balance.Current)
+}
@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+const campaignColumns = `id, name, type, multiplier, bonus, first_order_only, starts_at, ends_at, active, created_at`
+
+type CampaignRepository struct {
+	db               *sqlx.DB
+	ledgerRepository *LedgerRepository
+}
+
+func NewCampaignRepository(db *sqlx.DB, ledgerRepository *LedgerRepository) *CampaignRepository {
+	return &CampaignRepository{db: db, ledgerRepository: ledgerRepository}
+}
+
+func (r *CampaignRepository) CreateCampaign(ctx context.Context, campaign *domain.Campaign) error {
+	campaign.CreatedAt = time.Now()
+	query := `INSERT INTO campaign (name, type, multiplier, bonus, first_order_only, starts_at, ends_at, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id
+	`
+	return r.db.QueryRowContext(
+		ctx, query, campaign.Name, campaign.Type, campaign.Multiplier, campaign.Bonus, campaign.FirstOrderOnly,
+		campaign.StartsAt, campaign.EndsAt, campaign.Active, campaign.CreatedAt,
+	).Scan(&campaign.ID)
+}
+
+func (r *CampaignRepository) UpdateCampaign(ctx context.Context, campaign *domain.Campaign) error {
+	query := `UPDATE campaign SET name = $1, type = $2, multiplier = $3, bonus = $4, first_order_only = $5,
+			starts_at = $6, ends_at = $7, active = $8
+		WHERE id = $9
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(
+		ctx, query, campaign.Name, campaign.Type, campaign.Multiplier, campaign.Bonus, campaign.FirstOrderOnly,
+		campaign.StartsAt, campaign.EndsAt, campaign.Active, campaign.ID,
+	).Scan(&campaign.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCampaignDoesNotExist
+	}
+	return err
+}
+
+// DeleteCampaign удаляет акцию, по которой еще не начислялись бонусы
+// акцию с начисленными бонусами можно только отключить
+func (r *CampaignRepository) DeleteCampaign(ctx context.Context, campaignID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM campaign WHERE id = $1`, campaignID)
+	var pgErr pgx.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return ErrCampaignHasBonuses
+		}
+	}
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCampaignDoesNotExist
+	}
+	return nil
+}
+
+func (r *CampaignRepository) GetCampaign(ctx context.Context, campaignID int) (*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaign WHERE id = $1`
+
+	var campaign domain.Campaign
+	err := r.db.GetContext(ctx, &campaign, query, campaignID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCampaignDoesNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *CampaignRepository) GetCampaigns(ctx context.Context) ([]*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaign ORDER BY starts_at DESC, id DESC`
+
+	var campaigns []*domain.Campaign
+	if err := r.db.SelectContext(ctx, &campaigns, query); err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+// GetRunningCampaigns возвращает включенные акции, действующие в момент now
+func (r *CampaignRepository) GetRunningCampaigns(ctx context.Context, now time.Time) ([]*domain.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaign
+		WHERE active AND starts_at <= $1 AND ends_at > $1
+		ORDER BY id
+	`
+
+	var campaigns []*domain.Campaign
+	if err := r.db.SelectContext(ctx, &campaigns, query, now); err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+// GetCampaignOrder возвращает заказ вместе с количеством обработанных заказов его владельца
+func (r *CampaignRepository) GetCampaignOrder(ctx context.Context, orderNumber string) (*domain.CampaignOrder, error) {
+	// заказ первый, если среди обработанных заказов пользователя нет загруженных раньше него,
+	// поэтому повторное начисление бонусов после обработки следующих заказов видит тот же первый заказ
+	query := `SELECT o.number, o.user_id, o.accrual,
+			NOT EXISTS (
+				SELECT 1 FROM user_order p
+				WHERE p.user_id = o.user_id AND p.status = $2 AND (p.uploaded_at, p.number) < (o.uploaded_at, o.number)
+			)
+		FROM user_order o
+		WHERE o.number = $1
+	`
+
+	var order domain.CampaignOrder
+	err := r.db.QueryRowContext(ctx, query, orderNumber, domain.OrderProcessedStatus).Scan(
+		&order.Number, &order.UserID, &order.Accrual, &order.FirstProcessedOrder,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// AwardCampaignBonus начисляет пользователю бонус по акции
+// за один заказ бонус по каждой акции начисляется не больше одного раза,
+// а по акции только за первый заказ - не больше одного раза пользователю, даже если его заказы обрабатываются параллельно
+func (r *CampaignRepository) AwardCampaignBonus(
+	ctx context.Context, campaign *domain.Campaign, bonus *domain.CampaignBonus,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	bonus.CreatedAt = time.Now()
+	query := `INSERT INTO campaign_bonus (campaign_id, order_number, user_id, sum, created_at, first_order_only)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING
+		RETURNING id
+	`
+	err = tx.QueryRowContext(
+		ctx, query, bonus.CampaignID, bonus.OrderNumber, bonus.UserID, bonus.Sum, bonus.CreatedAt, campaign.FirstOrderOnly,
+	).Scan(&bonus.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCampaignBonusAlreadyAwarded
+	}
+	if err != nil {
+		return err
+	}
+
+	// в описании операции указывается акция, по которой начислен бонус
+	err = r.ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationCampaign,
+		Reference: fmt.Sprintf("%s (order %s)", campaign.Name, bonus.OrderNumber),
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountCampaigns, Amount: -bonus.Sum},
+			{Account: domain.LedgerAccountCurrent, UserID: bonus.UserID, Amount: bonus.Sum},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
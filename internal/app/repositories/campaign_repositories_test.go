@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCampaignRepository_AwardCampaignBonus_FirstOrderOnly(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	campaignRepository := NewCampaignRepository(db, NewLedgerRepository(db, 0))
+	userID := createTestUser(t, db, 0)
+	now := time.Now()
+	campaign := &domain.Campaign{
+		Name: fmt.Sprintf("welcome %d", userID), Type: domain.CampaignTypeFixedBonus, Bonus: domain.NewMoney(50, 0),
+		FirstOrderOnly: true, StartsAt: now, EndsAt: now.Add(time.Hour), Active: true,
+	}
+	require.NoError(t, campaignRepository.CreateCampaign(ctx, campaign))
+
+	// заказы пользователя обрабатываются параллельно, но бонус за первый заказ начисляется один раз
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		orderNumber := fmt.Sprintf("%d-first-%d", userID, i)
+		_, err := db.ExecContext(
+			ctx, `INSERT INTO user_order (number, uploaded_at, user_id) VALUES ($1, $2, $3)`, orderNumber, now, userID,
+		)
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- campaignRepository.AwardCampaignBonus(ctx, campaign, &domain.CampaignBonus{
+				CampaignID: campaign.ID, OrderNumber: orderNumber, UserID: userID, Sum: campaign.Bonus,
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	awarded := 0
+	for err := range errs {
+		if errors.Is(err, ErrCampaignBonusAlreadyAwarded) {
+			continue
+		}
+		require.NoError(t, err)
+		awarded++
+	}
+	assert.Equal(t, 1, awarded)
+
+	balance, err := NewBalanceRepository(db, NewLedgerRepository(db, 0)).GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(50, 0), balance.Current)
+}
+
+func TestCampaignRepository_GetCampaignOrder_FirstProcessedOrder(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	campaignRepository := NewCampaignRepository(db, NewLedgerRepository(db, 0))
+	userID := createTestUser(t, db, 0)
+	now := time.Now()
+	firstOrder := fmt.Sprintf("%d-first", userID)
+	secondOrder := fmt.Sprintf("%d-second", userID)
+	for i, orderNumber := range []string{firstOrder, secondOrder} {
+		_, err := db.ExecContext(
+			ctx, `INSERT INTO user_order (number, uploaded_at, user_id, status) VALUES ($1, $2, $3, $4)`,
+			orderNumber, now.Add(time.Duration(i)*time.Minute), userID, domain.OrderProcessedStatus,
+		)
+		require.NoError(t, err)
+	}
+
+	// начисление бонусов за первый заказ повторяется уже после обработки второго заказа
+	order, err := campaignRepository.GetCampaignOrder(ctx, firstOrder)
+	require.NoError(t, err)
+	assert.True(t, order.FirstProcessedOrder)
+	order, err = campaignRepository.GetCampaignOrder(ctx, secondOrder)
+	require.NoError(t, err)
+	assert.False(t, order.FirstProcessedOrder)
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"fmt"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -13,6 +14,80 @@ func openConnection(connStr string) (*sqlx.DB, error) {
 	return db, nil
 }
 
+// moneyColumns колонки с суммами баллов, которые ранее хранились как числа с плавающей точкой
+var moneyColumns = []struct {
+	table  string
+	column string
+}{
+	{table: "user_order", column: "accrual"},
+	{table: "withdrawal", column: "sum"},
+	{table: "user_balance", column: "current"},
+	{table: "user_balance", column: "withdrawn"},
+}
+
+// migrateMoneyColumnQuery возвращает запрос, переводящий колонку в тип numeric(18, 2)
+// значения округляются до сотых, миграция выполняется только для колонок, еще не имеющих тип numeric
+func migrateMoneyColumnQuery(table string, column string) string {
+	return fmt.Sprintf(`do $$
+		begin
+			if (select data_type from information_schema.columns
+				where table_schema = current_schema() and table_name = '%[1]s' and column_name = '%[2]s') <> 'numeric' then
+				alter table %[1]s alter column %[2]s type numeric(18, 2) using round(%[2]s::numeric, 2);
+			end if;
+		end $$;`, table, column)
+}
+
+// duplicateWithdrawalsQuery готовит списания к уникальному индексу по номеру заказа
+// до появления индекса один заказ мог быть оплачен баллами несколько раз: первое списание сохраняет номер заказа,
+// а к номерам повторных добавляется суффикс "-dup-<id списания>", чтобы их можно было найти и разобрать вручную
+// суммы и записи журнала операций не меняются, поэтому балансы пользователей остаются прежними
+const duplicateWithdrawalsQuery = `update withdrawal w
+		set order_number = left(w.order_number, 40) || '-dup-' || w.id
+		where exists (select 1 from withdrawal o where o.order_number = w.order_number and o.id < w.id)`
+
+// openingBalancesQuery переносит в журнал операций балансы, накопленные до его появления
+// для каждого пользователя без записей в журнале создается операция OPENING
+const openingBalancesQuery = `with opening as (
+		select ub.user_id, ub.current, ub.withdrawn
+		from user_balance ub
+		where (ub.current <> 0 or ub.withdrawn <> 0)
+			and not exists (select 1 from ledger_entry le where le.user_id = ub.user_id)
+	), opening_tx as (
+		insert into ledger_transaction (operation, reference, created_at)
+		select 'OPENING', user_id::text, now() from opening
+		returning id, reference
+	)
+	insert into ledger_entry (transaction_id, account, user_id, amount, created_at)
+	select opening_tx.id, e.account, e.user_id, e.amount, now()
+	from opening_tx
+	join opening o on o.user_id::text = opening_tx.reference
+	cross join lateral (values
+		('CURRENT', o.user_id, o.current),
+		('WITHDRAWN', o.user_id, o.withdrawn),
+		('SYSTEM_OPENING', null::int, -(o.current + o.withdrawn))
+	) as e(account, user_id, amount);`
+
+// legacyPointsLotsQuery создает бессрочные партии для баллов, начисленных до появления партий
+// перенос выполняется один раз: повторный запуск после него создавал бы бессрочные партии для любых расхождений
+// баланса с партиями, поэтому выполнение отмечается в schema_migration
+const legacyPointsLotsQuery = `with migration as (
+		insert into schema_migration (name, applied_at) values ('legacy_points_lots', now())
+		on conflict (name) do nothing
+		returning name
+	)
+	insert into points_lot (user_id, amount, remaining, credited_at)
+	select ub.user_id, ub.current - coalesce(l.remaining, 0), ub.current - coalesce(l.remaining, 0), now()
+	from user_balance ub
+	left join (select user_id, sum(remaining) as remaining from points_lot group by user_id) l on l.user_id = ub.user_id
+	where ub.current > coalesce(l.remaining, 0) and exists (select 1 from migration);`
+
+// legacyAccrualJobsQuery ставит в очередь необработанные заказы, принятые до появления очереди заданий
+const legacyAccrualJobsQuery = `insert into accrual_job (order_number, stage, next_attempt_at, created_at)
+	select number, case when status = 'NEW' then 'REGISTER' else 'POLL' end, now(), now()
+	from user_order
+	where status in ('NEW', 'PROCESSING')
+	on conflict (order_number) do nothing;`
+
 func createSchema(db *sqlx.DB) error {
 	queries := []string{
 		`create table if not exists auth_user(
@@ -26,14 +101,14 @@ func createSchema(db *sqlx.DB) error {
 			uploaded_at timestamptz not null,
 			user_id int not null,
 			status varchar not null default 'NEW',
-    		accrual double precision not null default 0,
+    		accrual numeric(18, 2) not null default 0,
 			constraint fk_user foreign key(user_id) references auth_user(id),
 			constraint status_values check (status IN ('NEW', 'PROCESSING', 'INVALID', 'PROCESSED'))
 		);`,
 		`create table if not exists withdrawal(
 			id serial primary key not null,
 			processed_at timestamptz not null,
-			sum double precision not null,
+			sum numeric(18, 2) not null,
 			order_number varchar(64) not null,
     		user_id int not null,
     		constraint fk_user foreign key(user_id) references auth_user(id),
@@ -41,13 +116,260 @@ func createSchema(db *sqlx.DB) error {
 		);`,
 		`create table if not exists user_balance(
     		user_id int primary key not null,
-    		current float not null default 0,
-    		withdrawn float not null default 0,
+    		current numeric(18, 2) not null default 0,
+    		withdrawn numeric(18, 2) not null default 0,
     		constraint current_value check (current >= 0),
     		constraint withdrawn_value check (withdrawn >= 0),
     		constraint fk_user foreign key(user_id) references auth_user(id)
     	);`,
+		`create table if not exists idempotency_key(
+			user_id int not null,
+			key varchar(255) not null,
+			fingerprint varchar(64) not null,
+			expires_at timestamptz not null,
+			status_code int,
+			content_type varchar(255),
+			response_body bytea,
+			primary key (user_id, key),
+			constraint fk_user foreign key(user_id) references auth_user(id)
+		);`,
+		`create table if not exists ledger_transaction(
+			id bigserial primary key not null,
+			operation varchar(32) not null,
+			reference varchar(128) not null default '',
+			created_at timestamptz not null
+		);`,
+		`create table if not exists ledger_entry(
+			id bigserial primary key not null,
+			transaction_id bigint not null,
+			account varchar(32) not null,
+			user_id int,
+			amount numeric(18, 2) not null,
+			created_at timestamptz not null,
+			constraint fk_transaction foreign key(transaction_id) references ledger_transaction(id),
+			constraint fk_user foreign key(user_id) references auth_user(id)
+		);`,
+		`alter table auth_user add column if not exists is_admin boolean not null default false;`,
+		`create table if not exists withdrawal_reversal(
+			id serial primary key not null,
+			withdrawal_id int not null,
+			sum numeric(18, 2) not null,
+			reason varchar(512) not null,
+			admin_id int not null,
+			created_at timestamptz not null,
+			constraint fk_withdrawal foreign key(withdrawal_id) references withdrawal(id),
+			constraint fk_admin foreign key(admin_id) references auth_user(id),
+			constraint sum_value check (sum > 0)
+		);`,
+		duplicateWithdrawalsQuery,
+		`create unique index if not exists withdrawal_order_number_idx on withdrawal(order_number);`,
+		`create index if not exists ledger_entry_user_idx on ledger_entry(user_id, account);`,
+		`create index if not exists ledger_entry_transaction_idx on ledger_entry(transaction_id);`,
+		`create table if not exists points_lot(
+			id bigserial primary key not null,
+			user_id int not null,
+			transaction_id bigint,
+			amount numeric(18, 2) not null,
+			remaining numeric(18, 2) not null,
+			credited_at timestamptz not null,
+			expires_at timestamptz,
+			constraint fk_user foreign key(user_id) references auth_user(id),
+			constraint fk_transaction foreign key(transaction_id) references ledger_transaction(id),
+			constraint remaining_value check (remaining >= 0 and remaining <= amount)
+		);`,
+		`create index if not exists points_lot_user_idx on points_lot(user_id) where remaining > 0;`,
+		`create index if not exists points_lot_expires_idx on points_lot(expires_at) where remaining > 0;`,
+		`create table if not exists transfer(
+			id serial primary key not null,
+			sender_id int not null,
+			recipient_id int not null,
+			sum numeric(18, 2) not null,
+			note varchar(256) not null default '',
+			created_at timestamptz not null,
+			constraint fk_sender foreign key(sender_id) references auth_user(id),
+			constraint fk_recipient foreign key(recipient_id) references auth_user(id),
+			constraint sum_value check (sum > 0),
+			constraint different_users check (sender_id <> recipient_id)
+		);`,
+		`create index if not exists transfer_sender_idx on transfer(sender_id, created_at);`,
+		`create index if not exists transfer_recipient_idx on transfer(recipient_id, created_at);`,
+		`alter table user_balance add column if not exists held numeric(18, 2) not null default 0;`,
+		`create table if not exists balance_hold(
+			id serial primary key not null,
+			user_id int not null,
+			order_number varchar(64) not null,
+			sum numeric(18, 2) not null,
+			status varchar(16) not null default 'ACTIVE',
+			created_at timestamptz not null,
+			expires_at timestamptz not null,
+			resolved_at timestamptz,
+			constraint fk_user foreign key(user_id) references auth_user(id),
+			constraint sum_value check (sum > 0),
+			constraint status_values check (status IN ('ACTIVE', 'CAPTURED', 'VOIDED', 'EXPIRED'))
+		);`,
+		`create unique index if not exists balance_hold_active_order_idx on balance_hold(order_number)
+			where status = 'ACTIVE';`,
+		`create index if not exists balance_hold_expires_idx on balance_hold(expires_at) where status = 'ACTIVE';`,
+		`create table if not exists user_tier(
+			user_id int primary key not null,
+			tier varchar(16) not null,
+			earned numeric(18, 2) not null,
+			calculated_at timestamptz not null,
+			constraint fk_user foreign key(user_id) references auth_user(id)
+		);`,
+		`alter table auth_user add column if not exists password_changed_at timestamptz;`,
+		`create index if not exists withdrawal_user_idx on withdrawal(user_id, processed_at);`,
+		`alter table ledger_transaction alter column reference type varchar(256);`,
+		`create table if not exists campaign(
+			id serial primary key not null,
+			name varchar(64) not null,
+			type varchar(16) not null,
+			multiplier double precision not null default 0,
+			bonus numeric(18, 2) not null default 0,
+			first_order_only boolean not null default false,
+			starts_at timestamptz not null,
+			ends_at timestamptz not null,
+			active boolean not null default true,
+			created_at timestamptz not null,
+			constraint type_values check (type IN ('MULTIPLIER', 'FIXED_BONUS')),
+			constraint period_value check (starts_at < ends_at)
+		);`,
+		`create table if not exists campaign_bonus(
+			id serial primary key not null,
+			campaign_id int not null,
+			order_number varchar(64) not null,
+			user_id int not null,
+			sum numeric(18, 2) not null,
+			created_at timestamptz not null,
+			constraint fk_campaign foreign key(campaign_id) references campaign(id),
+			constraint fk_order foreign key(order_number) references user_order(number),
+			constraint fk_user foreign key(user_id) references auth_user(id),
+			constraint campaign_order_unique unique (campaign_id, order_number)
+		);`,
+		`alter table auth_user add column if not exists referral_code varchar(16);`,
+		`update auth_user set referral_code = upper(substr(md5(random()::text || id::text), 1, 8))
+			where referral_code is null;`,
+		`create unique index if not exists auth_user_referral_code_idx on auth_user(referral_code);`,
+		`create table if not exists referral(
+			id serial primary key not null,
+			referrer_id int not null,
+			referred_id int not null,
+			status varchar(16) not null default 'PENDING',
+			bonus numeric(18, 2) not null default 0,
+			created_at timestamptz not null,
+			rewarded_at timestamptz,
+			constraint fk_referrer foreign key(referrer_id) references auth_user(id),
+			constraint fk_referred foreign key(referred_id) references auth_user(id),
+			constraint referred_unique unique (referred_id),
+			constraint different_users check (referrer_id <> referred_id),
+			constraint status_values check (status IN ('PENDING', 'REWARDED', 'CAP_REACHED'))
+		);`,
+		`create index if not exists referral_referrer_idx on referral(referrer_id, status);`,
+		`create table if not exists voucher_batch(
+			id serial primary key not null,
+			value numeric(18, 2) not null,
+			expires_at timestamptz not null,
+			usage_limit int not null default 1,
+			created_by int not null,
+			created_at timestamptz not null,
+			constraint fk_admin foreign key(created_by) references auth_user(id),
+			constraint positive_value check (value > 0),
+			constraint positive_usage_limit check (usage_limit > 0)
+		);`,
+		`create table if not exists voucher(
+			id serial primary key not null,
+			batch_id int not null,
+			code varchar(32) not null,
+			used_count int not null default 0,
+			constraint fk_batch foreign key(batch_id) references voucher_batch(id),
+			constraint voucher_code_unique unique (code)
+		);`,
+		`create index if not exists voucher_batch_idx on voucher(batch_id);`,
+		`create table if not exists voucher_redemption(
+			id serial primary key not null,
+			voucher_id int not null,
+			user_id int not null,
+			sum numeric(18, 2) not null,
+			redeemed_at timestamptz not null,
+			constraint fk_voucher foreign key(voucher_id) references voucher(id),
+			constraint fk_user foreign key(user_id) references auth_user(id),
+			constraint voucher_user_unique unique (voucher_id, user_id)
+		);`,
+		`create table if not exists balance_adjustment(
+			id serial primary key not null,
+			user_id int not null,
+			amount numeric(18, 2) not null,
+			reason varchar(256) not null,
+			status varchar(16) not null default 'PENDING',
+			requested_by int not null,
+			reviewed_by int,
+			created_at timestamptz not null,
+			reviewed_at timestamptz,
+			constraint fk_user foreign key(user_id) references auth_user(id),
+			constraint fk_requested_by foreign key(requested_by) references auth_user(id),
+			constraint fk_reviewed_by foreign key(reviewed_by) references auth_user(id),
+			constraint nonzero_amount check (amount <> 0),
+			constraint status_values check (status IN ('PENDING', 'APPLIED', 'REJECTED'))
+		);`,
+		`create index if not exists balance_adjustment_user_idx on balance_adjustment(user_id, created_at);`,
+		`create table if not exists audit_log(
+			id bigserial primary key not null,
+			actor_id int not null,
+			action varchar(64) not null,
+			entity_type varchar(32) not null,
+			entity_id int not null,
+			details text not null default '',
+			created_at timestamptz not null,
+			constraint fk_actor foreign key(actor_id) references auth_user(id)
+		);`,
+		`create index if not exists audit_log_created_at_idx on audit_log(created_at);`,
+		`create table if not exists accrual_job(
+			id bigserial primary key not null,
+			order_number varchar(64) not null,
+			stage varchar(16) not null default 'REGISTER',
+			attempts int not null default 0,
+			next_attempt_at timestamptz not null,
+			locked_until timestamptz,
+			created_at timestamptz not null,
+			constraint accrual_job_order_unique unique (order_number),
+			constraint fk_order foreign key(order_number) references user_order(number),
+			constraint stage_values check (stage IN ('REGISTER', 'POLL'))
+		);`,
+		`create index if not exists accrual_job_next_attempt_idx on accrual_job(next_attempt_at);`,
+		`alter table accrual_job add column if not exists polls int not null default 0;`,
+		`alter table accrual_job add column if not exists locked_by varchar(32);`,
+		`alter table accrual_job add column if not exists last_error text not null default '';`,
+		`alter table accrual_job add column if not exists dead_lettered_at timestamptz;`,
+		`create index if not exists accrual_job_dead_lettered_idx on accrual_job(dead_lettered_at)
+			where dead_lettered_at is not null;`,
+		`create table if not exists points_lot_consumption(
+			id bigserial primary key not null,
+			lot_id bigint not null,
+			transaction_id bigint not null,
+			amount numeric(18, 2) not null,
+			restored numeric(18, 2) not null default 0,
+			constraint fk_lot foreign key(lot_id) references points_lot(id),
+			constraint fk_transaction foreign key(transaction_id) references ledger_transaction(id),
+			constraint restored_value check (restored >= 0 and restored <= amount)
+		);`,
+		`create index if not exists points_lot_consumption_transaction_idx on points_lot_consumption(transaction_id);`,
+		`alter table balance_hold add column if not exists lots_transaction_id bigint;`,
+		`alter table withdrawal add column if not exists lots_transaction_id bigint;`,
+		`alter table campaign_bonus add column if not exists first_order_only boolean not null default false;`,
+		`create unique index if not exists campaign_bonus_first_order_idx on campaign_bonus(campaign_id, user_id)
+			where first_order_only;`,
+		`create index if not exists ledger_transaction_operation_idx on ledger_transaction(operation, created_at);`,
+		`alter table user_order add column if not exists base_accrual numeric(18, 2);`,
+		`create index if not exists idempotency_key_expires_idx on idempotency_key(expires_at);`,
+		`create table if not exists schema_migration(
+			name varchar(64) primary key not null,
+			applied_at timestamptz not null
+		);`,
+	}
+	for _, c := range moneyColumns {
+		queries = append(queries, migrateMoneyColumnQuery(c.table, c.column))
 	}
+	queries = append(queries, openingBalancesQuery, legacyPointsLotsQuery, legacyAccrualJobsQuery)
 	tx, err := db.BeginTx(context.Background(), nil)
 	if err != nil {
 		return err
@@ -65,6 +387,20 @@ func createSchema(db *sqlx.DB) error {
 	return tx.Commit()
 }
 
+// OpenDB подключается к существующей базе данных без создания схемы и миграций
+// используется утилитами, которые не должны изменять схему, например сверкой балансов
+func OpenDB(connStr string) (*sqlx.DB, error) {
+	db, err := openConnection(connStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
 func InitDB(connStr string) (*sqlx.DB, error) {
 	db, err := openConnection(connStr)
 	if err != nil {
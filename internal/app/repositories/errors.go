@@ -5,4 +5,31 @@ import "fmt"
 var ErrUserAlreadyExists = fmt.Errorf("user with given login already exists")
 var ErrUserDoesNotExist = fmt.Errorf("user does not exist")
 var ErrOrderAlreadyExists = fmt.Errorf("order with this number already exists")
+var ErrOrderStatusIsFinal = fmt.Errorf("order already has a final status")
+var ErrOrderDoesNotExist = fmt.Errorf("order does not exist")
+var ErrAccrualJobLeaseLost = fmt.Errorf("accrual job is claimed by another worker")
+var ErrAccrualJobDoesNotExist = fmt.Errorf("accrual job does not exist")
+var ErrAccrualJobIsNotDeadLettered = fmt.Errorf("accrual job is not dead-lettered")
 var ErrCanNotWithdrawBalance = fmt.Errorf("can not withdraw balance")
+var ErrIdempotencyKeyDoesNotExist = fmt.Errorf("idempotency key does not exist")
+var ErrUnbalancedLedgerTransaction = fmt.Errorf("sum of ledger transaction entries must be zero")
+var ErrWithdrawalDoesNotExist = fmt.Errorf("withdrawal does not exist")
+var ErrReversalExceedsWithdrawal = fmt.Errorf("reversal sum exceeds the withdrawn sum")
+var ErrHoldDoesNotExist = fmt.Errorf("balance hold does not exist")
+var ErrHoldIsNotActive = fmt.Errorf("balance hold is not active")
+var ErrCampaignDoesNotExist = fmt.Errorf("campaign does not exist")
+var ErrCampaignHasBonuses = fmt.Errorf("campaign has awarded bonuses")
+var ErrCampaignBonusAlreadyAwarded = fmt.Errorf("campaign bonus for this order or first order bonus was already awarded")
+var ErrReferralCodeDoesNotExist = fmt.Errorf("referral code does not exist")
+var ErrReferralCodeAlreadyExists = fmt.Errorf("referral code already exists")
+var ErrSelfReferral = fmt.Errorf("user can not refer themselves")
+var ErrVoucherBatchDoesNotExist = fmt.Errorf("voucher batch does not exist")
+var ErrVoucherCodeAlreadyExists = fmt.Errorf("voucher code already exists")
+var ErrVoucherDoesNotExist = fmt.Errorf("voucher does not exist")
+var ErrVoucherExpired = fmt.Errorf("voucher has expired")
+var ErrVoucherUsageLimitReached = fmt.Errorf("voucher usage limit reached")
+var ErrVoucherAlreadyRedeemed = fmt.Errorf("voucher was already redeemed by user")
+var ErrAdjustmentDoesNotExist = fmt.Errorf("balance adjustment does not exist")
+var ErrAdjustmentIsNotPending = fmt.Errorf("balance adjustment is not pending")
+var ErrTransferLimitExceeded = fmt.Errorf("transfer limit exceeded")
+var ErrAdjustmentSelfApproval = fmt.Errorf("balance adjustment must be approved by another admin")
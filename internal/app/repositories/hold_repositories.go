@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+type HoldRepository struct {
+	db               *sqlx.DB
+	ledgerRepository *LedgerRepository
+}
+
+func NewHoldRepository(db *sqlx.DB, ledgerRepository *LedgerRepository) *HoldRepository {
+	return &HoldRepository{db: db, ledgerRepository: ledgerRepository}
+}
+
+// CreateHold переводит сумму резерва со счета доступных баллов пользователя на счет зарезервированных
+// резерв не должен превышать ограничений caps, как и списание
+func (r *HoldRepository) CreateHold(ctx context.Context, hold *domain.BalanceHold, caps []domain.WithdrawalCap) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkOrderOwner(ctx, tx, hold.Order, hold.UserID); err != nil {
+		return err
+	}
+	// заказ, уже оплаченный баллами, нельзя зарезервировать повторно
+	var withdrawalExists bool
+	query := `SELECT EXISTS (SELECT 1 FROM withdrawal WHERE order_number=$1)`
+	if err := tx.QueryRowContext(ctx, query, hold.Order).Scan(&withdrawalExists); err != nil {
+		return err
+	}
+	if withdrawalExists {
+		return ErrOrderAlreadyExists
+	}
+	if err := checkWithdrawalCaps(ctx, tx, hold.UserID, hold.Sum, caps); err != nil {
+		return err
+	}
+
+	hold.Status = domain.HoldActiveStatus
+	query = `INSERT INTO balance_hold (user_id, order_number, sum, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+	`
+	err = tx.QueryRowContext(
+		ctx, query, hold.UserID, hold.Order, hold.Sum, hold.Status, hold.CreatedAt, hold.ExpiresAt,
+	).Scan(&hold.ID)
+	// для заказа может существовать только один активный резерв
+	var pgErr pgx.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrOrderAlreadyExists
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	transaction := &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationHold,
+		Reference: hold.Order,
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountCurrent, UserID: hold.UserID, Amount: -hold.Sum},
+			{Account: domain.LedgerAccountHeld, UserID: hold.UserID, Amount: hold.Sum},
+		},
+	}
+	if err := r.ledgerRepository.PostTransaction(ctx, tx, transaction); err != nil {
+		return err
+	}
+	// запоминаем операцию, израсходовавшую партии баллов, чтобы при снятии резерва вернуть их прежние сроки сгорания
+	hold.LotsTransactionID = transaction.ID
+	query = `UPDATE balance_hold SET lots_transaction_id = $1 WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, hold.LotsTransactionID, hold.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CaptureHold подтверждает активный резерв пользователя: зарезервированные баллы списываются в счет заказа
+func (r *HoldRepository) CaptureHold(ctx context.Context, userID int, holdID int, now time.Time) (*domain.BalanceHold, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, err := r.lockActiveHold(ctx, tx, userID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	// резерв с истекшим сроком уже нельзя подтвердить, даже если он еще не был снят
+	if !hold.ExpiresAt.After(now) {
+		return nil, ErrHoldIsNotActive
+	}
+
+	withdrawal := &domain.Withdrawal{
+		Order: hold.Order, Sum: hold.Sum, UserID: hold.UserID, LotsTransactionID: hold.LotsTransactionID,
+	}
+	if err := insertWithdrawal(ctx, tx, withdrawal); err != nil {
+		return nil, err
+	}
+	err = r.ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationCapture,
+		Reference: hold.Order,
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountHeld, UserID: hold.UserID, Amount: -hold.Sum},
+			{Account: domain.LedgerAccountWithdrawn, UserID: hold.UserID, Amount: hold.Sum},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveHold(ctx, tx, hold, domain.HoldCapturedStatus, now); err != nil {
+		return nil, err
+	}
+
+	return hold, tx.Commit()
+}
+
+// ReleaseHold снимает активный резерв и возвращает баллы на счет доступных
+// userID равный 0 означает, что резерв снимается системой независимо от владельца
+// вернувшиеся баллы сохраняют сроки сгорания партий, израсходованных при резервировании
+func (r *HoldRepository) ReleaseHold(
+	ctx context.Context, userID int, holdID int, status string, now time.Time,
+) (*domain.BalanceHold, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, err := r.lockActiveHold(ctx, tx, userID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	// резерв с истекшим сроком пользователь не может отменить, как и подтвердить: его снимает система
+	if status == domain.HoldVoidedStatus && !hold.ExpiresAt.After(now) {
+		return nil, ErrHoldIsNotActive
+	}
+
+	err = r.ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationRelease,
+		Reference: hold.Order,
+		// резерв возвращает уже начисленные баллы, поэтому их срок сгорания не продлевается
+		PreservesExpiry: true,
+		LotsSourceID:    hold.LotsTransactionID,
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountHeld, UserID: hold.UserID, Amount: -hold.Sum},
+			{Account: domain.LedgerAccountCurrent, UserID: hold.UserID, Amount: hold.Sum},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveHold(ctx, tx, hold, status, now); err != nil {
+		return nil, err
+	}
+
+	return hold, tx.Commit()
+}
+
+// GetExpiredHolds возвращает идентификаторы активных резервов, срок которых истек к моменту now,
+// в порядке возрастания, начиная с резерва, следующего за afterID
+func (r *HoldRepository) GetExpiredHolds(ctx context.Context, now time.Time, afterID int, limit int) ([]int, error) {
+	query := `SELECT id FROM balance_hold
+		WHERE status = $1 AND expires_at <= $2 AND id > $3
+		ORDER BY id
+		LIMIT $4
+	`
+
+	var holdIDs []int
+	if err := r.db.SelectContext(ctx, &holdIDs, query, domain.HoldActiveStatus, now, afterID, limit); err != nil {
+		return nil, err
+	}
+	return holdIDs, nil
+}
+
+// lockActiveHold блокирует резерв, чтобы его нельзя было одновременно подтвердить и снять
+func (r *HoldRepository) lockActiveHold(ctx context.Context, tx *sql.Tx, userID int, holdID int) (*domain.BalanceHold, error) {
+	var hold domain.BalanceHold
+	query := `SELECT id, user_id, order_number, sum, status, created_at, expires_at, COALESCE(lots_transaction_id, 0)
+		FROM balance_hold
+		WHERE id = $1 AND ($2 = 0 OR user_id = $2)
+		FOR UPDATE
+	`
+	err := tx.QueryRowContext(ctx, query, holdID, userID).Scan(
+		&hold.ID, &hold.UserID, &hold.Order, &hold.Sum, &hold.Status, &hold.CreatedAt, &hold.ExpiresAt,
+		&hold.LotsTransactionID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrHoldDoesNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != domain.HoldActiveStatus {
+		return nil, ErrHoldIsNotActive
+	}
+	return &hold, nil
+}
+
+// resolveHold переводит резерв в конечный статус
+func resolveHold(ctx context.Context, tx *sql.Tx, hold *domain.BalanceHold, status string, now time.Time) error {
+	hold.Status = status
+	hold.ResolvedAt = &now
+	query := `UPDATE balance_hold SET status = $1, resolved_at = $2 WHERE id = $3`
+	_, err := tx.ExecContext(ctx, query, hold.Status, hold.ResolvedAt, hold.ID)
+	return err
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"testing"
+	"time"
+)
+
+func TestHoldRepository_CaptureAndRelease(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, domain.NewMoney(100, 0))
+	ledgerRepository := NewLedgerRepository(db, 0)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+	holdRepository := NewHoldRepository(db, ledgerRepository)
+
+	now := time.Now()
+	newHold := func(order string, sum domain.Money) *domain.BalanceHold {
+		hold := &domain.BalanceHold{
+			UserID:    userID,
+			Order:     fmt.Sprintf("%d-%s", userID, order),
+			Sum:       sum,
+			CreatedAt: now,
+			ExpiresAt: now.Add(time.Minute),
+		}
+		require.NoError(t, holdRepository.CreateHold(ctx, hold, nil))
+		return hold
+	}
+	captured := newHold("captured", domain.NewMoney(30, 0))
+	voided := newHold("voided", domain.NewMoney(20, 0))
+
+	// на зарезервированную сумму нельзя оформить второй резерв
+	err := holdRepository.CreateHold(ctx, &domain.BalanceHold{
+		UserID: userID, Order: fmt.Sprintf("%d-big", userID), Sum: domain.NewMoney(60, 0),
+		CreatedAt: now, ExpiresAt: now.Add(time.Minute),
+	}, nil)
+	assert.ErrorIs(t, err, ErrCanNotWithdrawBalance)
+
+	balance, err := balanceRepository.GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(50, 0), balance.Current)
+	assert.Equal(t, domain.NewMoney(50, 0), balance.Held)
+
+	_, err = holdRepository.CaptureHold(ctx, userID, captured.ID, now)
+	require.NoError(t, err)
+	_, err = holdRepository.ReleaseHold(ctx, userID, voided.ID, domain.HoldVoidedStatus, now)
+	require.NoError(t, err)
+
+	// завершенный резерв нельзя подтвердить или отменить повторно
+	_, err = holdRepository.CaptureHold(ctx, userID, voided.ID, now)
+	assert.ErrorIs(t, err, ErrHoldIsNotActive)
+	_, err = holdRepository.ReleaseHold(ctx, userID, captured.ID, domain.HoldVoidedStatus, now)
+	assert.ErrorIs(t, err, ErrHoldIsNotActive)
+
+	balance, err = balanceRepository.GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(70, 0), balance.Current)
+	assert.Equal(t, domain.NewMoney(30, 0), balance.Withdrawn)
+	assert.Equal(t, domain.Money(0), balance.Held)
+
+	// просроченный резерв снимается системой
+	expired := newHold("expired", domain.NewMoney(10, 0))
+	holdIDs, err := holdRepository.GetExpiredHolds(ctx, now.Add(time.Hour), 0, 100)
+	require.NoError(t, err)
+	assert.Contains(t, holdIDs, expired.ID)
+	// просроченный резерв пользователь не может ни подтвердить, ни отменить
+	_, err = holdRepository.CaptureHold(ctx, userID, expired.ID, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrHoldIsNotActive)
+	_, err = holdRepository.ReleaseHold(ctx, userID, expired.ID, domain.HoldVoidedStatus, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrHoldIsNotActive)
+	_, err = holdRepository.ReleaseHold(ctx, 0, expired.ID, domain.HoldExpiredStatus, now.Add(time.Hour))
+	require.NoError(t, err)
+}
+
+func TestBalanceRepository_WithdrawBalanceForOrder_ActiveHold(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, domain.NewMoney(100, 0))
+	ledgerRepository := NewLedgerRepository(db, 0)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+	holdRepository := NewHoldRepository(db, ledgerRepository)
+
+	now := time.Now()
+	hold := &domain.BalanceHold{
+		UserID: userID, Order: fmt.Sprintf("%d-held", userID), Sum: domain.NewMoney(30, 0),
+		CreatedAt: now, ExpiresAt: now.Add(time.Minute),
+	}
+	require.NoError(t, holdRepository.CreateHold(ctx, hold, nil))
+
+	// заказ с активным резервом нельзя оплатить отдельным списанием
+	withdrawal := &domain.Withdrawal{Order: hold.Order, Sum: domain.NewMoney(30, 0), UserID: userID}
+	err := balanceRepository.WithdrawBalanceForOrder(ctx, withdrawal, nil)
+	assert.ErrorIs(t, err, ErrOrderAlreadyExists)
+
+	balance, err := balanceRepository.GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(70, 0), balance.Current)
+	assert.Equal(t, domain.NewMoney(30, 0), balance.Held)
+	assert.Equal(t, domain.Money(0), balance.Withdrawn)
+
+	// после снятия резерва заказ можно оплатить списанием
+	_, err = holdRepository.ReleaseHold(ctx, userID, hold.ID, domain.HoldVoidedStatus, now)
+	require.NoError(t, err)
+	require.NoError(t, balanceRepository.WithdrawBalanceForOrder(ctx, withdrawal, nil))
+}
+
+func TestHoldRepository_ReleaseHold_PreservesExpiry(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, 0)
+	ledgerRepository := NewLedgerRepository(db, time.Hour)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+	holdRepository := NewHoldRepository(db, ledgerRepository)
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	err = ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationAccrual,
+		Reference: fmt.Sprintf("%d-accrual", userID),
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountAccruals, Amount: -domain.NewMoney(30, 0)},
+			{Account: domain.LedgerAccountCurrent, UserID: userID, Amount: domain.NewMoney(30, 0)},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	before := time.Now().Add(2 * time.Hour)
+	accrued, err := balanceRepository.GetExpiringPoints(ctx, userID, before)
+	require.NoError(t, err)
+	require.Len(t, accrued, 1)
+
+	// резервируем и снимаем резерв: вернувшиеся баллы не должны получить новый срок сгорания
+	now := time.Now()
+	hold := &domain.BalanceHold{
+		UserID: userID, Order: fmt.Sprintf("%d-hold", userID), Sum: domain.NewMoney(20, 0),
+		CreatedAt: now, ExpiresAt: now.Add(time.Minute),
+	}
+	require.NoError(t, holdRepository.CreateHold(ctx, hold, nil))
+	_, err = holdRepository.ReleaseHold(ctx, userID, hold.ID, domain.HoldVoidedStatus, now)
+	require.NoError(t, err)
+
+	released, err := balanceRepository.GetExpiringPoints(ctx, userID, before)
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, domain.NewMoney(30, 0), released[0].Sum)
+	assert.True(t, accrued[0].ExpiresAt.Equal(released[0].ExpiresAt))
+
+	// баллы сгорают в исходный срок
+	expiredSum, err := ledgerRepository.ExpireUserPoints(ctx, userID, accrued[0].ExpiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(30, 0), expiredSum)
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+type IdempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// CreateKey сохраняет ключ идемпотентности пользователя вместе с отпечатком запроса
+// возвращает false, если действующий ключ с таким значением уже существует
+func (r *IdempotencyRepository) CreateKey(
+	ctx context.Context, userID int, key string, fingerprint string, expiresAt time.Time,
+) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// ключ с истекшим сроком действия можно использовать повторно
+	query := `DELETE FROM idempotency_key WHERE user_id=$1 AND key=$2 AND expires_at < $3`
+	if _, err := tx.ExecContext(ctx, query, userID, key, time.Now()); err != nil {
+		return false, err
+	}
+
+	query = `INSERT INTO idempotency_key (user_id, key, fingerprint, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, key) DO NOTHING
+	`
+	result, err := tx.ExecContext(ctx, query, userID, key, fingerprint, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, tx.Commit()
+}
+
+func (r *IdempotencyRepository) GetKey(ctx context.Context, userID int, key string) (*domain.IdempotencyRecord, error) {
+	query := `SELECT user_id, key, fingerprint, expires_at, status_code, content_type, response_body
+		FROM idempotency_key
+		WHERE user_id=$1 AND key=$2
+	`
+
+	record := &domain.IdempotencyRecord{}
+	var statusCode sql.NullInt32
+	var contentType sql.NullString
+	var body []byte
+	err := r.db.QueryRowContext(ctx, query, userID, key).Scan(
+		&record.UserID, &record.Key, &record.Fingerprint, &record.ExpiresAt, &statusCode, &contentType, &body,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrIdempotencyKeyDoesNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if statusCode.Valid {
+		record.Response = &domain.IdempotentResponse{
+			StatusCode:  int(statusCode.Int32),
+			ContentType: contentType.String,
+			Body:        body,
+		}
+	}
+
+	return record, nil
+}
+
+func (r *IdempotencyRepository) SaveResponse(
+	ctx context.Context, userID int, key string, response *domain.IdempotentResponse,
+) error {
+	query := `UPDATE idempotency_key SET status_code=$1, content_type=$2, response_body=$3
+		WHERE user_id=$4 AND key=$5
+	`
+	_, err := r.db.ExecContext(ctx, query, response.StatusCode, response.ContentType, response.Body, userID, key)
+	return err
+}
+
+func (r *IdempotencyRepository) DeleteKey(ctx context.Context, userID int, key string) error {
+	query := `DELETE FROM idempotency_key WHERE user_id=$1 AND key=$2`
+	_, err := r.db.ExecContext(ctx, query, userID, key)
+	return err
+}
+
+// DeleteExpiredKeys удаляет ключи, срок действия которых истек к моменту now, и возвращает их количество
+func (r *IdempotencyRepository) DeleteExpiredKeys(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_key WHERE expires_at < $1`
+	result, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,438 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+// userBalanceColumns колонки таблицы user_balance, в которых хранятся суммы на счетах пользователя
+var userBalanceColumns = map[string]string{
+	domain.LedgerAccountCurrent:   "current",
+	domain.LedgerAccountWithdrawn: "withdrawn",
+	domain.LedgerAccountHeld:      "held",
+}
+
+type LedgerRepository struct {
+	db *sqlx.DB
+	// pointsLifetime время, через которое сгорают начисленные баллы, 0 - баллы не сгорают
+	pointsLifetime time.Duration
+}
+
+func NewLedgerRepository(db *sqlx.DB, pointsLifetime time.Duration) *LedgerRepository {
+	return &LedgerRepository{db: db, pointsLifetime: pointsLifetime}
+}
+
+// PostTransaction записывает операцию в журнал в рамках транзакции tx
+// и изменяет на суммы записей сохраненные балансы пользователей
+// если после операции сумма на счете пользователя станет отрицательной, возвращает ErrCanNotWithdrawBalance
+//
+// каждое поступление на счет CURRENT сохраняется как отдельная партия баллов со своим сроком сгорания,
+// а списания со счета CURRENT расходуют партии, начиная с тех, что сгорают раньше
+// новый срок сгорания получают только начисления: при перемещении баллов пользователя (PreservesExpiry)
+// поступления сохраняют сроки израсходованных партий, поэтому срок нельзя продлить, гоняя баллы между счетами
+func (r *LedgerRepository) PostTransaction(ctx context.Context, tx *sql.Tx, transaction *domain.LedgerTransaction) error {
+	var total domain.Money
+	for _, entry := range transaction.Entries {
+		total += entry.Amount
+	}
+	if len(transaction.Entries) < 2 || total != 0 {
+		return ErrUnbalancedLedgerTransaction
+	}
+
+	transaction.CreatedAt = time.Now()
+	query := `INSERT INTO ledger_transaction (operation, reference, created_at) VALUES ($1, $2, $3) RETURNING id`
+	err := tx.QueryRowContext(
+		ctx, query, transaction.Operation, transaction.Reference, transaction.CreatedAt,
+	).Scan(&transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range transaction.Entries {
+		entry.TransactionID = transaction.ID
+		entry.CreatedAt = transaction.CreatedAt
+		query := `INSERT INTO ledger_entry (transaction_id, account, user_id, amount, created_at)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id
+		`
+		err := tx.QueryRowContext(
+			ctx, query, entry.TransactionID, entry.Account, nullableUserID(entry.UserID), entry.Amount, entry.CreatedAt,
+		).Scan(&entry.ID)
+		if err != nil {
+			return err
+		}
+
+		column, ok := userBalanceColumns[entry.Account]
+		if !ok {
+			continue
+		}
+		// обновляем сохраненный баланс, не допуская отрицательных сумм на счете
+		query = fmt.Sprintf(
+			`UPDATE user_balance SET %[1]s=%[1]s+$1 WHERE user_id=$2 AND %[1]s+$1 >= 0`, column,
+		)
+		result, err := tx.ExecContext(ctx, query, entry.Amount, entry.UserID)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrCanNotWithdrawBalance
+		}
+
+		if entry.Account != domain.LedgerAccountCurrent {
+			continue
+		}
+		switch {
+		case entry.Amount > 0 && transaction.PreservesExpiry:
+			sourceID := transaction.LotsSourceID
+			if sourceID == 0 {
+				sourceID = transaction.ID
+			}
+			err = r.restorePointsLots(ctx, tx, entry, sourceID)
+		case entry.Amount > 0:
+			err = r.createPointsLot(ctx, tx, entry, entry.Amount, r.expiresAt(entry.CreatedAt))
+		default:
+			err = r.consumePointsLots(ctx, tx, entry.UserID, transaction.ID, -entry.Amount)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expiresAt возвращает срок сгорания баллов, начисленных в момент creditedAt, nil - баллы не сгорают
+func (r *LedgerRepository) expiresAt(creditedAt time.Time) *time.Time {
+	if r.pointsLifetime <= 0 {
+		return nil
+	}
+	t := creditedAt.Add(r.pointsLifetime)
+	return &t
+}
+
+// createPointsLot сохраняет сумму amount из поступления entry как партию баллов со сроком сгорания expiresAt
+func (r *LedgerRepository) createPointsLot(
+	ctx context.Context, tx *sql.Tx, entry *domain.LedgerEntry, amount domain.Money, expiresAt *time.Time,
+) error {
+	query := `INSERT INTO points_lot (user_id, transaction_id, amount, remaining, credited_at, expires_at)
+		VALUES ($1, $2, $3, $3, $4, $5)
+	`
+	_, err := tx.ExecContext(ctx, query, entry.UserID, entry.TransactionID, amount, entry.CreatedAt, expiresAt)
+	return err
+}
+
+// restorePointsLots сохраняет поступление entry как партии со сроками сгорания партий,
+// израсходованных операцией sourceID и еще не возвращенных на счет
+// если израсходованных партий не хватает (например, для операций, записанных до учета расходования),
+// остаток сохраняется как новая партия
+func (r *LedgerRepository) restorePointsLots(
+	ctx context.Context, tx *sql.Tx, entry *domain.LedgerEntry, sourceID int64,
+) error {
+	query := `SELECT c.id, c.amount - c.restored, l.expires_at
+		FROM points_lot_consumption c
+		JOIN points_lot l ON l.id = c.lot_id
+		WHERE c.transaction_id = $1 AND c.restored < c.amount
+		ORDER BY l.expires_at NULLS LAST, c.id
+		FOR UPDATE OF c
+	`
+	rows, err := tx.QueryContext(ctx, query, sourceID)
+	if err != nil {
+		return err
+	}
+	type lotRestoration struct {
+		consumptionID int64
+		restored      domain.Money
+		expiresAt     *time.Time
+	}
+	var restorations []lotRestoration
+	sum := entry.Amount
+	for sum > 0 && rows.Next() {
+		var restoration lotRestoration
+		var available domain.Money
+		if err := rows.Scan(&restoration.consumptionID, &available, &restoration.expiresAt); err != nil {
+			rows.Close()
+			return err
+		}
+		restoration.restored = available
+		if restoration.restored > sum {
+			restoration.restored = sum
+		}
+		restorations = append(restorations, restoration)
+		sum -= restoration.restored
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, restoration := range restorations {
+		query := `UPDATE points_lot_consumption SET restored = restored + $1 WHERE id = $2`
+		if _, err := tx.ExecContext(ctx, query, restoration.restored, restoration.consumptionID); err != nil {
+			return err
+		}
+		if err := r.createPointsLot(ctx, tx, entry, restoration.restored, restoration.expiresAt); err != nil {
+			return err
+		}
+	}
+	if sum > 0 {
+		return r.createPointsLot(ctx, tx, entry, sum, r.expiresAt(entry.CreatedAt))
+	}
+
+	return nil
+}
+
+// consumePointsLots расходует партии баллов пользователя на сумму sum, начиная с партий, которые сгорают раньше
+// расходование запоминается за операцией transactionID, чтобы вернуть баллы на счет с прежними сроками сгорания,
+// при transactionID 0 расходование не запоминается
+// должен вызываться после обновления баланса пользователя, которое блокирует его строку в user_balance
+func (r *LedgerRepository) consumePointsLots(
+	ctx context.Context, tx *sql.Tx, userID int, transactionID int64, sum domain.Money,
+) error {
+	query := `SELECT id, remaining FROM points_lot
+		WHERE user_id = $1 AND remaining > 0
+		ORDER BY expires_at NULLS LAST, id
+		FOR UPDATE
+	`
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+	type lotConsumption struct {
+		id       int64
+		consumed domain.Money
+	}
+	var consumptions []lotConsumption
+	for sum > 0 && rows.Next() {
+		var id int64
+		var remaining domain.Money
+		if err := rows.Scan(&id, &remaining); err != nil {
+			rows.Close()
+			return err
+		}
+		consumed := remaining
+		if consumed > sum {
+			consumed = sum
+		}
+		consumptions = append(consumptions, lotConsumption{id: id, consumed: consumed})
+		sum -= consumed
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range consumptions {
+		query := `UPDATE points_lot SET remaining = remaining - $1 WHERE id = $2`
+		if _, err := tx.ExecContext(ctx, query, c.consumed, c.id); err != nil {
+			return err
+		}
+		if transactionID == 0 {
+			continue
+		}
+		query = `INSERT INTO points_lot_consumption (lot_id, transaction_id, amount) VALUES ($1, $2, $3)`
+		if _, err := tx.ExecContext(ctx, query, c.id, transactionID, c.consumed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetUsersWithExpiredPoints возвращает пользователей с id больше afterUserID,
+// у которых есть сгоревшие, но еще не списанные баллы
+func (r *LedgerRepository) GetUsersWithExpiredPoints(
+	ctx context.Context, now time.Time, afterUserID int, limit int,
+) ([]int, error) {
+	query := `SELECT DISTINCT user_id FROM points_lot
+		WHERE remaining > 0 AND expires_at <= $1 AND user_id > $2
+		ORDER BY user_id
+		LIMIT $3
+	`
+
+	var userIDs []int
+	if err := r.db.SelectContext(ctx, &userIDs, query, now, afterUserID, limit); err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// ExpireUserPoints списывает со счета пользователя баллы из партий, срок которых истек к моменту now
+// возвращает сумму сгоревших баллов
+func (r *LedgerRepository) ExpireUserPoints(ctx context.Context, userID int, now time.Time) (domain.Money, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// блокируем баланс пользователя до блокировки партий, как и при остальных операциях
+	query := `SELECT user_id FROM user_balance WHERE user_id = $1 FOR UPDATE`
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return 0, err
+	}
+
+	var expiredSum domain.Money
+	query = `SELECT COALESCE(SUM(remaining), 0) FROM points_lot WHERE user_id = $1 AND remaining > 0 AND expires_at <= $2`
+	if err := tx.QueryRowContext(ctx, query, userID, now).Scan(&expiredSum); err != nil {
+		return 0, err
+	}
+	if expiredSum == 0 {
+		return 0, nil
+	}
+
+	// сгоревшие партии сгорают раньше остальных, поэтому списание израсходует именно их
+	err = r.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationExpiration,
+		Reference: now.Format(time.RFC3339),
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountCurrent, UserID: userID, Amount: -expiredSum},
+			{Account: domain.LedgerAccountExpired, Amount: expiredSum},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return expiredSum, tx.Commit()
+}
+
+// GetBalanceMismatches находит пользователей, чьи сохраненные балансы не совпадают с балансами по журналу операций
+func (r *LedgerRepository) GetBalanceMismatches(ctx context.Context) ([]*domain.BalanceMismatch, error) {
+	query := `SELECT ub.user_id, ub.current, ub.withdrawn, ub.held,
+			COALESCE(l.current, 0), COALESCE(l.withdrawn, 0), COALESCE(l.held, 0)
+		FROM user_balance ub
+		LEFT JOIN (
+			SELECT user_id,
+				SUM(amount) FILTER (WHERE account = $1) AS current,
+				SUM(amount) FILTER (WHERE account = $2) AS withdrawn,
+				SUM(amount) FILTER (WHERE account = $3) AS held
+			FROM ledger_entry
+			WHERE user_id IS NOT NULL
+			GROUP BY user_id
+		) l ON l.user_id = ub.user_id
+		WHERE ub.current <> COALESCE(l.current, 0) OR ub.withdrawn <> COALESCE(l.withdrawn, 0)
+			OR ub.held <> COALESCE(l.held, 0)
+		ORDER BY ub.user_id
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx, query, domain.LedgerAccountCurrent, domain.LedgerAccountWithdrawn, domain.LedgerAccountHeld,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mismatches []*domain.BalanceMismatch
+	for rows.Next() {
+		var m domain.BalanceMismatch
+		err := rows.Scan(
+			&m.UserID, &m.Cached.Current, &m.Cached.Withdrawn, &m.Cached.Held,
+			&m.Ledger.Current, &m.Ledger.Withdrawn, &m.Ledger.Held,
+		)
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, &m)
+	}
+
+	return mismatches, rows.Err()
+}
+
+// GetUnbalancedTransactions возвращает идентификаторы операций, сумма записей которых не равна нулю
+func (r *LedgerRepository) GetUnbalancedTransactions(ctx context.Context) ([]int64, error) {
+	query := `SELECT transaction_id FROM ledger_entry GROUP BY transaction_id HAVING SUM(amount) <> 0 ORDER BY transaction_id`
+
+	var ids []int64
+	if err := r.db.SelectContext(ctx, &ids, query); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// RebuildUserBalance пересчитывает сохраненный баланс пользователя по журналу операций
+// строка баланса блокируется до подсчета сумм, чтобы одновременные начисления и списания
+// не потерялись между подсчетом и обновлением
+// остаток партий баллов выравнивается по новому текущему балансу
+func (r *LedgerRepository) RebuildUserBalance(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT user_id FROM user_balance WHERE user_id = $1 FOR UPDATE`
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return err
+	}
+
+	query = `UPDATE user_balance SET
+			current = (SELECT COALESCE(SUM(amount), 0) FROM ledger_entry WHERE user_id = $1 AND account = $2),
+			withdrawn = (SELECT COALESCE(SUM(amount), 0) FROM ledger_entry WHERE user_id = $1 AND account = $3),
+			held = (SELECT COALESCE(SUM(amount), 0) FROM ledger_entry WHERE user_id = $1 AND account = $4)
+		WHERE user_id = $1
+	`
+	_, err = tx.ExecContext(
+		ctx, query, userID, domain.LedgerAccountCurrent, domain.LedgerAccountWithdrawn, domain.LedgerAccountHeld,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := r.alignPointsLots(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// alignPointsLots приводит остаток партий баллов пользователя к его текущему балансу
+// лишние баллы списываются с партий, которые сгорают раньше, а недостающие сохраняются бессрочной партией,
+// так как срок их начисления неизвестен
+// должен вызываться после обновления баланса пользователя, которое блокирует его строку в user_balance
+func (r *LedgerRepository) alignPointsLots(ctx context.Context, tx *sql.Tx, userID int) error {
+	query := `SELECT ub.current, COALESCE((SELECT SUM(remaining) FROM points_lot WHERE user_id = $1), 0)
+		FROM user_balance ub WHERE ub.user_id = $1
+	`
+	var current, remaining domain.Money
+	err := tx.QueryRowContext(ctx, query, userID).Scan(&current, &remaining)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if current < 0 {
+		current = 0
+	}
+
+	if remaining > current {
+		return r.consumePointsLots(ctx, tx, userID, 0, remaining-current)
+	}
+	if remaining < current {
+		query := `INSERT INTO points_lot (user_id, amount, remaining, credited_at) VALUES ($1, $2, $2, $3)`
+		_, err := tx.ExecContext(ctx, query, userID, current-remaining, time.Now())
+		return err
+	}
+	return nil
+}
+
+// nullableUserID возвращает NULL для записей системных счетов
+func nullableUserID(userID int) interface{} {
+	if userID == 0 {
+		return nil
+	}
+	return userID
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"testing"
+	"time"
+)
+
+func TestLedgerRepository_ExpireUserPoints(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, 0)
+	ledgerRepository := NewLedgerRepository(db, time.Hour)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+
+	// начисляем две партии баллов
+	for i := 0; i < 2; i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		err = ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+			Operation: domain.LedgerOperationAccrual,
+			Reference: fmt.Sprintf("%d-accrual-%d", userID, i),
+			Entries: []*domain.LedgerEntry{
+				{Account: domain.LedgerAccountAccruals, Amount: -domain.NewMoney(30, 0)},
+				{Account: domain.LedgerAccountCurrent, UserID: userID, Amount: domain.NewMoney(30, 0)},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+	}
+
+	// списание расходует сначала первую партию
+	err := balanceRepository.WithdrawBalanceForOrder(ctx, &domain.Withdrawal{
+		Order: fmt.Sprintf("%d-withdrawal", userID), Sum: domain.NewMoney(40, 0), UserID: userID,
+	}, nil)
+	require.NoError(t, err)
+
+	expiring, err := balanceRepository.GetExpiringPoints(ctx, userID, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	assert.Equal(t, domain.NewMoney(20, 0), expiring[0].Sum)
+
+	// по истечении срока сгорает остаток второй партии
+	expiredSum, err := ledgerRepository.ExpireUserPoints(ctx, userID, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(20, 0), expiredSum)
+
+	balance, err := balanceRepository.GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Money(0), balance.Current)
+	assert.Equal(t, domain.NewMoney(40, 0), balance.Withdrawn)
+}
+
+func TestLedgerRepository_RebuildUserBalance(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, domain.NewMoney(100, 0))
+	ledgerRepository := NewLedgerRepository(db, 0)
+
+	// портим сохраненный баланс, журнал операций остается прежним
+	_, err := db.ExecContext(ctx, `UPDATE user_balance SET current = 1, withdrawn = 2 WHERE user_id = $1`, userID)
+	require.NoError(t, err)
+
+	require.NoError(t, ledgerRepository.RebuildUserBalance(ctx, userID))
+
+	balance, err := NewBalanceRepository(db, ledgerRepository).GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(100, 0), balance.Current)
+	assert.Equal(t, domain.Money(0), balance.Withdrawn)
+}
+
+func TestLedgerRepository_RebuildUserBalance_AlignsPointsLots(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, domain.NewMoney(100, 0))
+	ledgerRepository := NewLedgerRepository(db, 0)
+	lotsRemaining := func() domain.Money {
+		var remaining domain.Money
+		err := db.GetContext(ctx, &remaining, `SELECT COALESCE(SUM(remaining), 0) FROM points_lot WHERE user_id = $1`, userID)
+		require.NoError(t, err)
+		return remaining
+	}
+
+	// лишняя партия списывается при пересчете
+	_, err := db.ExecContext(
+		ctx, `INSERT INTO points_lot (user_id, amount, remaining, credited_at) VALUES ($1, 50, 50, now())`, userID,
+	)
+	require.NoError(t, err)
+	require.NoError(t, ledgerRepository.RebuildUserBalance(ctx, userID))
+	assert.Equal(t, domain.NewMoney(100, 0), lotsRemaining())
+
+	// недостающие баллы сохраняются бессрочной партией
+	_, err = db.ExecContext(ctx, `UPDATE points_lot SET remaining = 0 WHERE user_id = $1`, userID)
+	require.NoError(t, err)
+	require.NoError(t, ledgerRepository.RebuildUserBalance(ctx, userID))
+	assert.Equal(t, domain.NewMoney(100, 0), lotsRemaining())
+
+	// перенос баллов в партии при запуске больше не выполняется и не создает партии для расхождений
+	_, err = db.ExecContext(ctx, `UPDATE points_lot SET remaining = 0 WHERE user_id = $1`, userID)
+	require.NoError(t, err)
+	require.NoError(t, createSchema(db))
+	assert.Equal(t, domain.Money(0), lotsRemaining())
+}
@@ -18,28 +18,48 @@ func NewOrderRepository(db *sqlx.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
+// GetOrCreateOrder создает заказ вместе с заданием на его обработку системой расчета баллов
+// если заказ с таким номером уже есть, то возвращает его и false
 func (r *OrderRepository) GetOrCreateOrder(ctx context.Context, orderToCreate domain.OrderDTO) (*domain.OrderDTO, bool, error) {
-	query := `INSERT INTO user_order (number, uploaded_at, user_id) VALUES ($1, $2, $3) RETURNING *`
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	// списание по этому номеру не должно проверить владельца, пока заказ загружается
+	if err := lockOrderNumber(ctx, tx, orderToCreate.Number); err != nil {
+		return nil, false, err
+	}
+
+	query := `INSERT INTO user_order (number, uploaded_at, user_id) VALUES ($1, $2, $3)
+		RETURNING number, uploaded_at, user_id, status, accrual
+	`
 	var order domain.OrderDTO
-	err := r.db.QueryRowxContext(
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		&orderToCreate.Number,
 		&orderToCreate.UploadedAt,
 		&orderToCreate.UserID,
-	).StructScan(&order)
+	).Scan(&order.Number, &order.UploadedAt, &order.UserID, &order.Status, &order.Accrual)
 	var pgErr pgx.PgError
-	if errors.As(err, &pgErr) {
-		if pgErr.Code == pgerrcode.UniqueViolation {
-			query := `SELECT * FROM user_order WHERE number=$1`
-			if err := r.db.QueryRowxContext(ctx, query, &orderToCreate.Number).StructScan(&order); err != nil {
-				return nil, false, err
-			}
-			return &order, false, nil
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		query := `SELECT * FROM user_order WHERE number=$1`
+		if err := r.db.QueryRowxContext(ctx, query, &orderToCreate.Number).StructScan(&order); err != nil {
+			return nil, false, err
 		}
+		return &order, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := insertAccrualJob(ctx, tx, order.Number, orderToCreate.UploadedAt); err != nil {
+		return nil, false, err
 	}
 
-	return &order, true, nil
+	return &order, true, tx.Commit()
 }
 
 func (r *OrderRepository) GetOrdersByUser(ctx context.Context, user *domain.UserDTO) ([]*domain.OrderDTO, error) {
@@ -70,53 +90,52 @@ func (r *OrderRepository) GetOrdersByUser(ctx context.Context, user *domain.User
 	return orders, nil
 }
 
+// UpdateOrderStatusAndAccrual обновляет статус и начисление заказа
+// заказ с окончательным статусом не меняется, и возвращается ErrOrderStatusIsFinal,
+// поэтому один и тот же результат расчета нельзя применить к заказу дважды
+// для несуществующего заказа возвращается ErrOrderDoesNotExist
 func (r *OrderRepository) UpdateOrderStatusAndAccrual(
 	ctx context.Context,
 	orderNumber string,
 	orderStatus string,
-	accrual float32,
+	accrual domain.Money,
 	tx *sql.Tx,
 ) error {
-	query := `UPDATE user_order SET status=$1, accrual=$2 WHERE number=$3`
+	query := `UPDATE user_order SET status=$1, accrual=$2 WHERE number=$3 AND status NOT IN ($4, $5)`
+	args := []interface{}{&orderStatus, &accrual, &orderNumber, domain.OrderProcessedStatus, domain.OrderInvalidStatus}
+	var res sql.Result
 	var err error
 	if tx != nil {
-		_, err = tx.ExecContext(ctx, query, &orderStatus, &accrual, &orderNumber)
+		res, err = tx.ExecContext(ctx, query, args...)
 	} else {
-		_, err = r.db.ExecContext(ctx, query, &orderStatus, &accrual, &orderNumber)
+		res, err = r.db.ExecContext(ctx, query, args...)
 	}
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (r *OrderRepository) GetOrdersWithStatusesIn(ctx context.Context, statuses []string) ([]*domain.OrderDTO, error) {
-	query := `SELECT * FROM user_order WHERE status IN (?)`
-	query, args, err := sqlx.In(query, statuses)
+	updated, err := res.RowsAffected()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	query = r.db.Rebind(query)
-	rows, err := r.db.QueryxContext(ctx, query, args...)
-	if err != nil {
-		return []*domain.OrderDTO{}, err
+	if updated > 0 {
+		return nil
 	}
 
-	var orders []*domain.OrderDTO
-	for rows.Next() {
-		var order domain.OrderDTO
-		err := rows.StructScan(&order)
-		if err != nil {
-			return nil, err
-		}
-		orders = append(orders, &order)
+	// заказ не обновлен: либо у него уже окончательный статус, либо его нет, заказы не удаляются,
+	// поэтому проверка после обновления однозначно различает эти случаи
+	var exists bool
+	query = `SELECT EXISTS (SELECT 1 FROM user_order WHERE number=$1)`
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, query, orderNumber).Scan(&exists)
+	} else {
+		err = r.db.QueryRowContext(ctx, query, orderNumber).Scan(&exists)
 	}
-
-	err = rows.Err()
 	if err != nil {
-		return orders, err
+		return err
 	}
-
-	return orders, nil
+	if !exists {
+		return ErrOrderDoesNotExist
+	}
+	return ErrOrderStatusIsFinal
 }
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"testing"
+	"time"
+)
+
+func TestUserRepository_IncreaseBalanceAndUpdateOrderStatus_Idempotent(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, 0)
+	orderRepository := NewOrderRepository(db)
+	ledgerRepository := NewLedgerRepository(db, 0)
+	userRepository := NewUserRepository(db, orderRepository, ledgerRepository)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+
+	orderNumber := fmt.Sprintf("%d", time.Now().UnixNano())
+	_, _, err := orderRepository.GetOrCreateOrder(ctx, domain.OrderDTO{Number: orderNumber, UploadedAt: time.Now(), UserID: userID})
+	require.NoError(t, err)
+
+	accrual := domain.NewMoney(100, 0)
+	err = userRepository.IncreaseBalanceAndUpdateOrderStatus(ctx, orderNumber, accrual, domain.OrderProcessedStatus, nil)
+	require.NoError(t, err)
+	// повторная обработка заказа, например другим экземпляром сервиса, не начисляет баллы второй раз
+	err = userRepository.IncreaseBalanceAndUpdateOrderStatus(ctx, orderNumber, accrual, domain.OrderProcessedStatus, nil)
+	assert.ErrorIs(t, err, ErrOrderStatusIsFinal)
+	// и не возвращает обработанный заказ в прежний статус
+	err = orderRepository.UpdateOrderStatusAndAccrual(ctx, orderNumber, domain.OrderProcessingStatus, 0, nil)
+	assert.ErrorIs(t, err, ErrOrderStatusIsFinal)
+
+	// несуществующий заказ не путается с заказом, у которого уже окончательный статус
+	missingOrderNumber := orderNumber + "-missing"
+	err = orderRepository.UpdateOrderStatusAndAccrual(ctx, missingOrderNumber, domain.OrderProcessingStatus, 0, nil)
+	assert.ErrorIs(t, err, ErrOrderDoesNotExist)
+	err = userRepository.IncreaseBalanceAndUpdateOrderStatus(ctx, missingOrderNumber, accrual, domain.OrderProcessedStatus, nil)
+	assert.ErrorIs(t, err, ErrOrderDoesNotExist)
+
+	balance, err := balanceRepository.GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, accrual, balance.Current)
+}
+
+func TestUserRepository_IncreaseBalanceAndUpdateOrderStatus_TierMultiplier(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	userID := createTestUser(t, db, 0)
+	orderRepository := NewOrderRepository(db)
+	ledgerRepository := NewLedgerRepository(db, 0)
+	userRepository := NewUserRepository(db, orderRepository, ledgerRepository)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+	tierRepository := NewTierRepository(db)
+
+	tiers := domain.Tiers{{Name: domain.TierGold, Threshold: domain.NewMoney(5000, 0), Multiplier: 1.25}}
+	record := &domain.UserTierRecord{UserID: userID, Tier: domain.TierGold, CalculatedAt: time.Now()}
+	require.NoError(t, tierRepository.SaveUserTier(ctx, record))
+
+	orderNumber := fmt.Sprintf("%d", time.Now().UnixNano())
+	_, _, err := orderRepository.GetOrCreateOrder(ctx, domain.OrderDTO{Number: orderNumber, UploadedAt: time.Now(), UserID: userID})
+	require.NoError(t, err)
+
+	// начисление умножается на множитель уровня, прочитанного в той же транзакции
+	err = userRepository.IncreaseBalanceAndUpdateOrderStatus(
+		ctx, orderNumber, domain.NewMoney(100, 0), domain.OrderProcessedStatus, tiers,
+	)
+	require.NoError(t, err)
+
+	balance, err := balanceRepository.GetUserBalance(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(125, 0), balance.Current)
+	orders, err := orderRepository.GetOrdersByUser(ctx, &domain.UserDTO{ID: userID})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, domain.NewMoney(125, 0), orders[0].Accrual)
+
+	// для уровня учитывается начисление без множителя, иначе уровень сам увеличивал бы баллы для своего расчета
+	earned, err := tierRepository.GetUserEarnedPoints(ctx, userID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(100, 0), earned)
+	records, err := tierRepository.GetEarnedPoints(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	for _, r := range records {
+		if r.UserID == userID {
+			assert.Equal(t, domain.NewMoney(100, 0), r.Earned)
+		}
+	}
+}
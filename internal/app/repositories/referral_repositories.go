@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+type ReferralRepository struct {
+	db               *sqlx.DB
+	ledgerRepository *LedgerRepository
+}
+
+func NewReferralRepository(db *sqlx.DB, ledgerRepository *LedgerRepository) *ReferralRepository {
+	return &ReferralRepository{db: db, ledgerRepository: ledgerRepository}
+}
+
+// createReferral сохраняет приглашение пользователя referredID по реферальному коду в рамках транзакции tx
+func createReferral(ctx context.Context, tx *sql.Tx, referralCode string, referredID int) error {
+	var referrerID int
+	query := `SELECT id FROM auth_user WHERE referral_code = $1`
+	err := tx.QueryRowContext(ctx, query, referralCode).Scan(&referrerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrReferralCodeDoesNotExist
+	}
+	if err != nil {
+		return err
+	}
+
+	query = `INSERT INTO referral (referrer_id, referred_id, status, created_at) VALUES ($1, $2, $3, $4)`
+	_, err = tx.ExecContext(ctx, query, referrerID, referredID, domain.ReferralPendingStatus, time.Now())
+	return err
+}
+
+// GetReferrals возвращает пользователей, приглашенных пользователем referrerID
+func (r *ReferralRepository) GetReferrals(ctx context.Context, referrerID int) ([]*domain.Referral, error) {
+	query := `SELECT r.id, r.referrer_id, r.referred_id, u.login, r.status, r.bonus, r.created_at, r.rewarded_at
+		FROM referral r
+		JOIN auth_user u ON u.id = r.referred_id
+		WHERE r.referrer_id = $1
+		ORDER BY r.created_at
+	`
+
+	var referrals []*domain.Referral
+	if err := r.db.SelectContext(ctx, &referrals, query, referrerID); err != nil {
+		return nil, err
+	}
+	return referrals, nil
+}
+
+// RewardReferral начисляет бонус bonus приглашенному владельцу заказа и пригласившему его пользователю
+// бонус начисляется один раз, при первом обработанном заказе приглашенного пользователя с положительным начислением
+// если пригласивший пользователь уже получил maxRewards бонусов, бонус не начисляется никому
+// возвращает nil, если владелец заказа не был приглашен, бонус уже был начислен или за заказ не начислено баллов,
+// и ErrSelfReferral, если пользователь пригласил сам себя
+func (r *ReferralRepository) RewardReferral(
+	ctx context.Context, orderNumber string, bonus domain.Money, maxRewards int,
+) (*domain.Referral, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var referral domain.Referral
+	query := `SELECT r.id, r.referrer_id, r.referred_id FROM referral r
+		JOIN user_order o ON o.user_id = r.referred_id
+		WHERE o.number = $1 AND o.status = $2 AND o.accrual > 0 AND r.status = $3
+		FOR UPDATE OF r
+	`
+	err = tx.QueryRowContext(ctx, query, orderNumber, domain.OrderProcessedStatus, domain.ReferralPendingStatus).Scan(
+		&referral.ID, &referral.ReferrerID, &referral.ReferredID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if referral.ReferrerID == referral.ReferredID {
+		return nil, ErrSelfReferral
+	}
+
+	// блокируем пригласившего пользователя, чтобы параллельные начисления не превысили ограничение
+	query = `SELECT id FROM auth_user WHERE id = $1 FOR UPDATE`
+	if _, err := tx.ExecContext(ctx, query, referral.ReferrerID); err != nil {
+		return nil, err
+	}
+	var rewardedCount int
+	query = `SELECT COUNT(*) FROM referral WHERE referrer_id = $1 AND status = $2`
+	err = tx.QueryRowContext(ctx, query, referral.ReferrerID, domain.ReferralRewardedStatus).Scan(&rewardedCount)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	referral.RewardedAt = &now
+	referral.Status = domain.ReferralCapReachedStatus
+	if maxRewards == 0 || rewardedCount < maxRewards {
+		referral.Status = domain.ReferralRewardedStatus
+		referral.Bonus = bonus
+		err = r.ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+			Operation: domain.LedgerOperationReferral,
+			Reference: fmt.Sprintf("referral %d", referral.ID),
+			Entries: []*domain.LedgerEntry{
+				{Account: domain.LedgerAccountReferrals, Amount: -2 * bonus},
+				{Account: domain.LedgerAccountCurrent, UserID: referral.ReferrerID, Amount: bonus},
+				{Account: domain.LedgerAccountCurrent, UserID: referral.ReferredID, Amount: bonus},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query = `UPDATE referral SET status = $1, bonus = $2, rewarded_at = $3 WHERE id = $4`
+	_, err = tx.ExecContext(ctx, query, referral.Status, referral.Bonus, referral.RewardedAt, referral.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &referral, tx.Commit()
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"testing"
+	"time"
+)
+
+func TestReferralRepository_RewardReferral(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	ledgerRepository := NewLedgerRepository(db, 0)
+	userRepository := NewUserRepository(db, NewOrderRepository(db), ledgerRepository)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+	referralRepository := NewReferralRepository(db, ledgerRepository)
+
+	suffix := time.Now().UnixNano()
+	referralCode := fmt.Sprintf("R%d", suffix%1e15)
+	createUser := func(login string, user domain.UserDTO) *domain.UserDTO {
+		user.Login = fmt.Sprintf("%s_%d", login, suffix)
+		user.Password = "123"
+		require.NoError(t, userRepository.CreateUser(ctx, user))
+		created, err := userRepository.GetUserByLogin(ctx, user.Login)
+		require.NoError(t, err)
+		return created
+	}
+	createOrder := func(user *domain.UserDTO, accrual domain.Money) string {
+		number := fmt.Sprintf("%d-%d-%v", user.ID, suffix, accrual)
+		_, err := db.ExecContext(
+			ctx, `INSERT INTO user_order (number, uploaded_at, user_id, status, accrual) VALUES ($1, $2, $3, $4, $5)`,
+			number, time.Now(), user.ID, domain.OrderProcessedStatus, accrual,
+		)
+		require.NoError(t, err)
+		return number
+	}
+
+	referrer := createUser("referrer", domain.UserDTO{ReferralCode: referralCode})
+	assert.Equal(t, referralCode, referrer.ReferralCode)
+	err := userRepository.CreateUser(ctx, domain.UserDTO{
+		Login: fmt.Sprintf("unknown_code_%d", suffix), Password: "123", ReferrerCode: "UNKNOWN",
+	})
+	assert.ErrorIs(t, err, ErrReferralCodeDoesNotExist)
+
+	first := createUser("first", domain.UserDTO{ReferrerCode: referralCode})
+	second := createUser("second", domain.UserDTO{ReferrerCode: referralCode})
+	bonus := domain.NewMoney(100, 0)
+
+	// за заказ без начисления бонус не начисляется
+	referral, err := referralRepository.RewardReferral(ctx, createOrder(first, 0), bonus, 1)
+	require.NoError(t, err)
+	assert.Nil(t, referral)
+
+	// бонус начисляется обоим пользователям только один раз
+	firstOrder := createOrder(first, domain.NewMoney(10, 0))
+	referral, err = referralRepository.RewardReferral(ctx, firstOrder, bonus, 1)
+	require.NoError(t, err)
+	require.NotNil(t, referral)
+	assert.Equal(t, domain.ReferralRewardedStatus, referral.Status)
+	referral, err = referralRepository.RewardReferral(ctx, firstOrder, bonus, 1)
+	require.NoError(t, err)
+	assert.Nil(t, referral)
+
+	// ограничение количества бонусов пригласившего пользователя достигнуто
+	referral, err = referralRepository.RewardReferral(ctx, createOrder(second, domain.NewMoney(10, 0)), bonus, 1)
+	require.NoError(t, err)
+	require.NotNil(t, referral)
+	assert.Equal(t, domain.ReferralCapReachedStatus, referral.Status)
+
+	for userID, want := range map[int]domain.Money{referrer.ID: bonus, first.ID: bonus, second.ID: 0} {
+		balance, err := balanceRepository.GetUserBalance(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, want, balance.Current)
+	}
+
+	referrals, err := referralRepository.GetReferrals(ctx, referrer.ID)
+	require.NoError(t, err)
+	require.Len(t, referrals, 2)
+	assert.Equal(t, first.Login, referrals[0].Login)
+	assert.Equal(t, domain.ReferralRewardedStatus, referrals[0].Status)
+	assert.Equal(t, domain.ReferralCapReachedStatus, referrals[1].Status)
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+// earnedPointsOperations операции, баллы по которым учитываются при расчете уровня пользователя
+var earnedPointsOperations = []string{domain.LedgerOperationAccrual, domain.LedgerOperationCampaign}
+
+// earnedPointsAmount сумма записи журнала, учитываемая при расчете уровня
+// начисления за заказы учитываются без множителя уровня, иначе уровень увеличивал бы баллы, по которым он рассчитывается,
+// для заказов, обработанных до сохранения начисления без множителя, учитывается начисленная сумма
+const earnedPointsAmount = `CASE WHEN lt.operation = ? THEN COALESCE(o.base_accrual, le.amount) ELSE le.amount END`
+
+// earnedPointsOrderJoin присоединяет заказ к начислению по нему
+const earnedPointsOrderJoin = `LEFT JOIN user_order o ON lt.operation = ? AND o.number = lt.reference`
+
+type TierRepository struct {
+	db *sqlx.DB
+}
+
+func NewTierRepository(db *sqlx.DB) *TierRepository {
+	return &TierRepository{db: db}
+}
+
+// GetEarnedPoints возвращает баллы, заработанные каждым пользователем начиная с момента since
+func (r *TierRepository) GetEarnedPoints(ctx context.Context, since time.Time) ([]*domain.UserTierRecord, error) {
+	query, args, err := sqlx.In(`SELECT u.id AS user_id, COALESCE(e.earned, 0) AS earned
+		FROM auth_user u
+		LEFT JOIN (
+			SELECT le.user_id, SUM(`+earnedPointsAmount+`) AS earned
+			FROM ledger_entry le
+			JOIN ledger_transaction lt ON lt.id = le.transaction_id
+			`+earnedPointsOrderJoin+`
+			WHERE le.account = ? AND le.amount > 0 AND lt.operation IN (?) AND lt.created_at >= ?
+			GROUP BY le.user_id
+		) e ON e.user_id = u.id
+		ORDER BY u.id
+	`, domain.LedgerOperationAccrual, domain.LedgerOperationAccrual,
+		domain.LedgerAccountCurrent, earnedPointsOperations, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*domain.UserTierRecord
+	if err := r.db.SelectContext(ctx, &records, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetUserEarnedPoints возвращает баллы, заработанные пользователем начиная с момента since
+func (r *TierRepository) GetUserEarnedPoints(ctx context.Context, userID int, since time.Time) (domain.Money, error) {
+	query, args, err := sqlx.In(`SELECT COALESCE(SUM(`+earnedPointsAmount+`), 0)
+		FROM ledger_entry le
+		JOIN ledger_transaction lt ON lt.id = le.transaction_id
+		`+earnedPointsOrderJoin+`
+		WHERE le.user_id = ? AND le.account = ? AND le.amount > 0 AND lt.operation IN (?) AND lt.created_at >= ?
+	`, domain.LedgerOperationAccrual, domain.LedgerOperationAccrual,
+		userID, domain.LedgerAccountCurrent, earnedPointsOperations, since)
+	if err != nil {
+		return 0, err
+	}
+
+	var earned domain.Money
+	if err := r.db.QueryRowContext(ctx, r.db.Rebind(query), args...).Scan(&earned); err != nil {
+		return 0, err
+	}
+	return earned, nil
+}
+
+// SaveUserTier сохраняет рассчитанный уровень пользователя
+func (r *TierRepository) SaveUserTier(ctx context.Context, record *domain.UserTierRecord) error {
+	query := `INSERT INTO user_tier (user_id, tier, earned, calculated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET tier = $2, earned = $3, calculated_at = $4
+	`
+	_, err := r.db.ExecContext(ctx, query, record.UserID, record.Tier, record.Earned, record.CalculatedAt)
+	return err
+}
+
+// GetUserTier возвращает последний рассчитанный уровень пользователя
+// если уровень еще не рассчитывался, возвращает nil
+func (r *TierRepository) GetUserTier(ctx context.Context, userID int) (*domain.UserTierRecord, error) {
+	query := `SELECT user_id, tier, earned, calculated_at FROM user_tier WHERE user_id = $1`
+
+	var record domain.UserTierRecord
+	err := r.db.GetContext(ctx, &record, query, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
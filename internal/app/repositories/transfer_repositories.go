@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"strconv"
+	"time"
+)
+
+type TransferRepository struct {
+	db               *sqlx.DB
+	ledgerRepository *LedgerRepository
+}
+
+func NewTransferRepository(db *sqlx.DB, ledgerRepository *LedgerRepository) *TransferRepository {
+	return &TransferRepository{db: db, ledgerRepository: ledgerRepository}
+}
+
+// CreateTransfer переводит баллы со счета отправителя на счет получателя в одной транзакции
+// если dailyLimit больше нуля, сумма переводов отправителя за последние сутки вместе с новым переводом
+// не должна его превышать, проверка выполняется после блокировки баланса отправителя
+func (r *TransferRepository) CreateTransfer(ctx context.Context, transfer *domain.Transfer, dailyLimit domain.Money) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// блокируем балансы обоих пользователей в порядке возрастания id,
+	// чтобы встречные переводы не приводили к взаимной блокировке
+	query := `SELECT user_id FROM user_balance WHERE user_id IN ($1, $2) ORDER BY user_id FOR UPDATE`
+	rows, err := tx.QueryContext(ctx, query, transfer.SenderID, transfer.RecipientID)
+	if err != nil {
+		return err
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	transfer.CreatedAt = time.Now()
+	if dailyLimit > 0 {
+		transferredSum, err := getTransferredSumSince(ctx, tx, transfer.SenderID, transfer.CreatedAt.Add(-24*time.Hour))
+		if err != nil {
+			return err
+		}
+		if transferredSum+transfer.Sum > dailyLimit {
+			return ErrTransferLimitExceeded
+		}
+	}
+
+	query = `INSERT INTO transfer (sender_id, recipient_id, sum, note, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err = tx.QueryRowContext(
+		ctx, query, transfer.SenderID, transfer.RecipientID, transfer.Sum, transfer.Note, transfer.CreatedAt,
+	).Scan(&transfer.ID)
+	if err != nil {
+		return err
+	}
+
+	err = r.ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationTransfer,
+		Reference: strconv.Itoa(transfer.ID),
+		// получатель получает баллы со сроками сгорания партий отправителя
+		PreservesExpiry: true,
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountCurrent, UserID: transfer.SenderID, Amount: -transfer.Sum},
+			{Account: domain.LedgerAccountCurrent, UserID: transfer.RecipientID, Amount: transfer.Sum},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// getTransferredSumSince возвращает сумму переводов, отправленных пользователем начиная с момента since
+func getTransferredSumSince(
+	ctx context.Context, tx *sql.Tx, senderID int, since time.Time,
+) (domain.Money, error) {
+	query := `SELECT COALESCE(SUM(sum), 0) FROM transfer WHERE sender_id = $1 AND created_at >= $2`
+
+	var sum domain.Money
+	if err := tx.QueryRowContext(ctx, query, senderID, since).Scan(&sum); err != nil {
+		return 0, err
+	}
+	return sum, nil
+}
+
+// GetTransfersByUser возвращает переводы, отправленные и полученные пользователем
+func (r *TransferRepository) GetTransfersByUser(ctx context.Context, userID int) ([]*domain.Transfer, error) {
+	query := `SELECT t.id, t.sender_id, t.recipient_id, s.login AS sender, rc.login AS recipient, t.sum, t.note, t.created_at
+		FROM transfer t
+		JOIN auth_user s ON s.id = t.sender_id
+		JOIN auth_user rc ON rc.id = t.recipient_id
+		WHERE t.sender_id = $1 OR t.recipient_id = $1
+		ORDER BY t.created_at
+	`
+
+	var transfers []*domain.Transfer
+	if err := r.db.SelectContext(ctx, &transfers, query, userID); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"sync"
+	"testing"
+)
+
+func TestTransferRepository_CreateTransfer_ConcurrentDailyLimit(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	senderID := createTestUser(t, db, domain.NewMoney(1000, 0))
+	recipientID := createTestUser(t, db, 0)
+	transferRepository := NewTransferRepository(db, NewLedgerRepository(db, 0))
+	dailyLimit := domain.NewMoney(100, 0)
+
+	// параллельные переводы не должны вместе превысить дневной лимит отправителя
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created, limited := 0, 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			transfer := &domain.Transfer{SenderID: senderID, RecipientID: recipientID, Sum: domain.NewMoney(40, 0)}
+			err := transferRepository.CreateTransfer(ctx, transfer, dailyLimit)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created++
+			case errors.Is(err, ErrTransferLimitExceeded):
+				limited++
+			default:
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 2, created)
+	assert.Equal(t, 3, limited)
+
+	balance, err := NewBalanceRepository(db, NewLedgerRepository(db, 0)).GetUserBalance(ctx, senderID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(920, 0), balance.Current)
+}
@@ -7,17 +7,17 @@ import (
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx"
 	"github.com/jmoiron/sqlx"
-	"github.com/rs/zerolog/log"
 	"gophermart/internal/app/domain"
 )
 
 type UserRepository struct {
-	db              *sqlx.DB
-	orderRepository *OrderRepository
+	db               *sqlx.DB
+	orderRepository  *OrderRepository
+	ledgerRepository *LedgerRepository
 }
 
-func NewUserRepository(db *sqlx.DB, repository *OrderRepository) *UserRepository {
-	return &UserRepository{db: db, orderRepository: repository}
+func NewUserRepository(db *sqlx.DB, repository *OrderRepository, ledgerRepository *LedgerRepository) *UserRepository {
+	return &UserRepository{db: db, orderRepository: repository, ledgerRepository: ledgerRepository}
 }
 
 func (r *UserRepository) CreateUser(ctx context.Context, user domain.UserDTO) error {
@@ -29,14 +29,17 @@ func (r *UserRepository) CreateUser(ctx context.Context, user domain.UserDTO) er
 
 	// создаем пользователя и получаем его id
 	var createdUserID int
-	query := `INSERT INTO auth_user (login, password) VALUES ($1, $2) RETURNING id`
-	err = r.db.QueryRowxContext(ctx, query, user.Login, user.Password).Scan(&createdUserID)
+	query := `INSERT INTO auth_user (login, password, referral_code) VALUES ($1, $2, NULLIF($3, '')) RETURNING id`
+	err = tx.QueryRowContext(ctx, query, user.Login, user.Password, user.ReferralCode).Scan(&createdUserID)
 
 	var pgErr pgx.PgError
 	if errors.As(err, &pgErr) {
-		if pgErr.Code == pgerrcode.UniqueViolation {
+		if pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == "login_unique" {
 			return ErrUserAlreadyExists
 		}
+		if pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == "auth_user_referral_code_idx" {
+			return ErrReferralCodeAlreadyExists
+		}
 	}
 	if err != nil {
 		return err
@@ -44,16 +47,23 @@ func (r *UserRepository) CreateUser(ctx context.Context, user domain.UserDTO) er
 
 	// создаем для пользователя также строку в таблице баланса
 	query = `INSERT INTO user_balance (user_id) values ($1)`
-	_, err = r.db.ExecContext(ctx, query, createdUserID)
+	_, err = tx.ExecContext(ctx, query, createdUserID)
 	if err != nil {
 		return err
 	}
 
+	if user.ReferrerCode != "" {
+		if err := createReferral(ctx, tx, user.ReferrerCode, createdUserID); err != nil {
+			return err
+		}
+	}
+
 	return tx.Commit()
 }
 
 func (r *UserRepository) GetUserByLogin(ctx context.Context, login string) (*domain.UserDTO, error) {
-	query := `SELECT id, login, password FROM auth_user WHERE login=$1`
+	query := `SELECT id, login, password, is_admin, COALESCE(referral_code, '') AS referral_code
+		FROM auth_user WHERE login=$1`
 	var existingUser domain.UserDTO
 	err := r.db.QueryRowxContext(ctx, query, login).StructScan(&existingUser)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -66,8 +76,27 @@ func (r *UserRepository) GetUserByLogin(ctx context.Context, login string) (*dom
 	return &existingUser, nil
 }
 
+// ChangePassword сохраняет новый хэш пароля пользователя и время смены пароля
+func (r *UserRepository) ChangePassword(ctx context.Context, userID int, password string) error {
+	query := `UPDATE auth_user SET password = $2, password_changed_at = now() WHERE id = $1`
+	res, err := r.db.ExecContext(ctx, query, userID, password)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrUserDoesNotExist
+	}
+	return nil
+}
+
+// IncreaseBalanceAndUpdateOrderStatus начисляет баллы за заказ, умноженные на множитель уровня владельца заказа,
+// и обновляет статус заказа
 func (r *UserRepository) IncreaseBalanceAndUpdateOrderStatus(
-	ctx context.Context, orderNumber string, accrual float32, orderStatus string,
+	ctx context.Context, orderNumber string, accrual domain.Money, orderStatus string, tiers domain.Tiers,
 ) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -75,24 +104,48 @@ func (r *UserRepository) IncreaseBalanceAndUpdateOrderStatus(
 	}
 	defer tx.Rollback()
 
-	// находим пользователя, для которого сущесвует заказ
-	// и прибавляем ему баланс
-	query := `UPDATE user_balance SET current=current+$1
-		WHERE user_id = (SELECT user_id FROM user_order WHERE number = $2)
-	`
-	result, err := tx.ExecContext(ctx, query, accrual, orderNumber)
+	// находим пользователя, для которого сущесвует заказ, и его уровень
+	// уровень блокируется до конца транзакции, поэтому пересчет уровней не изменит его
+	// между расчетом начисления и записью в журнал
+	var userID int
+	query := `SELECT user_id FROM user_order WHERE number = $1`
+	err = tx.QueryRowContext(ctx, query, orderNumber).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrOrderDoesNotExist
+	}
 	if err != nil {
 		return err
 	}
-	rows, err := result.RowsAffected()
+	tierName := domain.TierBasic
+	query = `SELECT tier FROM user_tier WHERE user_id = $1 FOR SHARE`
+	err = tx.QueryRowContext(ctx, query, userID).Scan(&tierName)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	baseAccrual := accrual
+	accrual = accrual.Multiply(tiers.Get(tierName).Multiplier)
+
+	// меняем статус заказа: обновление блокирует заказ и не проходит, если заказ уже обработан,
+	// поэтому параллельная обработка того же заказа не начислит баллы второй раз
+	err = r.orderRepository.UpdateOrderStatusAndAccrual(ctx, orderNumber, orderStatus, accrual, tx)
 	if err != nil {
 		return err
 	}
-	if rows != 1 {
-		log.Error().Msg("increase user balance: expected one row to be affected")
+	// начисление без множителя уровня учитывается при расчете уровня
+	query = `UPDATE user_order SET base_accrual = $1 WHERE number = $2`
+	if _, err := tx.ExecContext(ctx, query, baseAccrual, orderNumber); err != nil {
+		return err
 	}
 
-	err = r.orderRepository.UpdateOrderStatusAndAccrual(ctx, orderNumber, orderStatus, accrual, tx)
+	// записываем в журнал начисление баллов на счет пользователя
+	err = r.ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationAccrual,
+		Reference: orderNumber,
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountAccruals, Amount: -accrual},
+			{Account: domain.LedgerAccountCurrent, UserID: userID, Amount: accrual},
+		},
+	})
 	if err != nil {
 		return err
 	}
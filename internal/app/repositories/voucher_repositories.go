@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx"
+	"github.com/jmoiron/sqlx"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+type VoucherRepository struct {
+	db               *sqlx.DB
+	ledgerRepository *LedgerRepository
+}
+
+func NewVoucherRepository(db *sqlx.DB, ledgerRepository *LedgerRepository) *VoucherRepository {
+	return &VoucherRepository{db: db, ledgerRepository: ledgerRepository}
+}
+
+// CreateVoucherBatch сохраняет партию ваучеров вместе со всеми ее ваучерами
+func (r *VoucherRepository) CreateVoucherBatch(ctx context.Context, batch *domain.VoucherBatch) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	batch.CreatedAt = time.Now()
+	query := `INSERT INTO voucher_batch (value, expires_at, usage_limit, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id
+	`
+	err = tx.QueryRowContext(
+		ctx, query, batch.Value, batch.ExpiresAt, batch.UsageLimit, batch.CreatedBy, batch.CreatedAt,
+	).Scan(&batch.ID)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO voucher (batch_id, code) VALUES ($1, $2) RETURNING id`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, voucher := range batch.Vouchers {
+		voucher.BatchID = batch.ID
+		err = stmt.QueryRowContext(ctx, voucher.BatchID, voucher.Code).Scan(&voucher.ID)
+		var pgErr pgx.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return ErrVoucherCodeAlreadyExists
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetVoucherBatch возвращает партию ваучеров вместе с ее ваучерами
+func (r *VoucherRepository) GetVoucherBatch(ctx context.Context, batchID int) (*domain.VoucherBatch, error) {
+	query := `SELECT id, value, expires_at, usage_limit, created_by, created_at FROM voucher_batch WHERE id = $1`
+
+	var batch domain.VoucherBatch
+	err := r.db.GetContext(ctx, &batch, query, batchID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrVoucherBatchDoesNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT id, batch_id, code, used_count FROM voucher WHERE batch_id = $1 ORDER BY id`
+	if err := r.db.SelectContext(ctx, &batch.Vouchers, query, batchID); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// RedeemVoucher гасит ваучер с кодом code и начисляет его номинал на баланс пользователя
+// каждый пользователь может погасить ваучер только один раз
+func (r *VoucherRepository) RedeemVoucher(
+	ctx context.Context, code string, userID int, now time.Time,
+) (*domain.VoucherRedemption, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// блокируем ваучер, чтобы параллельные погашения не превысили ограничение использований
+	var usedCount, usageLimit int
+	var expiresAt time.Time
+	redemption := domain.VoucherRedemption{UserID: userID, RedeemedAt: now}
+	query := `SELECT v.id, v.used_count, b.usage_limit, b.expires_at, b.value
+		FROM voucher v
+		JOIN voucher_batch b ON b.id = v.batch_id
+		WHERE v.code = $1
+		FOR UPDATE OF v
+	`
+	err = tx.QueryRowContext(ctx, query, code).Scan(
+		&redemption.VoucherID, &usedCount, &usageLimit, &expiresAt, &redemption.Sum,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrVoucherDoesNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !now.Before(expiresAt) {
+		return nil, ErrVoucherExpired
+	}
+	if usedCount >= usageLimit {
+		return nil, ErrVoucherUsageLimitReached
+	}
+
+	query = `INSERT INTO voucher_redemption (voucher_id, user_id, sum, redeemed_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (voucher_id, user_id) DO NOTHING
+		RETURNING id
+	`
+	err = tx.QueryRowContext(
+		ctx, query, redemption.VoucherID, redemption.UserID, redemption.Sum, redemption.RedeemedAt,
+	).Scan(&redemption.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrVoucherAlreadyRedeemed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query = `UPDATE voucher SET used_count = used_count + 1 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, redemption.VoucherID); err != nil {
+		return nil, err
+	}
+
+	err = r.ledgerRepository.PostTransaction(ctx, tx, &domain.LedgerTransaction{
+		Operation: domain.LedgerOperationVoucher,
+		Reference: fmt.Sprintf("voucher %d", redemption.VoucherID),
+		Entries: []*domain.LedgerEntry{
+			{Account: domain.LedgerAccountVouchers, Amount: -redemption.Sum},
+			{Account: domain.LedgerAccountCurrent, UserID: userID, Amount: redemption.Sum},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &redemption, tx.Commit()
+}
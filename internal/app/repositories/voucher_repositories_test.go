@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"testing"
+	"time"
+)
+
+func TestVoucherRepository_RedeemVoucher(t *testing.T) {
+	db := initTestDB(t)
+	ctx := context.Background()
+
+	ledgerRepository := NewLedgerRepository(db, 0)
+	balanceRepository := NewBalanceRepository(db, ledgerRepository)
+	voucherRepository := NewVoucherRepository(db, ledgerRepository)
+
+	adminID := createTestUser(t, db, 0)
+	firstUserID := createTestUser(t, db, 0)
+	secondUserID := createTestUser(t, db, 0)
+	now := time.Now()
+	code := fmt.Sprintf("T%d", now.UnixNano())
+	batch := &domain.VoucherBatch{
+		Value:      domain.NewMoney(500, 0),
+		ExpiresAt:  now.Add(time.Hour),
+		UsageLimit: 1,
+		CreatedBy:  adminID,
+		Vouchers:   []*domain.Voucher{{Code: code}},
+	}
+	require.NoError(t, voucherRepository.CreateVoucherBatch(ctx, batch))
+
+	_, err := voucherRepository.RedeemVoucher(ctx, "UNKNOWN", firstUserID, now)
+	assert.ErrorIs(t, err, ErrVoucherDoesNotExist)
+	_, err = voucherRepository.RedeemVoucher(ctx, code, firstUserID, now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrVoucherExpired)
+
+	redemption, err := voucherRepository.RedeemVoucher(ctx, code, firstUserID, now)
+	require.NoError(t, err)
+	assert.Equal(t, batch.Value, redemption.Sum)
+
+	// ваучер погашается только один раз
+	_, err = voucherRepository.RedeemVoucher(ctx, code, firstUserID, now)
+	assert.ErrorIs(t, err, ErrVoucherAlreadyRedeemed)
+	_, err = voucherRepository.RedeemVoucher(ctx, code, secondUserID, now)
+	assert.ErrorIs(t, err, ErrVoucherUsageLimitReached)
+
+	balance, err := balanceRepository.GetUserBalance(ctx, firstUserID)
+	require.NoError(t, err)
+	assert.Equal(t, batch.Value, balance.Current)
+
+	exported, err := voucherRepository.GetVoucherBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	require.Len(t, exported.Vouchers, 1)
+	assert.Equal(t, 1, exported.Vouchers[0].UsedCount)
+}
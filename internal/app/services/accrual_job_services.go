@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+type AccrualJobRepository interface {
+	ClaimAccrualJobs(
+		ctx context.Context, owner string, now time.Time, lockedUntil time.Time, limit int,
+	) ([]*domain.AccrualJob, error)
+	RescheduleAccrualJob(ctx context.Context, job *domain.AccrualJob) error
+	DeleteAccrualJob(ctx context.Context, job *domain.AccrualJob) error
+	GetDeadLetteredAccrualJobs(ctx context.Context, limit int, offset int) ([]*domain.AccrualJob, error)
+	RequeueAccrualJob(ctx context.Context, jobID int64, adminID int, now time.Time) (*domain.AccrualJob, error)
+}
+
+const (
+	// accrualJobLease время, на которое воркер захватывает задание
+	// если воркер аварийно остановится, по его истечении задание достанется другому воркеру
+	accrualJobLease = 5 * time.Minute
+	// accrualJobOwnerLen длина токена захвата заданий
+	accrualJobOwnerLen = 16
+)
+
+// AccrualJobService выдает воркерам задания на обработку заказов из очереди в базе данных
+// каждый заказ опрашивается по своему расписанию: пауза между запросами результата расчета
+// и между повторными попытками после ошибок растет экспоненциально
+// после maxAttempts неудачных попыток подряд задание откладывается до решения администратора
+type AccrualJobService struct {
+	jobRepository AccrualJobRepository
+	pollBackoff   *Backoff
+	retryBackoff  *Backoff
+	maxAttempts   int
+	now           func() time.Time
+}
+
+// NewAccrualJobService создает сервис очереди заданий, maxAttempts равный 0 снимает ограничение на количество попыток
+func NewAccrualJobService(
+	jobRepository AccrualJobRepository, pollBackoff *Backoff, retryBackoff *Backoff, maxAttempts int,
+) *AccrualJobService {
+	return &AccrualJobService{
+		jobRepository: jobRepository,
+		pollBackoff:   pollBackoff,
+		retryBackoff:  retryBackoff,
+		maxAttempts:   maxAttempts,
+		now:           time.Now,
+	}
+}
+
+// ClaimJobs захватывает до limit заданий, время выполнения которых наступило
+// каждый захват получает свой токен, поэтому задание, захват которого истек,
+// не может изменить ни другой воркер, ни другой экземпляр сервиса
+func (s *AccrualJobService) ClaimJobs(ctx context.Context, limit int) ([]*domain.AccrualJob, error) {
+	owner, err := generateCode(accrualJobOwnerLen)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	return s.jobRepository.ClaimAccrualJobs(ctx, owner, now, now.Add(accrualJobLease), limit)
+}
+
+// PollJobLater переводит задание на этап запроса результата расчета и назначает следующий запрос
+// чем дольше заказ рассчитывается, тем реже запрашивается результат
+// задание выполнено успешно, поэтому счетчик неудачных попыток подряд сбрасывается
+func (s *AccrualJobService) PollJobLater(ctx context.Context, job *domain.AccrualJob) error {
+	job.Stage = domain.AccrualJobPollStage
+	job.Attempts = 0
+	job.NextAttemptAt = s.now().Add(s.pollBackoff.Delay(job.Polls))
+	job.Polls++
+	return s.jobRepository.RescheduleAccrualJob(ctx, job)
+}
+
+// RetryJob откладывает задание после ошибки cause, увеличивает счетчик неудачных попыток и запоминает ошибку
+// если попытки закончились, задание больше не выполняется, пока администратор не вернет его в очередь
+func (s *AccrualJobService) RetryJob(ctx context.Context, job *domain.AccrualJob, cause error) error {
+	now := s.now()
+	job.NextAttemptAt = now.Add(s.retryBackoff.Delay(job.Attempts))
+	job.Attempts++
+	job.LastError = cause.Error()
+	if s.maxAttempts > 0 && job.Attempts >= s.maxAttempts {
+		log.Error().Msg(fmt.Sprintf(
+			"order '%s' is dead-lettered after %d failed attempts: %v", job.OrderNumber, job.Attempts, job.LastError,
+		))
+		job.DeadLetteredAt = &now
+	}
+	return s.jobRepository.RescheduleAccrualJob(ctx, job)
+}
+
+// PostponeJob возвращает задание в очередь на прежнем этапе с паузой delay, не меняя счетчики попыток
+// используется, когда задание не выполнялось, например при остановке воркеров
+func (s *AccrualJobService) PostponeJob(ctx context.Context, job *domain.AccrualJob, delay time.Duration) error {
+	job.NextAttemptAt = s.now().Add(delay)
+	return s.jobRepository.RescheduleAccrualJob(ctx, job)
+}
+
+// CompleteJob удаляет задание, когда заказ получил окончательный статус
+func (s *AccrualJobService) CompleteJob(ctx context.Context, job *domain.AccrualJob) error {
+	return s.jobRepository.DeleteAccrualJob(ctx, job)
+}
+
+// GetDeadLetteredJobs возвращает задания, отложенные после слишком большого количества ошибок
+func (s *AccrualJobService) GetDeadLetteredJobs(ctx context.Context, limit int, offset int) ([]*domain.AccrualJob, error) {
+	return s.jobRepository.GetDeadLetteredAccrualJobs(ctx, limit, offset)
+}
+
+// RequeueJob возвращает отложенное задание в очередь по решению администратора adminID
+func (s *AccrualJobService) RequeueJob(ctx context.Context, jobID int64, adminID int) (*domain.AccrualJob, error) {
+	return s.jobRepository.RequeueAccrualJob(ctx, jobID, adminID, s.now())
+}
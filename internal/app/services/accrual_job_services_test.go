@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+	"time"
+)
+
+func TestAccrualJobService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	job := &domain.AccrualJob{ID: 1, OrderNumber: "123", Stage: domain.AccrualJobRegisterStage, Attempts: 2}
+
+	jobRepositoryMock := mock_services.NewMockAccrualJobRepository(ctrl)
+	// задания захватываются на время аренды
+	jobRepositoryMock.EXPECT().ClaimAccrualJobs(ctx, gomock.Any(), now, now.Add(accrualJobLease), 10).
+		Return([]*domain.AccrualJob{job}, nil)
+	jobRepositoryMock.EXPECT().RescheduleAccrualJob(ctx, job).Return(nil).Times(6)
+	jobRepositoryMock.EXPECT().DeleteAccrualJob(ctx, job).Return(nil)
+
+	pollBackoff := NewBackoff(time.Second, 4*time.Second)
+	pollBackoff.random = func() float64 { return 0 }
+	retryBackoff := NewBackoff(10*time.Second, time.Minute)
+	retryBackoff.random = func() float64 { return 0 }
+	jobService := NewAccrualJobService(jobRepositoryMock, pollBackoff, retryBackoff, 0)
+	jobService.now = func() time.Time { return now }
+
+	jobs, err := jobService.ClaimJobs(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.AccrualJob{job}, jobs)
+
+	// пауза между запросами результата расчета удваивается, но не превышает максимальную
+	for _, wantDelay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		require.NoError(t, jobService.PollJobLater(ctx, job))
+		assert.Equal(t, domain.AccrualJobPollStage, job.Stage)
+		assert.Equal(t, now.Add(wantDelay), job.NextAttemptAt)
+	}
+	assert.Equal(t, 3, job.Polls)
+	// успешный запрос сбрасывает счетчик неудачных попыток
+	assert.Equal(t, 0, job.Attempts)
+
+	require.NoError(t, jobService.RetryJob(ctx, job, errors.New("bad gateway")))
+	require.NoError(t, jobService.RetryJob(ctx, job, errors.New("bad gateway")))
+	assert.Equal(t, 2, job.Attempts)
+	assert.Equal(t, "bad gateway", job.LastError)
+	assert.Nil(t, job.DeadLetteredAt)
+	assert.Equal(t, now.Add(20*time.Second), job.NextAttemptAt)
+
+	// отложенное задание не считается неудачной попыткой
+	require.NoError(t, jobService.PostponeJob(ctx, job, time.Second))
+	assert.Equal(t, 2, job.Attempts)
+	assert.Equal(t, 3, job.Polls)
+	assert.Equal(t, now.Add(time.Second), job.NextAttemptAt)
+
+	require.NoError(t, jobService.CompleteJob(ctx, job))
+}
+
+func TestAccrualJobService_RetryJob_DeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	job := &domain.AccrualJob{ID: 1, OrderNumber: "123", Stage: domain.AccrualJobRegisterStage, Attempts: 1}
+
+	jobRepositoryMock := mock_services.NewMockAccrualJobRepository(ctrl)
+	jobRepositoryMock.EXPECT().RescheduleAccrualJob(ctx, job).Return(nil).Times(2)
+
+	jobService := NewAccrualJobService(jobRepositoryMock, NewBackoff(time.Second, time.Minute), NewBackoff(time.Second, time.Minute), 3)
+	jobService.now = func() time.Time { return now }
+
+	require.NoError(t, jobService.RetryJob(ctx, job, errors.New("connection refused")))
+	assert.Nil(t, job.DeadLetteredAt)
+
+	// после последней попытки задание откладывается до решения администратора вместе с последней ошибкой
+	require.NoError(t, jobService.RetryJob(ctx, job, errors.New("bad gateway")))
+	assert.Equal(t, 3, job.Attempts)
+	assert.Equal(t, "bad gateway", job.LastError)
+	require.NotNil(t, job.DeadLetteredAt)
+	assert.Equal(t, now, *job.DeadLetteredAt)
+}
+
+func TestAccrualJobService_RetryJob_ResetAfterPoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	job := &domain.AccrualJob{ID: 1, OrderNumber: "123", Stage: domain.AccrualJobPollStage}
+
+	jobRepositoryMock := mock_services.NewMockAccrualJobRepository(ctrl)
+	jobRepositoryMock.EXPECT().RescheduleAccrualJob(ctx, job).Return(nil).Times(3)
+
+	jobService := NewAccrualJobService(jobRepositoryMock, NewBackoff(time.Second, time.Minute), NewBackoff(time.Second, time.Minute), 2)
+	jobService.now = func() time.Time { return now }
+
+	require.NoError(t, jobService.RetryJob(ctx, job, errors.New("connection refused")))
+	assert.Equal(t, 1, job.Attempts)
+
+	// между ошибками система расчета ответила, что заказ еще рассчитывается
+	require.NoError(t, jobService.PollJobLater(ctx, job))
+	assert.Equal(t, 0, job.Attempts)
+
+	// ошибки идут не подряд, поэтому задание не откладывается до решения администратора
+	require.NoError(t, jobService.RetryJob(ctx, job, errors.New("bad gateway")))
+	assert.Equal(t, 1, job.Attempts)
+	assert.Nil(t, job.DeadLetteredAt)
+}
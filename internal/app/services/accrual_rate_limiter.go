@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRetryAfter пауза, если система расчета баллов ответила 429 без корректного заголовка Retry-After
+const defaultRetryAfter = 60 * time.Second
+
+// AccrualRateLimiter общий для всех воркеров ограничитель запросов к системе расчета баллов
+// не пропускает больше rps запросов в секунду и приостанавливает все запросы на время,
+// указанное системой расчета в заголовке Retry-After
+type AccrualRateLimiter struct {
+	interval    time.Duration
+	mu          sync.Mutex
+	nextSlot    time.Time
+	pausedUntil time.Time
+	// now и sleep заменяются в тестах, чтобы не ждать реального времени
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewAccrualRateLimiter создает ограничитель, rps равный 0 снимает ограничение частоты запросов
+func NewAccrualRateLimiter(rps int) *AccrualRateLimiter {
+	var interval time.Duration
+	if rps > 0 {
+		interval = time.Second / time.Duration(rps)
+	}
+	return &AccrualRateLimiter{interval: interval, now: time.Now, sleep: sleepContext}
+}
+
+// sleepContext ожидает время d, ожидание прерывается при отмене контекста ctx
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wait ожидает момента, когда можно выполнить очередной запрос
+func (l *AccrualRateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.now()
+	slot := now
+	if l.nextSlot.After(slot) {
+		slot = l.nextSlot
+	}
+	if l.pausedUntil.After(slot) {
+		slot = l.pausedUntil
+	}
+	l.nextSlot = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := slot.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	return l.sleep(ctx, delay)
+}
+
+// Pause приостанавливает все запросы на время d
+func (l *AccrualRateLimiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until := l.now().Add(d)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// parseRetryAfter разбирает заголовок Retry-After, заданный числом секунд или датой в формате HTTP
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return defaultRetryAfter
+}
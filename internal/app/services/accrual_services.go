@@ -2,49 +2,150 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/rs/zerolog/log"
 	"gophermart/internal/app/domain"
 	"io"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
 )
 
-const retryAfterTime = time.Second * 60
+// accrualMaxAttempts количество попыток выполнить запрос, на который система расчета баллов отвечает 429
+const accrualMaxAttempts = 3
+
+// AccrualClientOptions настройки HTTP клиента системы расчета баллов
+type AccrualClientOptions struct {
+	// ConnectTimeout время на установку соединения
+	ConnectTimeout time.Duration
+	// ResponseTimeout время ожидания заголовков ответа после отправки запроса
+	ResponseTimeout time.Duration
+	// RequestTimeout общее время выполнения запроса, включая чтение тела ответа
+	RequestTimeout time.Duration
+	// MaxIdleConns количество соединений, которые держатся открытыми для повторного использования
+	MaxIdleConns int
+}
+
+// NewAccrualHTTPClient создает HTTP клиент с ограничениями времени и пулом соединений к системе расчета баллов
+func NewAccrualHTTPClient(opts AccrualClientOptions) *http.Client {
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: opts.ConnectTimeout, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   opts.ConnectTimeout,
+		ResponseHeaderTimeout: opts.ResponseTimeout,
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConns,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	return &http.Client{Transport: transport, Timeout: opts.RequestTimeout}
+}
 
 type AccrualCalculationService struct {
 	accrualSystemAddr string
+	rateLimiter       *AccrualRateLimiter
+	client            *http.Client
+	breaker           *CircuitBreaker
 }
 
-func NewAccrualCalculationService(accrualSystemAddr string) *AccrualCalculationService {
-	return &AccrualCalculationService{accrualSystemAddr: accrualSystemAddr}
+func NewAccrualCalculationService(
+	accrualSystemAddr string, rateLimiter *AccrualRateLimiter, client *http.Client, breaker *CircuitBreaker,
+) *AccrualCalculationService {
+	return &AccrualCalculationService{
+		accrualSystemAddr: accrualSystemAddr,
+		rateLimiter:       rateLimiter,
+		client:            client,
+		breaker:           breaker,
+	}
+}
+
+// CircuitBreakerStats возвращает состояние автоматического выключателя запросов к системе расчета баллов
+func (s *AccrualCalculationService) CircuitBreakerStats() domain.CircuitBreakerStats {
+	return s.breaker.Stats()
 }
 
 type orderInput struct {
 	Order string `json:"order"`
 }
 
-func (s *AccrualCalculationService) CreateOrderForCalculation(orderNumber string) error {
+// doRequest выполняет запрос к системе расчета баллов с учетом общего ограничения частоты запросов
+// если система отвечает 429, все запросы приостанавливаются на время из заголовка Retry-After и запрос повторяется
+// запрос создается заново для каждой попытки, поэтому newRequest должен возвращать запрос с непрочитанным телом
+// ожидание и сам запрос прерываются при отмене контекста ctx
+// если автоматический выключатель разомкнут, запрос не выполняется и возвращается ErrAccrualCircuitOpen
+func (s *AccrualCalculationService) doRequest(
+	ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error),
+) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		if err := s.rateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		req, err := newRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.breaker.Allow(); err != nil {
+			return nil, err
+		}
+		log.Info().Msg(fmt.Sprintf("making request to %s", req.URL))
+		resp, err := s.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				s.breaker.RecordCancel()
+			} else {
+				s.breaker.RecordFailure()
+			}
+			return nil, err
+		}
+		// ошибки сервера говорят о неисправности системы расчета, а 429 - только о ее перегрузке
+		if resp.StatusCode >= http.StatusInternalServerError {
+			s.breaker.RecordFailure()
+		} else {
+			s.breaker.RecordSuccess()
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+
+		// запросы приостанавливаются и после последней попытки, чтобы другие воркеры не нагружали систему
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), s.rateLimiter.now())
+		log.Info().Msg(fmt.Sprintf("accrual system is overloaded, pausing requests for %v", retryAfter))
+		s.rateLimiter.Pause(retryAfter)
+		if attempt == accrualMaxAttempts {
+			return resp, nil
+		}
+		closeResponseBody(resp)
+	}
+}
+
+func closeResponseBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if err := resp.Body.Close(); err != nil {
+		log.Error().Msg(fmt.Sprintf("error on response body close: %v", err.Error()))
+	}
+}
+
+func (s *AccrualCalculationService) CreateOrderForCalculation(ctx context.Context, orderNumber string) error {
 	requestURL := s.accrualSystemAddr + "/api/orders"
 	reqBody, err := json.Marshal(&orderInput{Order: orderNumber})
-	reqBodyReader := bytes.NewReader(reqBody)
 	if err != nil {
 		return err
 	}
 
-	log.Info().Msg(fmt.Sprintf("making request to %s", requestURL))
-	req, err := http.NewRequest(http.MethodPost, requestURL, reqBodyReader)
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	client := http.DefaultClient
-	resp, err := client.Do(req)
+	resp, err := s.doRequest(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
 		return err
 	}
+	defer closeResponseBody(resp)
 
 	respStatusCode := resp.StatusCode
 	// 409 статус может быть в случае, если заказ ранее уже был создан в системе начисления
@@ -56,48 +157,25 @@ func (s *AccrualCalculationService) CreateOrderForCalculation(orderNumber string
 	if err != nil {
 		return err
 	}
-	err = resp.Body.Close()
-	if err != nil {
-		return err
-	}
 	bodyString := string(bodyBytes)
 	return fmt.Errorf("creating order for accrual calculation failed: status code - %d, body - %v", respStatusCode, bodyString)
 }
 
-func (s *AccrualCalculationService) GetOrderAccrualRes(orderNumber string) (*domain.AccrualCalculationRes, error) {
-	requestURL := s.accrualSystemAddr + "/api/orders/"
+func (s *AccrualCalculationService) GetOrderAccrualRes(
+	ctx context.Context, orderNumber string,
+) (*domain.AccrualCalculationRes, error) {
+	requestURL := s.accrualSystemAddr + "/api/orders/" + orderNumber
 	// проверяем, был ли заказ обработан
-	req, err := http.NewRequest(http.MethodGet, requestURL+orderNumber, nil)
+	resp, err := s.doRequest(ctx, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	})
 	if err != nil {
 		log.Error().Msg("request to accrual system failed: " + err.Error())
 		return nil, err
 	}
-	client := http.DefaultClient
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		err := resp.Body.Close()
-		if err != nil {
-			log.Error().Msg(fmt.Sprintf("error on response body close: %v", err.Error()))
-		}
-	}()
+	defer closeResponseBody(resp)
 
 	respStatusCode := resp.StatusCode
-	if respStatusCode == http.StatusTooManyRequests {
-		<-time.After(retryAfterTime)
-		resp, err = client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer func() {
-			err := resp.Body.Close()
-			if err != nil {
-				log.Error().Msg(fmt.Sprintf("error on response body close: %v", err.Error()))
-			}
-		}()
-	}
 	if respStatusCode != http.StatusOK {
 		errMsg := "request to accrual system failed: status of response - " + strconv.Itoa(respStatusCode)
 		log.Error().Msg(errMsg)
@@ -105,11 +183,7 @@ func (s *AccrualCalculationService) GetOrderAccrualRes(orderNumber string) (*dom
 	}
 
 	var accrualRes domain.AccrualCalculationRes
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	err = json.NewDecoder(bytes.NewReader(bodyBytes)).Decode(&accrualRes)
+	err = json.NewDecoder(resp.Body).Decode(&accrualRes)
 	if err != nil {
 		log.Error().Msg("request to accrual system failed: " + err.Error())
 		return nil, err
@@ -1,7 +1,277 @@
 package services
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newAccrualSystemFake запускает фейковую систему расчета баллов, которая отвечает 429 на первые tooManyRequests запросов
+func newAccrualSystemFake(t *testing.T, tooManyRequests int32, retryAfter string) (*httptest.Server, *int32) {
+	var requestsCount int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requestsCount, 1) <= tooManyRequests {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&domain.AccrualCalculationRes{
+			Order:   r.URL.Path[len("/api/orders/"):],
+			Status:  domain.OrderProcessedStatus,
+			Accrual: domain.NewMoney(500, 0),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requestsCount
+}
+
+// fakeClock часы ограничителя запросов, которые переводятся вперед при ожидании вместо реальной паузы
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	waited time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.waited += d
+	return ctx.Err()
+}
+
+func (c *fakeClock) Waited() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.waited
+}
+
+// newFakeClockRateLimiter создает ограничитель запросов, работающий по часам fakeClock
+func newFakeClockRateLimiter(rps int) (*AccrualRateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)}
+	limiter := NewAccrualRateLimiter(rps)
+	limiter.now = clock.Now
+	limiter.sleep = clock.Sleep
+	return limiter, clock
+}
+
+func TestAccrualCalculationService_GetOrderAccrualRes(t *testing.T) {
+	tests := []struct {
+		name              string
+		tooManyRequests   int32
+		wantErr           bool
+		wantWaited        time.Duration
+		wantRequestsCount int32
+		wantPaused        bool
+	}{
+		{
+			name:              "positive test",
+			wantRequestsCount: 1,
+		},
+		{
+			name:              "retried response is used",
+			tooManyRequests:   1,
+			wantWaited:        time.Second,
+			wantRequestsCount: 2,
+		},
+		{
+			name:              "too many requests on every attempt",
+			tooManyRequests:   accrualMaxAttempts,
+			wantErr:           true,
+			wantWaited:        time.Duration(accrualMaxAttempts-1) * time.Second,
+			wantRequestsCount: accrualMaxAttempts,
+			// последний ответ 429 тоже приостанавливает запросы остальных воркеров
+			wantPaused: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requestsCount := newAccrualSystemFake(t, tt.tooManyRequests, "1")
+			rateLimiter, clock := newFakeClockRateLimiter(0)
+			accrualService := NewAccrualCalculationService(srv.URL, rateLimiter, http.DefaultClient, NewCircuitBreaker(5, time.Minute))
+
+			res, err := accrualService.GetOrderAccrualRes(context.Background(), "123")
+			assert.Equal(t, tt.wantWaited, clock.Waited())
+			assert.Equal(t, tt.wantRequestsCount, atomic.LoadInt32(requestsCount))
+			assert.Equal(t, tt.wantPaused, rateLimiter.pausedUntil.After(clock.Now()))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &domain.AccrualCalculationRes{
+				Order: "123", Status: domain.OrderProcessedStatus, Accrual: domain.NewMoney(500, 0),
+			}, res)
+		})
+	}
+}
 
 func TestAccrualCalculationService_CreateOrderForCalculation(t *testing.T) {
+	srv, requestsCount := newAccrualSystemFake(t, 1, "1")
+	rateLimiter, _ := newFakeClockRateLimiter(0)
+	accrualService := NewAccrualCalculationService(srv.URL, rateLimiter, http.DefaultClient, NewCircuitBreaker(5, time.Minute))
+
+	// тело запроса должно быть отправлено и при повторной попытке
+	require.NoError(t, accrualService.CreateOrderForCalculation(context.Background(), "123"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(requestsCount))
+}
+
+func TestAccrualCalculationService_RetryAfterPausesAllRequests(t *testing.T) {
+	rateLimiter, clock := newFakeClockRateLimiter(0)
+	var mu sync.Mutex
+	var pausedAt time.Time
+	var requestTimes []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if pausedAt.IsZero() {
+			pausedAt = clock.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		requestTimes = append(requestTimes, clock.Now())
+		_ = json.NewEncoder(w).Encode(&domain.AccrualCalculationRes{Status: domain.OrderProcessingStatus})
+	}))
+	defer srv.Close()
+	accrualService := NewAccrualCalculationService(srv.URL, rateLimiter, http.DefaultClient, NewCircuitBreaker(5, time.Minute))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, err := accrualService.GetOrderAccrualRes(context.Background(), "1")
+		assert.NoError(t, err)
+	}()
+	// второй воркер делает запрос после того, как первый получил 429 и приостановил запросы
+	require.Eventually(t, func() bool {
+		rateLimiter.mu.Lock()
+		defer rateLimiter.mu.Unlock()
+		return !rateLimiter.pausedUntil.IsZero()
+	}, time.Second, time.Millisecond)
+	go func() {
+		defer wg.Done()
+		_, err := accrualService.GetOrderAccrualRes(context.Background(), "2")
+		assert.NoError(t, err)
+	}()
+	wg.Wait()
+
+	require.Len(t, requestTimes, 2)
+	for _, requestTime := range requestTimes {
+		assert.GreaterOrEqual(t, requestTime.Sub(pausedAt), time.Second)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "seconds", value: "120", want: 2 * time.Minute},
+		{name: "http date", value: "Sun, 01 May 2022 10:00:30 GMT", want: 30 * time.Second},
+		{name: "date in the past", value: "Sun, 01 May 2022 09:00:00 GMT", want: 0},
+		{name: "no header", value: "", want: defaultRetryAfter},
+		{name: "invalid value", value: "soon", want: defaultRetryAfter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseRetryAfter(tt.value, now))
+		})
+	}
+}
+
+func TestAccrualRateLimiter_Wait(t *testing.T) {
+	limiter, clock := newFakeClockRateLimiter(10)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(context.Background()))
+	}
+	// третий запрос выполняется не раньше, чем через два интервала
+	assert.Equal(t, 200*time.Millisecond, clock.Waited())
+
+	// пауза откладывает следующий запрос до своего окончания
+	limiter.Pause(time.Second)
+	require.NoError(t, limiter.Wait(context.Background()))
+	assert.Equal(t, 1200*time.Millisecond, clock.Waited())
+}
+
+func TestAccrualCalculationService_HangingAccrualSystem(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	t.Run("response timeout", func(t *testing.T) {
+		client := NewAccrualHTTPClient(AccrualClientOptions{
+			ConnectTimeout:  time.Second,
+			ResponseTimeout: 100 * time.Millisecond,
+			RequestTimeout:  time.Second,
+		})
+		accrualService := NewAccrualCalculationService(srv.URL, NewAccrualRateLimiter(0), client, NewCircuitBreaker(5, time.Minute))
+
+		start := time.Now()
+		_, err := accrualService.GetOrderAccrualRes(context.Background(), "123")
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("context is cancelled", func(t *testing.T) {
+		accrualService := NewAccrualCalculationService(srv.URL, NewAccrualRateLimiter(0), http.DefaultClient, NewCircuitBreaker(5, time.Minute))
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := accrualService.CreateOrderForCalculation(ctx, "123")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestAccrualCalculationService_CircuitBreaker(t *testing.T) {
+	var requestsCount int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestsCount, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	breaker := NewCircuitBreaker(2, time.Minute)
+	accrualService := NewAccrualCalculationService(srv.URL, NewAccrualRateLimiter(0), http.DefaultClient, breaker)
+
+	for i := 0; i < 2; i++ {
+		_, err := accrualService.GetOrderAccrualRes(context.Background(), "123")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, domain.CircuitOpen, accrualService.CircuitBreakerStats().State)
 
+	// пока выключатель разомкнут, запросы к системе расчета не выполняются
+	_, err := accrualService.GetOrderAccrualRes(context.Background(), "123")
+	assert.ErrorIs(t, err, ErrAccrualCircuitOpen)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requestsCount))
 }
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+)
+
+type AdjustmentRepository interface {
+	CreateAdjustment(ctx context.Context, adjustment *domain.BalanceAdjustment, approvalThreshold domain.Money) error
+	ApproveAdjustment(ctx context.Context, adjustmentID int, adminID int) (*domain.BalanceAdjustment, error)
+	RejectAdjustment(ctx context.Context, adjustmentID int, adminID int) (*domain.BalanceAdjustment, error)
+	GetUserAdjustments(ctx context.Context, userID int) ([]*domain.BalanceAdjustment, error)
+}
+
+type AuditLogRepository interface {
+	GetAuditLog(ctx context.Context, limit int, offset int) ([]*domain.AuditLogEntry, error)
+}
+
+// AdjustmentService ручные корректировки баланса администраторами
+// если корректировки одного администратора для одного пользователя за сутки в сумме превышают approvalThreshold,
+// они применяются только после подтверждения вторым администратором,
+// а корректировки администратором собственного баланса подтверждаются всегда
+type AdjustmentService struct {
+	adjustmentRepository AdjustmentRepository
+	auditLogRepository   AuditLogRepository
+	approvalThreshold    domain.Money
+}
+
+func NewAdjustmentService(
+	adjustmentRepository AdjustmentRepository, auditLogRepository AuditLogRepository, approvalThreshold domain.Money,
+) *AdjustmentService {
+	return &AdjustmentService{
+		adjustmentRepository: adjustmentRepository,
+		auditLogRepository:   auditLogRepository,
+		approvalThreshold:    approvalThreshold,
+	}
+}
+
+// CreateAdjustment создает корректировку и применяет ее, если подтверждение не требуется
+func (s *AdjustmentService) CreateAdjustment(ctx context.Context, adjustment *domain.BalanceAdjustment) error {
+	if adjustment.Amount == 0 {
+		return fmt.Errorf("%w: amount must not be zero", ErrInvalidAdjustment)
+	}
+
+	// с нулевым порогом подтверждения любая ненулевая корректировка ждет подтверждения,
+	// а подтвердить ее может только другой администратор
+	approvalThreshold := s.approvalThreshold
+	if adjustment.UserID == adjustment.RequestedBy {
+		approvalThreshold = 0
+	}
+
+	err := s.adjustmentRepository.CreateAdjustment(ctx, adjustment, approvalThreshold)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not adjust balance of user %d: %v", adjustment.UserID, err.Error()))
+		return err
+	}
+	log.Info().Msg(fmt.Sprintf(
+		"admin %d created adjustment %d of user %d balance by %v, status %s",
+		adjustment.RequestedBy, adjustment.ID, adjustment.UserID, adjustment.Amount, adjustment.Status,
+	))
+	return nil
+}
+
+func (s *AdjustmentService) ApproveAdjustment(
+	ctx context.Context, adjustmentID int, adminID int,
+) (*domain.BalanceAdjustment, error) {
+	adjustment, err := s.adjustmentRepository.ApproveAdjustment(ctx, adjustmentID, adminID)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not approve adjustment %d: %v", adjustmentID, err.Error()))
+		return nil, err
+	}
+	log.Info().Msg(fmt.Sprintf("admin %d approved adjustment %d", adminID, adjustmentID))
+	return adjustment, nil
+}
+
+func (s *AdjustmentService) RejectAdjustment(
+	ctx context.Context, adjustmentID int, adminID int,
+) (*domain.BalanceAdjustment, error) {
+	adjustment, err := s.adjustmentRepository.RejectAdjustment(ctx, adjustmentID, adminID)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not reject adjustment %d: %v", adjustmentID, err.Error()))
+		return nil, err
+	}
+	log.Info().Msg(fmt.Sprintf("admin %d rejected adjustment %d", adminID, adjustmentID))
+	return adjustment, nil
+}
+
+func (s *AdjustmentService) GetUserAdjustments(ctx context.Context, userID int) ([]*domain.BalanceAdjustment, error) {
+	return s.adjustmentRepository.GetUserAdjustments(ctx, userID)
+}
+
+func (s *AdjustmentService) GetAuditLog(ctx context.Context, limit int, offset int) ([]*domain.AuditLogEntry, error) {
+	return s.auditLogRepository.GetAuditLog(ctx, limit, offset)
+}
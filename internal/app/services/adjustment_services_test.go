@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+)
+
+func TestAdjustmentService_CreateAdjustment(t *testing.T) {
+	threshold := domain.NewMoney(1000, 0)
+	tests := []struct {
+		name          string
+		userID        int
+		amount        domain.Money
+		wantThreshold domain.Money
+		wantErr       error
+	}{
+		{
+			name:          "credit",
+			userID:        1,
+			amount:        domain.NewMoney(1000, 0),
+			wantThreshold: threshold,
+		},
+		{
+			name:          "debit",
+			userID:        1,
+			amount:        -domain.NewMoney(500, 0),
+			wantThreshold: threshold,
+		},
+		{
+			// корректировка собственного баланса ждет подтверждения другого администратора при любой сумме
+			name:          "own balance",
+			userID:        2,
+			amount:        domain.NewMoney(1, 0),
+			wantThreshold: 0,
+		},
+		{
+			name:    "zero amount",
+			userID:  1,
+			wantErr: ErrInvalidAdjustment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			adjustment := &domain.BalanceAdjustment{
+				UserID: tt.userID, Amount: tt.amount, Reason: "support ticket", RequestedBy: 2,
+			}
+			adjustmentRepositoryMock := mock_services.NewMockAdjustmentRepository(ctrl)
+			if tt.wantErr == nil {
+				// решение о подтверждении принимается в репозитории по сумме корректировок за сутки
+				adjustmentRepositoryMock.EXPECT().CreateAdjustment(ctx, adjustment, tt.wantThreshold).Return(nil)
+			}
+
+			adjustmentService := NewAdjustmentService(
+				adjustmentRepositoryMock, mock_services.NewMockAuditLogRepository(ctrl), threshold,
+			)
+			err := adjustmentService.CreateAdjustment(ctx, adjustment)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -23,6 +23,9 @@ func (s *RegistrationService) RegisterUser(ctx context.Context, user domain.User
 	if errors.Is(err, repositories.ErrUserAlreadyExists) {
 		return nil, ErrUserAlreadyExists
 	}
+	if errors.Is(err, repositories.ErrReferralCodeDoesNotExist) {
+		return nil, ErrReferralCodeDoesNotExist
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"math/rand"
+	"time"
+)
+
+// backoffJitter доля паузы, на которую она случайно сокращается,
+// чтобы задания, отложенные одновременно, не выполнялись тоже одновременно
+const backoffJitter = 0.2
+
+// Backoff экспоненциально растущая пауза между попытками: initial, 2*initial, 4*initial и так далее, но не больше max
+type Backoff struct {
+	initial time.Duration
+	max     time.Duration
+	random  func() float64
+}
+
+func NewBackoff(initial time.Duration, max time.Duration) *Backoff {
+	if max < initial {
+		max = initial
+	}
+	return &Backoff{initial: initial, max: max, random: rand.Float64}
+}
+
+// Delay возвращает паузу перед попыткой с номером attempt, считая с 0
+func (b *Backoff) Delay(attempt int) time.Duration {
+	delay := b.initial
+	for i := 0; i < attempt && delay < b.max; i++ {
+		delay *= 2
+	}
+	if delay > b.max {
+		delay = b.max
+	}
+	return delay - time.Duration(float64(delay)*backoffJitter*b.random())
+}
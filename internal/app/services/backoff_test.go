@@ -0,0 +1,31 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	tests := []struct {
+		name      string
+		attempt   int
+		random    float64
+		wantDelay time.Duration
+	}{
+		{name: "first attempt", attempt: 0, wantDelay: time.Second},
+		{name: "delay doubles", attempt: 3, wantDelay: 8 * time.Second},
+		{name: "delay is capped", attempt: 10, wantDelay: time.Minute},
+		{name: "large attempt does not overflow", attempt: 1000, wantDelay: time.Minute},
+		{name: "jitter shortens delay", attempt: 3, random: 0.5, wantDelay: 7200 * time.Millisecond},
+		{name: "jitter does not exceed cap", attempt: 10, random: 0.99, wantDelay: 48120 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backoff := NewBackoff(time.Second, time.Minute)
+			backoff.random = func() float64 { return tt.random }
+			assert.Equal(t, tt.wantDelay, backoff.Delay(tt.attempt))
+		})
+	}
+}
@@ -2,35 +2,68 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/rs/zerolog/log"
 	"gophermart/internal/app/domain"
+	"time"
 )
 
 type BalanceRepository interface {
-	WithdrawBalanceForOrder(ctx context.Context, withdrawal *domain.Withdrawal) error
+	WithdrawBalanceForOrder(ctx context.Context, withdrawal *domain.Withdrawal, caps []domain.WithdrawalCap) error
 	GetBalanceWithdrawals(ctx context.Context, userID int) ([]*domain.Withdrawal, error)
 	GetUserBalance(ctx context.Context, userID int) (*domain.BalanceData, error)
+	ReverseWithdrawal(ctx context.Context, reversal *domain.WithdrawalReversal) error
+	GetExpiringPoints(ctx context.Context, userID int, before time.Time) ([]*domain.ExpiringPoints, error)
+	GetStatement(ctx context.Context, userID int, filter domain.StatementFilter) ([]*domain.StatementLine, error)
 }
 
 type UserBalanceService struct {
 	balanceRepository BalanceRepository
+	// expiringSoonPeriod период, за который пользователю показываются сгорающие баллы
+	expiringSoonPeriod time.Duration
+	withdrawalRules    WithdrawalRuleChecker
 }
 
-func NewUserBalanceService(balanceRepository BalanceRepository) *UserBalanceService {
-	return &UserBalanceService{balanceRepository: balanceRepository}
+func NewUserBalanceService(
+	balanceRepository BalanceRepository, expiringSoonPeriod time.Duration, withdrawalRules WithdrawalRuleChecker,
+) *UserBalanceService {
+	return &UserBalanceService{
+		balanceRepository:  balanceRepository,
+		expiringSoonPeriod: expiringSoonPeriod,
+		withdrawalRules:    withdrawalRules,
+	}
 }
 
+// GetUserBalance возвращает баланс пользователя вместе с баллами, которые скоро сгорят
 func (s *UserBalanceService) GetUserBalance(ctx context.Context, userID int) (*domain.BalanceData, error) {
 	balanceData, err := s.balanceRepository.GetUserBalance(ctx, userID)
 	if err != nil {
 		log.Error().Msg(fmt.Sprintf("can not get balance for user: %v", err.Error()))
+		return nil, err
 	}
-	return balanceData, err
+
+	balanceData.Expiring, err = s.balanceRepository.GetExpiringPoints(ctx, userID, time.Now().Add(s.expiringSoonPeriod))
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not get expiring points for user: %v", err.Error()))
+		return nil, err
+	}
+	return balanceData, nil
 }
 
+// WithdrawBalanceForOrder списывает баллы в счет заказа, если списание не нарушает правил списания
 func (s *UserBalanceService) WithdrawBalanceForOrder(ctx context.Context, withdrawal *domain.Withdrawal) error {
-	err := s.balanceRepository.WithdrawBalanceForOrder(ctx, withdrawal)
+	if err := s.withdrawalRules.Check(ctx, withdrawal.UserID, withdrawal.Sum); err != nil {
+		log.Info().Msg(fmt.Sprintf("withdrawal for order '%s' rejected: %v", withdrawal.Order, err.Error()))
+		return err
+	}
+
+	err := s.balanceRepository.WithdrawBalanceForOrder(ctx, withdrawal, s.withdrawalRules.Caps(time.Now()))
+	var ruleErr *WithdrawalRuleError
+	if errors.As(err, &ruleErr) {
+		log.Info().Msg(fmt.Sprintf("withdrawal for order '%s' rejected: %v", withdrawal.Order, err.Error()))
+		return err
+	}
 	if err != nil {
 		log.Error().Msg(fmt.Sprintf("can not withdraw balance for order: %v", err.Error()))
 	}
@@ -45,3 +78,23 @@ func (s *UserBalanceService) GetBalanceWithdrawals(ctx context.Context, userID i
 	}
 	return withdrawals, err
 }
+
+func (s *UserBalanceService) ReverseWithdrawal(ctx context.Context, reversal *domain.WithdrawalReversal) error {
+	err := s.balanceRepository.ReverseWithdrawal(ctx, reversal)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not reverse withdrawal %d: %v", reversal.WithdrawalID, err.Error()))
+	}
+
+	return err
+}
+
+// GetStatement возвращает выписку по счету пользователя за период
+func (s *UserBalanceService) GetStatement(
+	ctx context.Context, userID int, filter domain.StatementFilter,
+) ([]*domain.StatementLine, error) {
+	lines, err := s.balanceRepository.GetStatement(ctx, userID, filter)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not get statement for user: %v", err.Error()))
+	}
+	return lines, err
+}
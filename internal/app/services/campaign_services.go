@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"time"
+)
+
+type CampaignRepository interface {
+	CreateCampaign(ctx context.Context, campaign *domain.Campaign) error
+	UpdateCampaign(ctx context.Context, campaign *domain.Campaign) error
+	DeleteCampaign(ctx context.Context, campaignID int) error
+	GetCampaign(ctx context.Context, campaignID int) (*domain.Campaign, error)
+	GetCampaigns(ctx context.Context) ([]*domain.Campaign, error)
+	GetRunningCampaigns(ctx context.Context, now time.Time) ([]*domain.Campaign, error)
+	GetCampaignOrder(ctx context.Context, orderNumber string) (*domain.CampaignOrder, error)
+	AwardCampaignBonus(ctx context.Context, campaign *domain.Campaign, bonus *domain.CampaignBonus) error
+}
+
+type CampaignService struct {
+	campaignRepository CampaignRepository
+}
+
+func NewCampaignService(campaignRepository CampaignRepository) *CampaignService {
+	return &CampaignService{campaignRepository: campaignRepository}
+}
+
+// validateCampaign проверяет, что параметры акции соответствуют ее виду
+func validateCampaign(campaign *domain.Campaign) error {
+	if !campaign.StartsAt.Before(campaign.EndsAt) {
+		return fmt.Errorf("%w: campaign must end after it starts", ErrInvalidCampaign)
+	}
+	switch campaign.Type {
+	case domain.CampaignTypeMultiplier:
+		if campaign.Multiplier <= 1 {
+			return fmt.Errorf("%w: multiplier must be greater than 1", ErrInvalidCampaign)
+		}
+		campaign.Bonus = 0
+	case domain.CampaignTypeFixedBonus:
+		if campaign.Bonus <= 0 {
+			return fmt.Errorf("%w: bonus must be greater than 0", ErrInvalidCampaign)
+		}
+		campaign.Multiplier = 0
+	default:
+		return fmt.Errorf("%w: unknown campaign type %q", ErrInvalidCampaign, campaign.Type)
+	}
+	return nil
+}
+
+func (s *CampaignService) CreateCampaign(ctx context.Context, campaign *domain.Campaign) error {
+	if err := validateCampaign(campaign); err != nil {
+		return err
+	}
+	err := s.campaignRepository.CreateCampaign(ctx, campaign)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not create campaign: %v", err.Error()))
+	}
+	return err
+}
+
+func (s *CampaignService) UpdateCampaign(ctx context.Context, campaign *domain.Campaign) error {
+	if err := validateCampaign(campaign); err != nil {
+		return err
+	}
+	err := s.campaignRepository.UpdateCampaign(ctx, campaign)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not update campaign %d: %v", campaign.ID, err.Error()))
+	}
+	return err
+}
+
+func (s *CampaignService) DeleteCampaign(ctx context.Context, campaignID int) error {
+	err := s.campaignRepository.DeleteCampaign(ctx, campaignID)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not delete campaign %d: %v", campaignID, err.Error()))
+	}
+	return err
+}
+
+func (s *CampaignService) GetCampaign(ctx context.Context, campaignID int) (*domain.Campaign, error) {
+	return s.campaignRepository.GetCampaign(ctx, campaignID)
+}
+
+func (s *CampaignService) GetCampaigns(ctx context.Context) ([]*domain.Campaign, error) {
+	campaigns, err := s.campaignRepository.GetCampaigns(ctx)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not get campaigns: %v", err.Error()))
+	}
+	return campaigns, err
+}
+
+// ApplyCampaigns начисляет бонусы по всем действующим акциям за обработанный заказ
+// accrual - начисление за заказ от системы расчета баллов, от которого считаются бонусы по акциям
+func (s *CampaignService) ApplyCampaigns(ctx context.Context, orderNumber string, accrual domain.Money) error {
+	now := time.Now()
+	campaigns, err := s.campaignRepository.GetRunningCampaigns(ctx, now)
+	if err != nil {
+		return err
+	}
+	if len(campaigns) == 0 {
+		return nil
+	}
+	order, err := s.campaignRepository.GetCampaignOrder(ctx, orderNumber)
+	if err != nil {
+		return err
+	}
+
+	for _, campaign := range campaigns {
+		// пропускаем заказы, загруженные после первого обработанного заказа пользователя, а при параллельной обработке
+		// первых заказов пользователя повторный бонус не даст начислить AwardCampaignBonus
+		if campaign.FirstOrderOnly && !order.FirstProcessedOrder {
+			continue
+		}
+		bonusSum := campaign.BonusFor(accrual)
+		if bonusSum <= 0 {
+			continue
+		}
+
+		bonus := &domain.CampaignBonus{
+			CampaignID:  campaign.ID,
+			OrderNumber: order.Number,
+			UserID:      order.UserID,
+			Sum:         bonusSum,
+		}
+		err := s.campaignRepository.AwardCampaignBonus(ctx, campaign, bonus)
+		if errors.Is(err, repositories.ErrCampaignBonusAlreadyAwarded) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		log.Info().Msg(fmt.Sprintf(
+			"awarded bonus %v for order '%s' by campaign %d", bonus.Sum, order.Number, campaign.ID,
+		))
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+	"time"
+)
+
+func TestCampaignService_ApplyCampaigns(t *testing.T) {
+	doublePoints := &domain.Campaign{ID: 1, Name: "double points", Type: domain.CampaignTypeMultiplier, Multiplier: 2}
+	firstOrderBonus := &domain.Campaign{
+		ID: 2, Name: "first order", Type: domain.CampaignTypeFixedBonus, Bonus: domain.NewMoney(100, 0), FirstOrderOnly: true,
+	}
+	tests := []struct {
+		name                string
+		firstProcessedOrder bool
+		awardErr            error
+		wantBonuses         map[int]domain.Money
+	}{
+		{
+			name:                "first processed order",
+			firstProcessedOrder: true,
+			wantBonuses:         map[int]domain.Money{1: domain.NewMoney(50, 25), 2: domain.NewMoney(100, 0)},
+		},
+		{
+			name:        "not the first order",
+			wantBonuses: map[int]domain.Money{1: domain.NewMoney(50, 25)},
+		},
+		{
+			name:                "bonuses were already awarded",
+			firstProcessedOrder: true,
+			awardErr:            repositories.ErrCampaignBonusAlreadyAwarded,
+			wantBonuses:         map[int]domain.Money{1: domain.NewMoney(50, 25), 2: domain.NewMoney(100, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			campaignRepositoryMock := mock_services.NewMockCampaignRepository(ctrl)
+			campaignRepositoryMock.EXPECT().GetRunningCampaigns(ctx, gomock.Any()).
+				Return([]*domain.Campaign{doublePoints, firstOrderBonus}, nil)
+			campaignRepositoryMock.EXPECT().GetCampaignOrder(ctx, "123").Return(&domain.CampaignOrder{
+				Number: "123", UserID: 1, FirstProcessedOrder: tt.firstProcessedOrder,
+			}, nil)
+			awarded := map[int]domain.Money{}
+			campaignRepositoryMock.EXPECT().AwardCampaignBonus(ctx, gomock.Any(), gomock.Any()).
+				Times(len(tt.wantBonuses)).
+				DoAndReturn(func(_ context.Context, campaign *domain.Campaign, bonus *domain.CampaignBonus) error {
+					awarded[campaign.ID] = bonus.Sum
+					return tt.awardErr
+				})
+
+			campaignService := NewCampaignService(campaignRepositoryMock)
+			require.NoError(t, campaignService.ApplyCampaigns(ctx, "123", domain.NewMoney(50, 25)))
+			assert.Equal(t, tt.wantBonuses, awarded)
+		})
+	}
+}
+
+func TestCampaignService_CreateCampaign(t *testing.T) {
+	startsAt := time.Date(2022, 3, 5, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		campaign *domain.Campaign
+		wantErr  error
+	}{
+		{
+			name: "multiplier campaign",
+			campaign: &domain.Campaign{
+				Name: "weekend", Type: domain.CampaignTypeMultiplier, Multiplier: 2,
+				StartsAt: startsAt, EndsAt: startsAt.Add(48 * time.Hour),
+			},
+		},
+		{
+			name: "multiplier is not greater than 1",
+			campaign: &domain.Campaign{
+				Name: "weekend", Type: domain.CampaignTypeMultiplier, Multiplier: 1,
+				StartsAt: startsAt, EndsAt: startsAt.Add(48 * time.Hour),
+			},
+			wantErr: ErrInvalidCampaign,
+		},
+		{
+			name: "bonus is not set",
+			campaign: &domain.Campaign{
+				Name: "first order", Type: domain.CampaignTypeFixedBonus,
+				StartsAt: startsAt, EndsAt: startsAt.Add(48 * time.Hour),
+			},
+			wantErr: ErrInvalidCampaign,
+		},
+		{
+			name: "campaign ends before it starts",
+			campaign: &domain.Campaign{
+				Name: "first order", Type: domain.CampaignTypeFixedBonus, Bonus: domain.NewMoney(100, 0),
+				StartsAt: startsAt, EndsAt: startsAt,
+			},
+			wantErr: ErrInvalidCampaign,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			campaignRepositoryMock := mock_services.NewMockCampaignRepository(ctrl)
+			if tt.wantErr == nil {
+				campaignRepositoryMock.EXPECT().CreateCampaign(ctx, tt.campaign).Return(nil)
+			}
+
+			campaignService := NewCampaignService(campaignRepositoryMock)
+			assert.ErrorIs(t, campaignService.CreateCampaign(ctx, tt.campaign), tt.wantErr)
+		})
+	}
+}
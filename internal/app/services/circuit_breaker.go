@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker автоматический выключатель запросов к системе расчета баллов
+// после failureThreshold ошибок подряд выключатель размыкается, и запросы не выполняются в течение openTimeout,
+// затем выполняется один пробный запрос: при успехе выключатель замыкается, при ошибке снова размыкается
+type CircuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu            sync.Mutex
+	state         string
+	failures      int
+	openedAt      time.Time
+	probeInFlight bool
+	failuresTotal int64
+	rejectedTotal int64
+	openedTotal   int64
+}
+
+// NewCircuitBreaker создает выключатель, failureThreshold меньше 1 заменяется на 1:
+// выключатель размыкается после первой же ошибки
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+		state:            domain.CircuitClosed,
+	}
+}
+
+// Allow проверяет, можно ли выполнить запрос
+// если выключатель разомкнут, возвращает ErrAccrualCircuitOpen
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case domain.CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			b.rejectedTotal++
+			return ErrAccrualCircuitOpen
+		}
+		log.Info().Msg("accrual circuit breaker is half-open, sending probe request")
+		b.state = domain.CircuitHalfOpen
+		b.probeInFlight = true
+		return nil
+	case domain.CircuitHalfOpen:
+		// пока выполняется пробный запрос, остальные запросы не выполняются
+		if b.probeInFlight {
+			b.rejectedTotal++
+			return ErrAccrualCircuitOpen
+		}
+		b.probeInFlight = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess учитывает успешный запрос
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != domain.CircuitClosed {
+		log.Info().Msg("accrual circuit breaker is closed")
+	}
+	b.state = domain.CircuitClosed
+	b.failures = 0
+	b.probeInFlight = false
+}
+
+// RecordFailure учитывает запрос, завершившийся ошибкой
+// ошибки запросов, начатых до размыкания, не продлевают паузу разомкнутого выключателя
+func (b *CircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.failuresTotal++
+	if b.state == domain.CircuitOpen {
+		return
+	}
+	if b.state == domain.CircuitHalfOpen || b.failures >= b.failureThreshold {
+		log.Error().Msg(fmt.Sprintf(
+			"accrual circuit breaker is open for %v after %d failures", b.openTimeout, b.failures,
+		))
+		b.openedTotal++
+		b.state = domain.CircuitOpen
+		b.openedAt = b.now()
+		b.probeInFlight = false
+	}
+}
+
+// RecordCancel учитывает запрос, прерванный до получения ответа, например, при остановке сервера
+// такой запрос не говорит о доступности системы расчета, поэтому только освобождает место для пробного запроса
+func (b *CircuitBreaker) RecordCancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probeInFlight = false
+}
+
+// Stats возвращает состояние и счетчики выключателя
+func (b *CircuitBreaker) Stats() domain.CircuitBreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return domain.CircuitBreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		FailuresTotal:       b.failuresTotal,
+		RejectedTotal:       b.rejectedTotal,
+		OpenedTotal:         b.openedTotal,
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	// успешный запрос сбрасывает счетчик ошибок подряд
+	require.NoError(t, breaker.Allow())
+	breaker.RecordFailure()
+	breaker.RecordSuccess()
+	breaker.RecordFailure()
+	assert.Equal(t, domain.CircuitClosed, breaker.Stats().State)
+
+	breaker.RecordFailure()
+	assert.Equal(t, domain.CircuitOpen, breaker.Stats().State)
+	assert.ErrorIs(t, breaker.Allow(), ErrAccrualCircuitOpen)
+
+	// после паузы пропускается только один пробный запрос
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow())
+	assert.Equal(t, domain.CircuitHalfOpen, breaker.Stats().State)
+	assert.ErrorIs(t, breaker.Allow(), ErrAccrualCircuitOpen)
+
+	// ошибка пробного запроса снова размыкает выключатель
+	breaker.RecordFailure()
+	assert.Equal(t, domain.CircuitOpen, breaker.Stats().State)
+	assert.ErrorIs(t, breaker.Allow(), ErrAccrualCircuitOpen)
+
+	// прерванный пробный запрос не влияет на состояние
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow())
+	breaker.RecordCancel()
+	require.NoError(t, breaker.Allow())
+
+	breaker.RecordSuccess()
+	assert.Equal(t, domain.CircuitClosed, breaker.Stats().State)
+	require.NoError(t, breaker.Allow())
+
+	assert.Equal(t, domain.CircuitBreakerStats{
+		State:         domain.CircuitClosed,
+		FailuresTotal: 4,
+		RejectedTotal: 3,
+		OpenedTotal:   2,
+	}, breaker.Stats())
+}
+
+func TestCircuitBreaker_LateFailureDoesNotExtendOpen(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordFailure()
+	assert.Equal(t, domain.CircuitOpen, breaker.Stats().State)
+
+	// запрос, начатый до размыкания, завершается ошибкой позже и не откладывает пробный запрос
+	now = now.Add(30 * time.Second)
+	breaker.RecordFailure()
+	now = now.Add(30 * time.Second)
+	require.NoError(t, breaker.Allow())
+	assert.Equal(t, domain.CircuitHalfOpen, breaker.Stats().State)
+	assert.Equal(t, int64(1), breaker.Stats().OpenedTotal)
+	assert.Equal(t, int64(2), breaker.Stats().FailuresTotal)
+}
+
+func TestNewCircuitBreaker_InvalidThreshold(t *testing.T) {
+	for _, threshold := range []int{0, -1} {
+		breaker := NewCircuitBreaker(threshold, time.Minute)
+		require.NoError(t, breaker.Allow())
+		// порог меньше 1 работает как 1: выключатель размыкается после первой ошибки
+		breaker.RecordFailure()
+		assert.Equal(t, domain.CircuitOpen, breaker.Stats().State)
+	}
+}
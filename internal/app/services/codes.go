@@ -0,0 +1,29 @@
+package services
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// в кодах нет похожих друг на друга символов, чтобы их было проще передавать и вводить вручную
+var codeAlphabet = []rune("ABCDEFGHJKLMNPQRSTUVWXYZ23456789")
+
+// generateCode генерирует случайный код длины n
+func generateCode(n int) (string, error) {
+	code := make([]rune, n)
+	for i := range code {
+		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[idx.Int64()]
+	}
+	return string(code), nil
+}
+
+// normalizeCode приводит введенный пользователем код к виду, в котором он хранится:
+// убирает пробелы и дефисы и переводит буквы в верхний регистр
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCode(t *testing.T) {
+	code, err := generateCode(12)
+	require.NoError(t, err)
+	assert.Len(t, code, 12)
+	for _, r := range code {
+		assert.True(t, strings.ContainsRune(string(codeAlphabet), r))
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	assert.Equal(t, "ABCD2345EFGH", normalizeCode(" abcd-2345-efgh "))
+}
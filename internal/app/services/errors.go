@@ -7,3 +7,13 @@ var ErrInvalidAccessToken = fmt.Errorf("access token is invalid")
 var ErrUserDoesNotExist = fmt.Errorf("user does not exist")
 var ErrOrderExistsForOtherUser = fmt.Errorf("order already exists for other user")
 var ErrUserAlreadyExists = fmt.Errorf("user with given login already exists")
+var ErrIdempotencyKeyReused = fmt.Errorf("idempotency key was already used with other request")
+var ErrIdempotentRequestInProgress = fmt.Errorf("request with this idempotency key is in progress")
+var ErrTransferRecipientNotFound = fmt.Errorf("transfer recipient does not exist")
+var ErrSelfTransfer = fmt.Errorf("can not transfer points to yourself")
+var ErrTransferLimitExceeded = fmt.Errorf("transfer limit exceeded")
+var ErrInvalidCampaign = fmt.Errorf("invalid campaign")
+var ErrReferralCodeDoesNotExist = fmt.Errorf("referral code does not exist")
+var ErrInvalidVoucherBatch = fmt.Errorf("invalid voucher batch")
+var ErrInvalidAdjustment = fmt.Errorf("invalid balance adjustment")
+var ErrAccrualCircuitOpen = fmt.Errorf("accrual system circuit breaker is open")
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+const expiredHoldsBatchSize = 100
+
+type HoldRepository interface {
+	CreateHold(ctx context.Context, hold *domain.BalanceHold, caps []domain.WithdrawalCap) error
+	CaptureHold(ctx context.Context, userID int, holdID int, now time.Time) (*domain.BalanceHold, error)
+	ReleaseHold(ctx context.Context, userID int, holdID int, status string, now time.Time) (*domain.BalanceHold, error)
+	GetExpiredHolds(ctx context.Context, now time.Time, afterID int, limit int) ([]int, error)
+}
+
+type HoldService struct {
+	holdRepository HoldRepository
+	// holdTTL время, через которое неподтвержденный резерв снимается автоматически
+	holdTTL         time.Duration
+	withdrawalRules WithdrawalRuleChecker
+}
+
+func NewHoldService(holdRepository HoldRepository, holdTTL time.Duration, withdrawalRules WithdrawalRuleChecker) *HoldService {
+	return &HoldService{holdRepository: holdRepository, holdTTL: holdTTL, withdrawalRules: withdrawalRules}
+}
+
+// AuthorizeHold резервирует баллы пользователя в счет заказа
+// резерв проверяется по тем же правилам, что и списание, поскольку после подтверждения он становится списанием
+func (s *HoldService) AuthorizeHold(ctx context.Context, hold *domain.BalanceHold) error {
+	if err := s.withdrawalRules.Check(ctx, hold.UserID, hold.Sum); err != nil {
+		log.Info().Msg(fmt.Sprintf("hold for order '%s' rejected: %v", hold.Order, err.Error()))
+		return err
+	}
+
+	hold.CreatedAt = time.Now()
+	hold.ExpiresAt = hold.CreatedAt.Add(s.holdTTL)
+	err := s.holdRepository.CreateHold(ctx, hold, s.withdrawalRules.Caps(hold.CreatedAt))
+	var ruleErr *WithdrawalRuleError
+	if errors.As(err, &ruleErr) {
+		log.Info().Msg(fmt.Sprintf("hold for order '%s' rejected: %v", hold.Order, err.Error()))
+		return err
+	}
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not hold balance for order: %v", err.Error()))
+	}
+
+	return err
+}
+
+// CaptureHold списывает зарезервированные баллы в счет заказа
+func (s *HoldService) CaptureHold(ctx context.Context, userID int, holdID int) (*domain.BalanceHold, error) {
+	hold, err := s.holdRepository.CaptureHold(ctx, userID, holdID, time.Now())
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not capture hold %d: %v", holdID, err.Error()))
+	}
+	return hold, err
+}
+
+// VoidHold отменяет резерв и возвращает баллы пользователю
+func (s *HoldService) VoidHold(ctx context.Context, userID int, holdID int) (*domain.BalanceHold, error) {
+	hold, err := s.holdRepository.ReleaseHold(ctx, userID, holdID, domain.HoldVoidedStatus, time.Now())
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not void hold %d: %v", holdID, err.Error()))
+	}
+	return hold, err
+}
+
+// ReleaseExpiredHolds снимает все резервы, срок которых истек
+// ошибка снятия одного резерва, например подтвержденного в тот же момент, не останавливает снятие остальных
+func (s *HoldService) ReleaseExpiredHolds(ctx context.Context) error {
+	now := time.Now()
+	lastHoldID := 0
+	failed := 0
+	for {
+		holdIDs, err := s.holdRepository.GetExpiredHolds(ctx, now, lastHoldID, expiredHoldsBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(holdIDs) == 0 {
+			break
+		}
+
+		for _, holdID := range holdIDs {
+			lastHoldID = holdID
+			hold, err := s.holdRepository.ReleaseHold(ctx, 0, holdID, domain.HoldExpiredStatus, now)
+			if err != nil {
+				log.Error().Msg(fmt.Sprintf("releasing expired hold %d failed: %v", holdID, err.Error()))
+				failed++
+				continue
+			}
+			log.Info().Msg(fmt.Sprintf("released expired hold %d of user %d", hold.ID, hold.UserID))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("releasing expired holds failed for %d holds", failed)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+	"time"
+)
+
+func TestHoldService_AuthorizeHold(t *testing.T) {
+	tests := []struct {
+		name         string
+		ruleErr      error
+		createErr    error
+		shouldCreate bool
+		wantErr      error
+	}{
+		{
+			name:         "hold is created",
+			shouldCreate: true,
+		},
+		{
+			name:    "hold breaks withdrawal rules",
+			ruleErr: ErrWithdrawalAboveMaximum,
+			wantErr: ErrWithdrawalAboveMaximum,
+		},
+		{
+			name:         "daily cap exceeded",
+			createErr:    ErrWithdrawalDailyLimitExceeded,
+			shouldCreate: true,
+			wantErr:      ErrWithdrawalDailyLimitExceeded,
+		},
+	}
+	caps := []domain.WithdrawalCap{
+		{Limit: domain.NewMoney(600, 0), Err: ErrWithdrawalDailyLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			hold := &domain.BalanceHold{UserID: 1, Order: "12345678903", Sum: domain.NewMoney(100, 0)}
+			rulesMock := mock_services.NewMockWithdrawalRuleChecker(ctrl)
+			rulesMock.EXPECT().Check(ctx, hold.UserID, hold.Sum).Return(tt.ruleErr)
+			holdRepositoryMock := mock_services.NewMockHoldRepository(ctrl)
+			if tt.shouldCreate {
+				rulesMock.EXPECT().Caps(gomock.Any()).Return(caps)
+				holdRepositoryMock.EXPECT().CreateHold(ctx, hold, caps).Return(tt.createErr)
+			}
+
+			holdService := NewHoldService(holdRepositoryMock, time.Minute, rulesMock)
+			err := holdService.AuthorizeHold(ctx, hold)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.shouldCreate {
+				assert.Equal(t, hold.CreatedAt.Add(time.Minute), hold.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestHoldService_ReleaseExpiredHolds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	holdRepositoryMock := mock_services.NewMockHoldRepository(ctrl)
+	// просроченные резервы снимаются пачками от имени системы, пока они не закончатся
+	gomock.InOrder(
+		holdRepositoryMock.EXPECT().GetExpiredHolds(ctx, gomock.Any(), 0, expiredHoldsBatchSize).Return([]int{1, 2}, nil),
+		holdRepositoryMock.EXPECT().ReleaseHold(ctx, 0, 1, domain.HoldExpiredStatus, gomock.Any()).
+			Return(&domain.BalanceHold{ID: 1, UserID: 10}, nil),
+		holdRepositoryMock.EXPECT().ReleaseHold(ctx, 0, 2, domain.HoldExpiredStatus, gomock.Any()).
+			Return(&domain.BalanceHold{ID: 2, UserID: 11}, nil),
+		holdRepositoryMock.EXPECT().GetExpiredHolds(ctx, gomock.Any(), 2, expiredHoldsBatchSize).Return(nil, nil),
+	)
+
+	holdService := NewHoldService(holdRepositoryMock, time.Minute, nil)
+	require.NoError(t, holdService.ReleaseExpiredHolds(ctx))
+}
+
+func TestHoldService_ReleaseExpiredHolds_ContinuesAfterError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	holdRepositoryMock := mock_services.NewMockHoldRepository(ctrl)
+	// первый резерв пользователь подтвердил в момент истечения срока, остальные все равно снимаются,
+	// а следующая пачка начинается после него, поэтому он не выбирается повторно
+	gomock.InOrder(
+		holdRepositoryMock.EXPECT().GetExpiredHolds(ctx, gomock.Any(), 0, expiredHoldsBatchSize).Return([]int{1, 2}, nil),
+		holdRepositoryMock.EXPECT().ReleaseHold(ctx, 0, 1, domain.HoldExpiredStatus, gomock.Any()).
+			Return(nil, repositories.ErrHoldIsNotActive),
+		holdRepositoryMock.EXPECT().ReleaseHold(ctx, 0, 2, domain.HoldExpiredStatus, gomock.Any()).
+			Return(&domain.BalanceHold{ID: 2, UserID: 11}, nil),
+		holdRepositoryMock.EXPECT().GetExpiredHolds(ctx, gomock.Any(), 2, expiredHoldsBatchSize).Return([]int{3}, nil),
+		holdRepositoryMock.EXPECT().ReleaseHold(ctx, 0, 3, domain.HoldExpiredStatus, gomock.Any()).
+			Return(&domain.BalanceHold{ID: 3, UserID: 12}, nil),
+		holdRepositoryMock.EXPECT().GetExpiredHolds(ctx, gomock.Any(), 3, expiredHoldsBatchSize).Return(nil, nil),
+	)
+
+	holdService := NewHoldService(holdRepositoryMock, time.Minute, nil)
+	assert.Error(t, holdService.ReleaseExpiredHolds(ctx))
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"time"
+)
+
+// idempotencyStartAttempts количество попыток зарезервировать ключ, который освобождается параллельным запросом
+const idempotencyStartAttempts = 3
+
+type IdempotencyRepository interface {
+	CreateKey(ctx context.Context, userID int, key string, fingerprint string, expiresAt time.Time) (bool, error)
+	GetKey(ctx context.Context, userID int, key string) (*domain.IdempotencyRecord, error)
+	SaveResponse(ctx context.Context, userID int, key string, response *domain.IdempotentResponse) error
+	DeleteKey(ctx context.Context, userID int, key string) error
+	DeleteExpiredKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+type IdempotencyService struct {
+	idempotencyRepository IdempotencyRepository
+	keyTTL                time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepository IdempotencyRepository, keyTTL time.Duration) *IdempotencyService {
+	return &IdempotencyService{idempotencyRepository: idempotencyRepository, keyTTL: keyTTL}
+}
+
+// StartRequest резервирует ключ идемпотентности за запросом с отпечатком fingerprint
+// если по ключу уже был сохранен ответ на такой же запрос, то возвращает этот ответ
+// если ключ освобождается параллельным запросом между попыткой его зарезервировать и чтением,
+// резервирование повторяется, а после idempotencyStartAttempts попыток запрос считается выполняющимся
+func (s *IdempotencyService) StartRequest(
+	ctx context.Context, userID int, key string, fingerprint string,
+) (*domain.IdempotentResponse, error) {
+	for attempt := 0; attempt < idempotencyStartAttempts; attempt++ {
+		created, err := s.idempotencyRepository.CreateKey(ctx, userID, key, fingerprint, time.Now().Add(s.keyTTL))
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return nil, nil
+		}
+
+		record, err := s.idempotencyRepository.GetKey(ctx, userID, key)
+		if errors.Is(err, repositories.ErrIdempotencyKeyDoesNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record.Fingerprint != fingerprint {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if record.Response == nil {
+			return nil, ErrIdempotentRequestInProgress
+		}
+
+		return record.Response, nil
+	}
+
+	return nil, ErrIdempotentRequestInProgress
+}
+
+// SaveResponse сохраняет ответ на запрос для последующих повторов
+func (s *IdempotencyService) SaveResponse(
+	ctx context.Context, userID int, key string, response *domain.IdempotentResponse,
+) error {
+	return s.idempotencyRepository.SaveResponse(ctx, userID, key, response)
+}
+
+// ReleaseKey освобождает ключ, чтобы запрос можно было выполнить повторно
+func (s *IdempotencyService) ReleaseKey(ctx context.Context, userID int, key string) error {
+	return s.idempotencyRepository.DeleteKey(ctx, userID, key)
+}
+
+// PurgeExpiredKeys удаляет ключи идемпотентности с истекшим сроком действия вместе с сохраненными ответами
+func (s *IdempotencyService) PurgeExpiredKeys(ctx context.Context) error {
+	deleted, err := s.idempotencyRepository.DeleteExpiredKeys(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	log.Info().Msg(fmt.Sprintf("purged %d expired idempotency keys", deleted))
+	return nil
+}
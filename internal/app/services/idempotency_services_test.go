@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	mock_services "gophermart/internal/app/services/mocks"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyService_StartRequest(t *testing.T) {
+	response := &domain.IdempotentResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: []byte(`{}`)}
+	type getKeyResult struct {
+		record *domain.IdempotencyRecord
+		err    error
+	}
+	tests := []struct {
+		name         string
+		created      []bool
+		getKey       []getKeyResult
+		wantResponse *domain.IdempotentResponse
+		wantErr      error
+	}{
+		{
+			name:    "new key",
+			created: []bool{true},
+		},
+		{
+			name:    "response is saved",
+			created: []bool{false},
+			getKey: []getKeyResult{
+				{record: &domain.IdempotencyRecord{Fingerprint: "fingerprint", Response: response}},
+			},
+			wantResponse: response,
+		},
+		{
+			name:    "request is in progress",
+			created: []bool{false},
+			getKey: []getKeyResult{
+				{record: &domain.IdempotencyRecord{Fingerprint: "fingerprint"}},
+			},
+			wantErr: ErrIdempotentRequestInProgress,
+		},
+		{
+			name:    "key is reused for another request",
+			created: []bool{false},
+			getKey: []getKeyResult{
+				{record: &domain.IdempotencyRecord{Fingerprint: "other"}},
+			},
+			wantErr: ErrIdempotencyKeyReused,
+		},
+		{
+			// ключ освобожден параллельным запросом между резервированием и чтением
+			name:    "key is released concurrently",
+			created: []bool{false, true},
+			getKey: []getKeyResult{
+				{err: repositories.ErrIdempotencyKeyDoesNotExist},
+			},
+		},
+		{
+			name:    "key is released concurrently on every attempt",
+			created: []bool{false, false, false},
+			getKey: []getKeyResult{
+				{err: repositories.ErrIdempotencyKeyDoesNotExist},
+				{err: repositories.ErrIdempotencyKeyDoesNotExist},
+				{err: repositories.ErrIdempotencyKeyDoesNotExist},
+			},
+			wantErr: ErrIdempotentRequestInProgress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			idempotencyRepositoryMock := mock_services.NewMockIdempotencyRepository(ctrl)
+			var calls []*gomock.Call
+			for i, created := range tt.created {
+				calls = append(calls, idempotencyRepositoryMock.EXPECT().
+					CreateKey(ctx, 1, "key", "fingerprint", gomock.Any()).Return(created, nil))
+				if i < len(tt.getKey) {
+					calls = append(calls, idempotencyRepositoryMock.EXPECT().
+						GetKey(ctx, 1, "key").Return(tt.getKey[i].record, tt.getKey[i].err))
+				}
+			}
+			gomock.InOrder(calls...)
+
+			idempotencyService := NewIdempotencyService(idempotencyRepositoryMock, time.Hour)
+			got, err := idempotencyService.StartRequest(ctx, 1, "key", "fingerprint")
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantResponse, got)
+		})
+	}
+}
+
+func TestIdempotencyService_PurgeExpiredKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	idempotencyRepositoryMock := mock_services.NewMockIdempotencyRepository(ctrl)
+	before := time.Now()
+	idempotencyRepositoryMock.EXPECT().DeleteExpiredKeys(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, now time.Time) (int64, error) {
+			assert.False(t, now.Before(before))
+			return 2, nil
+		})
+
+	idempotencyService := NewIdempotencyService(idempotencyRepositoryMock, time.Hour)
+	assert.NoError(t, idempotencyService.PurgeExpiredKeys(ctx))
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+type LedgerRepository interface {
+	GetBalanceMismatches(ctx context.Context) ([]*domain.BalanceMismatch, error)
+	GetUnbalancedTransactions(ctx context.Context) ([]int64, error)
+	RebuildUserBalance(ctx context.Context, userID int) error
+	GetUsersWithExpiredPoints(ctx context.Context, now time.Time, afterUserID int, limit int) ([]int, error)
+	ExpireUserPoints(ctx context.Context, userID int, now time.Time) (domain.Money, error)
+}
+
+// expiredPointsBatchSize количество пользователей, баллы которых списываются за один проход
+const expiredPointsBatchSize = 100
+
+type LedgerService struct {
+	ledgerRepository LedgerRepository
+}
+
+func NewLedgerService(ledgerRepository LedgerRepository) *LedgerService {
+	return &LedgerService{ledgerRepository: ledgerRepository}
+}
+
+// Reconcile сверяет сохраненные балансы пользователей с журналом операций
+// если fix равен true, то расходящиеся балансы пересчитываются по журналу
+func (s *LedgerService) Reconcile(ctx context.Context, fix bool) (*domain.ReconciliationReport, error) {
+	unbalanced, err := s.ledgerRepository.GetUnbalancedTransactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mismatches, err := s.ledgerRepository.GetBalanceMismatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if fix {
+		for _, m := range mismatches {
+			log.Info().Msg(fmt.Sprintf("rebuilding balance of user %d from ledger", m.UserID))
+			if err := s.ledgerRepository.RebuildUserBalance(ctx, m.UserID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &domain.ReconciliationReport{Mismatches: mismatches, UnbalancedTransactions: unbalanced}, nil
+}
+
+// ExpirePoints списывает у всех пользователей баллы, срок действия которых истек
+// ошибка у одного пользователя не останавливает списание у остальных: пользователи перебираются
+// по возрастанию id, поэтому пользователь с ошибкой не попадает в следующие пачки и будет обработан при следующем запуске
+func (s *LedgerService) ExpirePoints(ctx context.Context) error {
+	now := time.Now()
+	lastUserID := 0
+	failed := 0
+	for {
+		userIDs, err := s.ledgerRepository.GetUsersWithExpiredPoints(ctx, now, lastUserID, expiredPointsBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		for _, userID := range userIDs {
+			lastUserID = userID
+			expiredSum, err := s.ledgerRepository.ExpireUserPoints(ctx, userID, now)
+			if err != nil {
+				log.Error().Msg(fmt.Sprintf("expiring points of user %d failed: %v", userID, err.Error()))
+				failed++
+				continue
+			}
+			log.Info().Msg(fmt.Sprintf("expired %v points of user %d", expiredSum, userID))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("expiring points failed for %d users", failed)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+)
+
+func TestLedgerService_Reconcile(t *testing.T) {
+	mismatches := []*domain.BalanceMismatch{
+		{
+			UserID: 1,
+			Cached: domain.BalanceData{Current: domain.NewMoney(100, 0)},
+			Ledger: domain.BalanceData{Current: domain.NewMoney(90, 0)},
+		},
+		{
+			UserID: 2,
+			Cached: domain.BalanceData{Withdrawn: domain.NewMoney(5, 0)},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		fix            bool
+		unbalancedRes  []int64
+		wantRebuiltIDs []int
+	}{
+		{
+			name:          "report only",
+			fix:           false,
+			unbalancedRes: []int64{7},
+		},
+		{
+			name:           "rebuild mismatched balances",
+			fix:            true,
+			wantRebuiltIDs: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			ledgerRepositoryMock := mock_services.NewMockLedgerRepository(ctrl)
+			ledgerRepositoryMock.EXPECT().GetUnbalancedTransactions(ctx).Return(tt.unbalancedRes, nil)
+			ledgerRepositoryMock.EXPECT().GetBalanceMismatches(ctx).Return(mismatches, nil)
+			for _, userID := range tt.wantRebuiltIDs {
+				ledgerRepositoryMock.EXPECT().RebuildUserBalance(ctx, userID).Return(nil)
+			}
+
+			ledgerService := NewLedgerService(ledgerRepositoryMock)
+			report, err := ledgerService.Reconcile(ctx, tt.fix)
+			require.NoError(t, err)
+			assert.Equal(t, mismatches, report.Mismatches)
+			assert.Equal(t, tt.unbalancedRes, report.UnbalancedTransactions)
+		})
+	}
+}
+
+func TestLedgerService_ExpirePoints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	ledgerRepositoryMock := mock_services.NewMockLedgerRepository(ctrl)
+	// сгоревшие баллы списываются пачками, пока не останется пользователей со сгоревшими баллами
+	gomock.InOrder(
+		ledgerRepositoryMock.EXPECT().GetUsersWithExpiredPoints(ctx, gomock.Any(), 0, expiredPointsBatchSize).Return([]int{1, 2}, nil),
+		ledgerRepositoryMock.EXPECT().ExpireUserPoints(ctx, 1, gomock.Any()).Return(domain.NewMoney(10, 0), nil),
+		ledgerRepositoryMock.EXPECT().ExpireUserPoints(ctx, 2, gomock.Any()).Return(domain.NewMoney(5, 50), nil),
+		ledgerRepositoryMock.EXPECT().GetUsersWithExpiredPoints(ctx, gomock.Any(), 2, expiredPointsBatchSize).Return(nil, nil),
+	)
+
+	ledgerService := NewLedgerService(ledgerRepositoryMock)
+	require.NoError(t, ledgerService.ExpirePoints(ctx))
+}
+
+func TestLedgerService_ExpirePoints_UserFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	ledgerRepositoryMock := mock_services.NewMockLedgerRepository(ctrl)
+	// ошибка у первого пользователя не мешает списанию у остальных, а следующая пачка начинается после него
+	gomock.InOrder(
+		ledgerRepositoryMock.EXPECT().GetUsersWithExpiredPoints(ctx, gomock.Any(), 0, expiredPointsBatchSize).Return([]int{1}, nil),
+		ledgerRepositoryMock.EXPECT().ExpireUserPoints(ctx, 1, gomock.Any()).Return(domain.Money(0), errors.New("db error")),
+		ledgerRepositoryMock.EXPECT().GetUsersWithExpiredPoints(ctx, gomock.Any(), 1, expiredPointsBatchSize).Return([]int{2}, nil),
+		ledgerRepositoryMock.EXPECT().ExpireUserPoints(ctx, 2, gomock.Any()).Return(domain.NewMoney(5, 0), nil),
+		ledgerRepositoryMock.EXPECT().GetUsersWithExpiredPoints(ctx, gomock.Any(), 2, expiredPointsBatchSize).Return(nil, nil),
+	)
+
+	ledgerService := NewLedgerService(ledgerRepositoryMock)
+	assert.Error(t, ledgerService.ExpirePoints(ctx))
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: AccrualJobRepository)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAccrualJobRepository is a mock of AccrualJobRepository interface.
+type MockAccrualJobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualJobRepositoryMockRecorder
+}
+
+// MockAccrualJobRepositoryMockRecorder is the mock recorder for MockAccrualJobRepository.
+type MockAccrualJobRepositoryMockRecorder struct {
+	mock *MockAccrualJobRepository
+}
+
+// NewMockAccrualJobRepository creates a new mock instance.
+func NewMockAccrualJobRepository(ctrl *gomock.Controller) *MockAccrualJobRepository {
+	mock := &MockAccrualJobRepository{ctrl: ctrl}
+	mock.recorder = &MockAccrualJobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualJobRepository) EXPECT() *MockAccrualJobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimAccrualJobs mocks base method.
+func (m *MockAccrualJobRepository) ClaimAccrualJobs(arg0 context.Context, arg1 string, arg2, arg3 time.Time, arg4 int) ([]*domain.AccrualJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimAccrualJobs", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]*domain.AccrualJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimAccrualJobs indicates an expected call of ClaimAccrualJobs.
+func (mr *MockAccrualJobRepositoryMockRecorder) ClaimAccrualJobs(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimAccrualJobs", reflect.TypeOf((*MockAccrualJobRepository)(nil).ClaimAccrualJobs), arg0, arg1, arg2, arg3, arg4)
+}
+
+// DeleteAccrualJob mocks base method.
+func (m *MockAccrualJobRepository) DeleteAccrualJob(arg0 context.Context, arg1 *domain.AccrualJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccrualJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccrualJob indicates an expected call of DeleteAccrualJob.
+func (mr *MockAccrualJobRepositoryMockRecorder) DeleteAccrualJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccrualJob", reflect.TypeOf((*MockAccrualJobRepository)(nil).DeleteAccrualJob), arg0, arg1)
+}
+
+// GetDeadLetteredAccrualJobs mocks base method.
+func (m *MockAccrualJobRepository) GetDeadLetteredAccrualJobs(arg0 context.Context, arg1, arg2 int) ([]*domain.AccrualJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetteredAccrualJobs", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.AccrualJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetteredAccrualJobs indicates an expected call of GetDeadLetteredAccrualJobs.
+func (mr *MockAccrualJobRepositoryMockRecorder) GetDeadLetteredAccrualJobs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetteredAccrualJobs", reflect.TypeOf((*MockAccrualJobRepository)(nil).GetDeadLetteredAccrualJobs), arg0, arg1, arg2)
+}
+
+// RequeueAccrualJob mocks base method.
+func (m *MockAccrualJobRepository) RequeueAccrualJob(arg0 context.Context, arg1 int64, arg2 int, arg3 time.Time) (*domain.AccrualJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueAccrualJob", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.AccrualJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueAccrualJob indicates an expected call of RequeueAccrualJob.
+func (mr *MockAccrualJobRepositoryMockRecorder) RequeueAccrualJob(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueAccrualJob", reflect.TypeOf((*MockAccrualJobRepository)(nil).RequeueAccrualJob), arg0, arg1, arg2, arg3)
+}
+
+// RescheduleAccrualJob mocks base method.
+func (m *MockAccrualJobRepository) RescheduleAccrualJob(arg0 context.Context, arg1 *domain.AccrualJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleAccrualJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleAccrualJob indicates an expected call of RescheduleAccrualJob.
+func (mr *MockAccrualJobRepositoryMockRecorder) RescheduleAccrualJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleAccrualJob", reflect.TypeOf((*MockAccrualJobRepository)(nil).RescheduleAccrualJob), arg0, arg1)
+}
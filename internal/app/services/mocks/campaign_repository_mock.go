@@ -0,0 +1,153 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: CampaignRepository)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCampaignRepository is a mock of CampaignRepository interface.
+type MockCampaignRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCampaignRepositoryMockRecorder
+}
+
+// MockCampaignRepositoryMockRecorder is the mock recorder for MockCampaignRepository.
+type MockCampaignRepositoryMockRecorder struct {
+	mock *MockCampaignRepository
+}
+
+// NewMockCampaignRepository creates a new mock instance.
+func NewMockCampaignRepository(ctrl *gomock.Controller) *MockCampaignRepository {
+	mock := &MockCampaignRepository{ctrl: ctrl}
+	mock.recorder = &MockCampaignRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCampaignRepository) EXPECT() *MockCampaignRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AwardCampaignBonus mocks base method.
+func (m *MockCampaignRepository) AwardCampaignBonus(arg0 context.Context, arg1 *domain.Campaign, arg2 *domain.CampaignBonus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AwardCampaignBonus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AwardCampaignBonus indicates an expected call of AwardCampaignBonus.
+func (mr *MockCampaignRepositoryMockRecorder) AwardCampaignBonus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AwardCampaignBonus", reflect.TypeOf((*MockCampaignRepository)(nil).AwardCampaignBonus), arg0, arg1, arg2)
+}
+
+// CreateCampaign mocks base method.
+func (m *MockCampaignRepository) CreateCampaign(arg0 context.Context, arg1 *domain.Campaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockCampaignRepositoryMockRecorder) CreateCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockCampaignRepository)(nil).CreateCampaign), arg0, arg1)
+}
+
+// DeleteCampaign mocks base method.
+func (m *MockCampaignRepository) DeleteCampaign(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCampaign indicates an expected call of DeleteCampaign.
+func (mr *MockCampaignRepositoryMockRecorder) DeleteCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCampaign", reflect.TypeOf((*MockCampaignRepository)(nil).DeleteCampaign), arg0, arg1)
+}
+
+// GetCampaign mocks base method.
+func (m *MockCampaignRepository) GetCampaign(arg0 context.Context, arg1 int) (*domain.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaign", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaign indicates an expected call of GetCampaign.
+func (mr *MockCampaignRepositoryMockRecorder) GetCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaign", reflect.TypeOf((*MockCampaignRepository)(nil).GetCampaign), arg0, arg1)
+}
+
+// GetCampaignOrder mocks base method.
+func (m *MockCampaignRepository) GetCampaignOrder(arg0 context.Context, arg1 string) (*domain.CampaignOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaignOrder", arg0, arg1)
+	ret0, _ := ret[0].(*domain.CampaignOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaignOrder indicates an expected call of GetCampaignOrder.
+func (mr *MockCampaignRepositoryMockRecorder) GetCampaignOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaignOrder", reflect.TypeOf((*MockCampaignRepository)(nil).GetCampaignOrder), arg0, arg1)
+}
+
+// GetCampaigns mocks base method.
+func (m *MockCampaignRepository) GetCampaigns(arg0 context.Context) ([]*domain.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaigns", arg0)
+	ret0, _ := ret[0].([]*domain.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaigns indicates an expected call of GetCampaigns.
+func (mr *MockCampaignRepositoryMockRecorder) GetCampaigns(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaigns", reflect.TypeOf((*MockCampaignRepository)(nil).GetCampaigns), arg0)
+}
+
+// GetRunningCampaigns mocks base method.
+func (m *MockCampaignRepository) GetRunningCampaigns(arg0 context.Context, arg1 time.Time) ([]*domain.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunningCampaigns", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunningCampaigns indicates an expected call of GetRunningCampaigns.
+func (mr *MockCampaignRepositoryMockRecorder) GetRunningCampaigns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningCampaigns", reflect.TypeOf((*MockCampaignRepository)(nil).GetRunningCampaigns), arg0, arg1)
+}
+
+// UpdateCampaign mocks base method.
+func (m *MockCampaignRepository) UpdateCampaign(arg0 context.Context, arg1 *domain.Campaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCampaign indicates an expected call of UpdateCampaign.
+func (mr *MockCampaignRepositoryMockRecorder) UpdateCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCampaign", reflect.TypeOf((*MockCampaignRepository)(nil).UpdateCampaign), arg0, arg1)
+}
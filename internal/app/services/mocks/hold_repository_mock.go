@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: HoldRepository)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockHoldRepository is a mock of HoldRepository interface.
+type MockHoldRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHoldRepositoryMockRecorder
+}
+
+// MockHoldRepositoryMockRecorder is the mock recorder for MockHoldRepository.
+type MockHoldRepositoryMockRecorder struct {
+	mock *MockHoldRepository
+}
+
+// NewMockHoldRepository creates a new mock instance.
+func NewMockHoldRepository(ctrl *gomock.Controller) *MockHoldRepository {
+	mock := &MockHoldRepository{ctrl: ctrl}
+	mock.recorder = &MockHoldRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHoldRepository) EXPECT() *MockHoldRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CaptureHold mocks base method.
+func (m *MockHoldRepository) CaptureHold(arg0 context.Context, arg1, arg2 int, arg3 time.Time) (*domain.BalanceHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.BalanceHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockHoldRepositoryMockRecorder) CaptureHold(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockHoldRepository)(nil).CaptureHold), arg0, arg1, arg2, arg3)
+}
+
+// CreateHold mocks base method.
+func (m *MockHoldRepository) CreateHold(arg0 context.Context, arg1 *domain.BalanceHold, arg2 []domain.WithdrawalCap) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockHoldRepositoryMockRecorder) CreateHold(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockHoldRepository)(nil).CreateHold), arg0, arg1, arg2)
+}
+
+// GetExpiredHolds mocks base method.
+func (m *MockHoldRepository) GetExpiredHolds(arg0 context.Context, arg1 time.Time, arg2, arg3 int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredHolds", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredHolds indicates an expected call of GetExpiredHolds.
+func (mr *MockHoldRepositoryMockRecorder) GetExpiredHolds(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredHolds", reflect.TypeOf((*MockHoldRepository)(nil).GetExpiredHolds), arg0, arg1, arg2, arg3)
+}
+
+// ReleaseHold mocks base method.
+func (m *MockHoldRepository) ReleaseHold(arg0 context.Context, arg1, arg2 int, arg3 string, arg4 time.Time) (*domain.BalanceHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHold", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*domain.BalanceHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseHold indicates an expected call of ReleaseHold.
+func (mr *MockHoldRepositoryMockRecorder) ReleaseHold(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHold", reflect.TypeOf((*MockHoldRepository)(nil).ReleaseHold), arg0, arg1, arg2, arg3, arg4)
+}
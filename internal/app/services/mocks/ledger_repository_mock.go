@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: LedgerRepository)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLedgerRepository is a mock of LedgerRepository interface.
+type MockLedgerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerRepositoryMockRecorder
+}
+
+// MockLedgerRepositoryMockRecorder is the mock recorder for MockLedgerRepository.
+type MockLedgerRepositoryMockRecorder struct {
+	mock *MockLedgerRepository
+}
+
+// NewMockLedgerRepository creates a new mock instance.
+func NewMockLedgerRepository(ctrl *gomock.Controller) *MockLedgerRepository {
+	mock := &MockLedgerRepository{ctrl: ctrl}
+	mock.recorder = &MockLedgerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerRepository) EXPECT() *MockLedgerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ExpireUserPoints mocks base method.
+func (m *MockLedgerRepository) ExpireUserPoints(arg0 context.Context, arg1 int, arg2 time.Time) (domain.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireUserPoints", arg0, arg1, arg2)
+	ret0, _ := ret[0].(domain.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireUserPoints indicates an expected call of ExpireUserPoints.
+func (mr *MockLedgerRepositoryMockRecorder) ExpireUserPoints(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireUserPoints", reflect.TypeOf((*MockLedgerRepository)(nil).ExpireUserPoints), arg0, arg1, arg2)
+}
+
+// GetBalanceMismatches mocks base method.
+func (m *MockLedgerRepository) GetBalanceMismatches(arg0 context.Context) ([]*domain.BalanceMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceMismatches", arg0)
+	ret0, _ := ret[0].([]*domain.BalanceMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceMismatches indicates an expected call of GetBalanceMismatches.
+func (mr *MockLedgerRepositoryMockRecorder) GetBalanceMismatches(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceMismatches", reflect.TypeOf((*MockLedgerRepository)(nil).GetBalanceMismatches), arg0)
+}
+
+// GetUnbalancedTransactions mocks base method.
+func (m *MockLedgerRepository) GetUnbalancedTransactions(arg0 context.Context) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnbalancedTransactions", arg0)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnbalancedTransactions indicates an expected call of GetUnbalancedTransactions.
+func (mr *MockLedgerRepositoryMockRecorder) GetUnbalancedTransactions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnbalancedTransactions", reflect.TypeOf((*MockLedgerRepository)(nil).GetUnbalancedTransactions), arg0)
+}
+
+// GetUsersWithExpiredPoints mocks base method.
+func (m *MockLedgerRepository) GetUsersWithExpiredPoints(arg0 context.Context, arg1 time.Time, arg2, arg3 int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersWithExpiredPoints", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersWithExpiredPoints indicates an expected call of GetUsersWithExpiredPoints.
+func (mr *MockLedgerRepositoryMockRecorder) GetUsersWithExpiredPoints(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersWithExpiredPoints", reflect.TypeOf((*MockLedgerRepository)(nil).GetUsersWithExpiredPoints), arg0, arg1, arg2, arg3)
+}
+
+// RebuildUserBalance mocks base method.
+func (m *MockLedgerRepository) RebuildUserBalance(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildUserBalance", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebuildUserBalance indicates an expected call of RebuildUserBalance.
+func (mr *MockLedgerRepositoryMockRecorder) RebuildUserBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildUserBalance", reflect.TypeOf((*MockLedgerRepository)(nil).RebuildUserBalance), arg0, arg1)
+}
@@ -35,6 +35,20 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockUserRepository) ChangePassword(ctx context.Context, userID int, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserRepositoryMockRecorder) ChangePassword(ctx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserRepository)(nil).ChangePassword), ctx, userID, password)
+}
+
 // CreateUser mocks base method.
 func (m *MockUserRepository) CreateUser(ctx context.Context, user domain.UserDTO) error {
 	m.ctrl.T.Helper()
@@ -58,22 +72,22 @@ func (m *MockUserRepository) GetUserByLogin(ctx context.Context, username string
 	return ret0, ret1
 }
 
-// GetUserByUsername indicates an expected call of GetUserByUsername.
-func (mr *MockUserRepositoryMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
+// GetUserByLogin indicates an expected call of GetUserByLogin.
+func (mr *MockUserRepositoryMockRecorder) GetUserByLogin(ctx, username interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockUserRepository)(nil).GetUserByLogin), ctx, username)
 }
 
-// IncreaseBalanceForOrder mocks base method.
-func (m *MockUserRepository) IncreaseBalanceForOrder(ctx context.Context, orderNumber string, points int) error {
+// IncreaseBalanceAndUpdateOrderStatus mocks base method.
+func (m *MockUserRepository) IncreaseBalanceAndUpdateOrderStatus(ctx context.Context, orderNumber string, accrual domain.Money, orderStatus string, tiers domain.Tiers) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncreaseBalanceForOrder", ctx, orderNumber, points)
+	ret := m.ctrl.Call(m, "IncreaseBalanceAndUpdateOrderStatus", ctx, orderNumber, accrual, orderStatus, tiers)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// IncreaseBalanceForOrder indicates an expected call of IncreaseBalanceForOrder.
-func (mr *MockUserRepositoryMockRecorder) IncreaseBalanceForOrder(ctx, orderNumber, points interface{}) *gomock.Call {
+// IncreaseBalanceAndUpdateOrderStatus indicates an expected call of IncreaseBalanceAndUpdateOrderStatus.
+func (mr *MockUserRepositoryMockRecorder) IncreaseBalanceAndUpdateOrderStatus(ctx, orderNumber, accrual, orderStatus, tiers interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseBalanceForOrder", reflect.TypeOf((*MockUserRepository)(nil).IncreaseBalanceForOrder), ctx, orderNumber, points)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseBalanceAndUpdateOrderStatus", reflect.TypeOf((*MockUserRepository)(nil).IncreaseBalanceAndUpdateOrderStatus), ctx, orderNumber, accrual, orderStatus, tiers)
 }
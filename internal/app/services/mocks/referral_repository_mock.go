@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: ReferralRepository)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReferralRepository is a mock of ReferralRepository interface.
+type MockReferralRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReferralRepositoryMockRecorder
+}
+
+// MockReferralRepositoryMockRecorder is the mock recorder for MockReferralRepository.
+type MockReferralRepositoryMockRecorder struct {
+	mock *MockReferralRepository
+}
+
+// NewMockReferralRepository creates a new mock instance.
+func NewMockReferralRepository(ctrl *gomock.Controller) *MockReferralRepository {
+	mock := &MockReferralRepository{ctrl: ctrl}
+	mock.recorder = &MockReferralRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReferralRepository) EXPECT() *MockReferralRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetReferrals mocks base method.
+func (m *MockReferralRepository) GetReferrals(arg0 context.Context, arg1 int) ([]*domain.Referral, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferrals", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Referral)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferrals indicates an expected call of GetReferrals.
+func (mr *MockReferralRepositoryMockRecorder) GetReferrals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferrals", reflect.TypeOf((*MockReferralRepository)(nil).GetReferrals), arg0, arg1)
+}
+
+// RewardReferral mocks base method.
+func (m *MockReferralRepository) RewardReferral(arg0 context.Context, arg1 string, arg2 domain.Money, arg3 int) (*domain.Referral, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewardReferral", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.Referral)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RewardReferral indicates an expected call of RewardReferral.
+func (mr *MockReferralRepositoryMockRecorder) RewardReferral(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewardReferral", reflect.TypeOf((*MockReferralRepository)(nil).RewardReferral), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: TierRepository)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTierRepository is a mock of TierRepository interface.
+type MockTierRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTierRepositoryMockRecorder
+}
+
+// MockTierRepositoryMockRecorder is the mock recorder for MockTierRepository.
+type MockTierRepositoryMockRecorder struct {
+	mock *MockTierRepository
+}
+
+// NewMockTierRepository creates a new mock instance.
+func NewMockTierRepository(ctrl *gomock.Controller) *MockTierRepository {
+	mock := &MockTierRepository{ctrl: ctrl}
+	mock.recorder = &MockTierRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTierRepository) EXPECT() *MockTierRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetEarnedPoints mocks base method.
+func (m *MockTierRepository) GetEarnedPoints(arg0 context.Context, arg1 time.Time) ([]*domain.UserTierRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEarnedPoints", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.UserTierRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEarnedPoints indicates an expected call of GetEarnedPoints.
+func (mr *MockTierRepositoryMockRecorder) GetEarnedPoints(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEarnedPoints", reflect.TypeOf((*MockTierRepository)(nil).GetEarnedPoints), arg0, arg1)
+}
+
+// GetUserEarnedPoints mocks base method.
+func (m *MockTierRepository) GetUserEarnedPoints(arg0 context.Context, arg1 int, arg2 time.Time) (domain.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserEarnedPoints", arg0, arg1, arg2)
+	ret0, _ := ret[0].(domain.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserEarnedPoints indicates an expected call of GetUserEarnedPoints.
+func (mr *MockTierRepositoryMockRecorder) GetUserEarnedPoints(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEarnedPoints", reflect.TypeOf((*MockTierRepository)(nil).GetUserEarnedPoints), arg0, arg1, arg2)
+}
+
+// GetUserTier mocks base method.
+func (m *MockTierRepository) GetUserTier(arg0 context.Context, arg1 int) (*domain.UserTierRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTier", arg0, arg1)
+	ret0, _ := ret[0].(*domain.UserTierRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTier indicates an expected call of GetUserTier.
+func (mr *MockTierRepositoryMockRecorder) GetUserTier(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTier", reflect.TypeOf((*MockTierRepository)(nil).GetUserTier), arg0, arg1)
+}
+
+// SaveUserTier mocks base method.
+func (m *MockTierRepository) SaveUserTier(arg0 context.Context, arg1 *domain.UserTierRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUserTier", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveUserTier indicates an expected call of SaveUserTier.
+func (mr *MockTierRepositoryMockRecorder) SaveUserTier(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUserTier", reflect.TypeOf((*MockTierRepository)(nil).SaveUserTier), arg0, arg1)
+}
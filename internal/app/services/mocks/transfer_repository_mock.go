@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: TransferRepository,TransferUserService,TransferCooldownChecker)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTransferRepository is a mock of TransferRepository interface.
+type MockTransferRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferRepositoryMockRecorder
+}
+
+// MockTransferRepositoryMockRecorder is the mock recorder for MockTransferRepository.
+type MockTransferRepositoryMockRecorder struct {
+	mock *MockTransferRepository
+}
+
+// NewMockTransferRepository creates a new mock instance.
+func NewMockTransferRepository(ctrl *gomock.Controller) *MockTransferRepository {
+	mock := &MockTransferRepository{ctrl: ctrl}
+	mock.recorder = &MockTransferRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferRepository) EXPECT() *MockTransferRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateTransfer mocks base method.
+func (m *MockTransferRepository) CreateTransfer(arg0 context.Context, arg1 *domain.Transfer, arg2 domain.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockTransferRepositoryMockRecorder) CreateTransfer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockTransferRepository)(nil).CreateTransfer), arg0, arg1, arg2)
+}
+
+// GetTransfersByUser mocks base method.
+func (m *MockTransferRepository) GetTransfersByUser(arg0 context.Context, arg1 int) ([]*domain.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfersByUser", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfersByUser indicates an expected call of GetTransfersByUser.
+func (mr *MockTransferRepositoryMockRecorder) GetTransfersByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfersByUser", reflect.TypeOf((*MockTransferRepository)(nil).GetTransfersByUser), arg0, arg1)
+}
+
+// MockTransferUserService is a mock of TransferUserService interface.
+type MockTransferUserService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferUserServiceMockRecorder
+}
+
+// MockTransferUserServiceMockRecorder is the mock recorder for MockTransferUserService.
+type MockTransferUserServiceMockRecorder struct {
+	mock *MockTransferUserService
+}
+
+// NewMockTransferUserService creates a new mock instance.
+func NewMockTransferUserService(ctrl *gomock.Controller) *MockTransferUserService {
+	mock := &MockTransferUserService{ctrl: ctrl}
+	mock.recorder = &MockTransferUserServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferUserService) EXPECT() *MockTransferUserServiceMockRecorder {
+	return m.recorder
+}
+
+// GetUserByLogin mocks base method.
+func (m *MockTransferUserService) GetUserByLogin(arg0 context.Context, arg1 string) (*domain.UserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByLogin", arg0, arg1)
+	ret0, _ := ret[0].(*domain.UserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByLogin indicates an expected call of GetUserByLogin.
+func (mr *MockTransferUserServiceMockRecorder) GetUserByLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockTransferUserService)(nil).GetUserByLogin), arg0, arg1)
+}
+
+// MockTransferCooldownChecker is a mock of TransferCooldownChecker interface.
+type MockTransferCooldownChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferCooldownCheckerMockRecorder
+}
+
+// MockTransferCooldownCheckerMockRecorder is the mock recorder for MockTransferCooldownChecker.
+type MockTransferCooldownCheckerMockRecorder struct {
+	mock *MockTransferCooldownChecker
+}
+
+// NewMockTransferCooldownChecker creates a new mock instance.
+func NewMockTransferCooldownChecker(ctrl *gomock.Controller) *MockTransferCooldownChecker {
+	mock := &MockTransferCooldownChecker{ctrl: ctrl}
+	mock.recorder = &MockTransferCooldownCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferCooldownChecker) EXPECT() *MockTransferCooldownCheckerMockRecorder {
+	return m.recorder
+}
+
+// CheckPasswordCooldown mocks base method.
+func (m *MockTransferCooldownChecker) CheckPasswordCooldown(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPasswordCooldown", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckPasswordCooldown indicates an expected call of CheckPasswordCooldown.
+func (mr *MockTransferCooldownCheckerMockRecorder) CheckPasswordCooldown(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPasswordCooldown", reflect.TypeOf((*MockTransferCooldownChecker)(nil).CheckPasswordCooldown), arg0, arg1)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: VoucherRepository)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockVoucherRepository is a mock of VoucherRepository interface.
+type MockVoucherRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVoucherRepositoryMockRecorder
+}
+
+// MockVoucherRepositoryMockRecorder is the mock recorder for MockVoucherRepository.
+type MockVoucherRepositoryMockRecorder struct {
+	mock *MockVoucherRepository
+}
+
+// NewMockVoucherRepository creates a new mock instance.
+func NewMockVoucherRepository(ctrl *gomock.Controller) *MockVoucherRepository {
+	mock := &MockVoucherRepository{ctrl: ctrl}
+	mock.recorder = &MockVoucherRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVoucherRepository) EXPECT() *MockVoucherRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateVoucherBatch mocks base method.
+func (m *MockVoucherRepository) CreateVoucherBatch(arg0 context.Context, arg1 *domain.VoucherBatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVoucherBatch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVoucherBatch indicates an expected call of CreateVoucherBatch.
+func (mr *MockVoucherRepositoryMockRecorder) CreateVoucherBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVoucherBatch", reflect.TypeOf((*MockVoucherRepository)(nil).CreateVoucherBatch), arg0, arg1)
+}
+
+// GetVoucherBatch mocks base method.
+func (m *MockVoucherRepository) GetVoucherBatch(arg0 context.Context, arg1 int) (*domain.VoucherBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVoucherBatch", arg0, arg1)
+	ret0, _ := ret[0].(*domain.VoucherBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVoucherBatch indicates an expected call of GetVoucherBatch.
+func (mr *MockVoucherRepositoryMockRecorder) GetVoucherBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVoucherBatch", reflect.TypeOf((*MockVoucherRepository)(nil).GetVoucherBatch), arg0, arg1)
+}
+
+// RedeemVoucher mocks base method.
+func (m *MockVoucherRepository) RedeemVoucher(arg0 context.Context, arg1 string, arg2 int, arg3 time.Time) (*domain.VoucherRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemVoucher", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.VoucherRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemVoucher indicates an expected call of RedeemVoucher.
+func (mr *MockVoucherRepositoryMockRecorder) RedeemVoucher(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemVoucher", reflect.TypeOf((*MockVoucherRepository)(nil).RedeemVoucher), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: WithdrawalRuleChecker)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWithdrawalRuleChecker is a mock of WithdrawalRuleChecker interface.
+type MockWithdrawalRuleChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockWithdrawalRuleCheckerMockRecorder
+}
+
+// MockWithdrawalRuleCheckerMockRecorder is the mock recorder for MockWithdrawalRuleChecker.
+type MockWithdrawalRuleCheckerMockRecorder struct {
+	mock *MockWithdrawalRuleChecker
+}
+
+// NewMockWithdrawalRuleChecker creates a new mock instance.
+func NewMockWithdrawalRuleChecker(ctrl *gomock.Controller) *MockWithdrawalRuleChecker {
+	mock := &MockWithdrawalRuleChecker{ctrl: ctrl}
+	mock.recorder = &MockWithdrawalRuleCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWithdrawalRuleChecker) EXPECT() *MockWithdrawalRuleCheckerMockRecorder {
+	return m.recorder
+}
+
+// Caps mocks base method.
+func (m *MockWithdrawalRuleChecker) Caps(arg0 time.Time) []domain.WithdrawalCap {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Caps", arg0)
+	ret0, _ := ret[0].([]domain.WithdrawalCap)
+	return ret0
+}
+
+// Caps indicates an expected call of Caps.
+func (mr *MockWithdrawalRuleCheckerMockRecorder) Caps(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Caps", reflect.TypeOf((*MockWithdrawalRuleChecker)(nil).Caps), arg0)
+}
+
+// Check mocks base method.
+func (m *MockWithdrawalRuleChecker) Check(arg0 context.Context, arg1 int, arg2 domain.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockWithdrawalRuleCheckerMockRecorder) Check(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockWithdrawalRuleChecker)(nil).Check), arg0, arg1, arg2)
+}
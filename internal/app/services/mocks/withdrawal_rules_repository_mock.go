@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/services (interfaces: WithdrawalRulesRepository)
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWithdrawalRulesRepository is a mock of WithdrawalRulesRepository interface.
+type MockWithdrawalRulesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWithdrawalRulesRepositoryMockRecorder
+}
+
+// MockWithdrawalRulesRepositoryMockRecorder is the mock recorder for MockWithdrawalRulesRepository.
+type MockWithdrawalRulesRepositoryMockRecorder struct {
+	mock *MockWithdrawalRulesRepository
+}
+
+// NewMockWithdrawalRulesRepository creates a new mock instance.
+func NewMockWithdrawalRulesRepository(ctrl *gomock.Controller) *MockWithdrawalRulesRepository {
+	mock := &MockWithdrawalRulesRepository{ctrl: ctrl}
+	mock.recorder = &MockWithdrawalRulesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWithdrawalRulesRepository) EXPECT() *MockWithdrawalRulesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetPasswordChangedAt mocks base method.
+func (m *MockWithdrawalRulesRepository) GetPasswordChangedAt(arg0 context.Context, arg1 int) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordChangedAt", arg0, arg1)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordChangedAt indicates an expected call of GetPasswordChangedAt.
+func (mr *MockWithdrawalRulesRepositoryMockRecorder) GetPasswordChangedAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordChangedAt", reflect.TypeOf((*MockWithdrawalRulesRepository)(nil).GetPasswordChangedAt), arg0, arg1)
+}
@@ -3,7 +3,11 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
 	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
 	"strconv"
 	"time"
 )
@@ -11,17 +15,15 @@ import (
 type OrderRepository interface {
 	GetOrCreateOrder(ctx context.Context, orderToCreate domain.OrderDTO) (*domain.OrderDTO, bool, error)
 	GetOrdersByUser(ctx context.Context, user *domain.UserDTO) ([]*domain.OrderDTO, error)
-	UpdateOrderStatusAndAccrual(ctx context.Context, orderNumber string, orderStatus string, accrual float32, tx *sql.Tx) error
-	GetOrdersWithStatusesIn(ctx context.Context, statuses []string) ([]*domain.OrderDTO, error)
+	UpdateOrderStatusAndAccrual(ctx context.Context, orderNumber string, orderStatus string, accrual domain.Money, tx *sql.Tx) error
 }
 
 type OrderService struct {
 	orderRepository OrderRepository
-	orderSender     *OrderSender
 }
 
-func NewOrderService(orderRepository OrderRepository, orderSender *OrderSender) *OrderService {
-	return &OrderService{orderRepository: orderRepository, orderSender: orderSender}
+func NewOrderService(orderRepository OrderRepository) *OrderService {
+	return &OrderService{orderRepository: orderRepository}
 }
 
 func (s *OrderService) GetOrCreateOrder(ctx context.Context, orderToCreate domain.OrderDTO) (*domain.OrderDTO, bool, error) {
@@ -31,15 +33,11 @@ func (s *OrderService) GetOrCreateOrder(ctx context.Context, orderToCreate domai
 		return nil, false, err
 	}
 	// проверяем, каким пользователем был создан заказ
+	// новый заказ ставится в очередь на обработку вместе с созданием, поэтому больше ничего делать не нужно
 	if !created && orderToCreate.UserID != order.UserID {
 		return nil, false, ErrOrderExistsForOtherUser
 	}
 
-	// отправляем номер для дальнейшей обработки заказа
-	if created {
-		s.orderSender.SendOrderToWorkers(orderToCreate.Number)
-	}
-
 	return order, created, nil
 }
 
@@ -47,25 +45,14 @@ func (s *OrderService) GetOrdersByUser(ctx context.Context, user *domain.UserDTO
 	return s.orderRepository.GetOrdersByUser(ctx, user)
 }
 
-func (s *OrderService) UpdateOrderStatusAndAccrual(ctx context.Context, orderNumber string, orderStatus string, accrual float32) error {
-	return s.orderRepository.UpdateOrderStatusAndAccrual(ctx, orderNumber, orderStatus, accrual, nil)
-}
-
-func (s *OrderService) GetUnprocessedOrdersNumbers(ctx context.Context) ([]string, error) {
-	unprocessedOrders, err := s.orderRepository.GetOrdersWithStatusesIn(
-		ctx,
-		[]string{domain.OrderNewStatus, domain.OrderProcessingStatus},
-	)
-	if err != nil {
-		return []string{}, err
-	}
-
-	var ordersNumbers []string
-	for _, order := range unprocessedOrders {
-		ordersNumbers = append(ordersNumbers, order.Number)
+// UpdateOrderStatusAndAccrual обновляет статус заказа, окончательный статус заказа не меняется
+func (s *OrderService) UpdateOrderStatusAndAccrual(ctx context.Context, orderNumber string, orderStatus string, accrual domain.Money) error {
+	err := s.orderRepository.UpdateOrderStatusAndAccrual(ctx, orderNumber, orderStatus, accrual, nil)
+	if errors.Is(err, repositories.ErrOrderStatusIsFinal) {
+		log.Info().Msg(fmt.Sprintf("order '%s' already has a final status, skipping update", orderNumber))
+		return nil
 	}
-
-	return ordersNumbers, nil
+	return err
 }
 
 type OrderNumberValidator struct {
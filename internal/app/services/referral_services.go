@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+)
+
+const (
+	// referralCodeLen длина реферального кода пользователя
+	referralCodeLen = 8
+	// referralCodeAttempts количество попыток сгенерировать уникальный реферальный код
+	referralCodeAttempts = 3
+)
+
+type ReferralRepository interface {
+	GetReferrals(ctx context.Context, referrerID int) ([]*domain.Referral, error)
+	RewardReferral(ctx context.Context, orderNumber string, bonus domain.Money, maxRewards int) (*domain.Referral, error)
+}
+
+// ReferralRules условия начисления бонусов за приглашения
+type ReferralRules struct {
+	// Bonus сумма, начисляемая и пригласившему, и приглашенному пользователю
+	Bonus domain.Money
+	// MaxRewards максимальное количество бонусов, которое может получить пригласивший пользователь, 0 - без ограничения
+	MaxRewards int
+}
+
+type ReferralService struct {
+	referralRepository ReferralRepository
+	rules              ReferralRules
+}
+
+func NewReferralService(referralRepository ReferralRepository, rules ReferralRules) *ReferralService {
+	return &ReferralService{referralRepository: referralRepository, rules: rules}
+}
+
+// GetReferralSummary возвращает реферальный код пользователя и список приглашенных им пользователей
+func (s *ReferralService) GetReferralSummary(ctx context.Context, user *domain.UserDTO) (*domain.ReferralSummary, error) {
+	referrals, err := s.referralRepository.GetReferrals(ctx, user.ID)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not get referrals of user %d: %v", user.ID, err.Error()))
+		return nil, err
+	}
+	if referrals == nil {
+		referrals = []*domain.Referral{}
+	}
+	return &domain.ReferralSummary{ReferralCode: user.ReferralCode, Referrals: referrals}, nil
+}
+
+// ApplyReferralBonus начисляет бонус за приглашение, если заказ orderNumber - первый обработанный заказ
+// приглашенного пользователя
+func (s *ReferralService) ApplyReferralBonus(ctx context.Context, orderNumber string) error {
+	if s.rules.Bonus <= 0 {
+		return nil
+	}
+	referral, err := s.referralRepository.RewardReferral(ctx, orderNumber, s.rules.Bonus, s.rules.MaxRewards)
+	if errors.Is(err, repositories.ErrSelfReferral) {
+		log.Info().Msg(fmt.Sprintf("owner of order '%s' referred themselves, referral bonus is not awarded", orderNumber))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if referral == nil {
+		return nil
+	}
+
+	if referral.Status == domain.ReferralCapReachedStatus {
+		log.Info().Msg(fmt.Sprintf(
+			"user %d reached referral bonuses limit, referral %d is not rewarded", referral.ReferrerID, referral.ID,
+		))
+		return nil
+	}
+	log.Info().Msg(fmt.Sprintf(
+		"awarded referral bonus %v to users %d and %d for order '%s'",
+		referral.Bonus, referral.ReferrerID, referral.ReferredID, orderNumber,
+	))
+	return nil
+}
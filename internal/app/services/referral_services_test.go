@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+)
+
+func TestReferralService_ApplyReferralBonus(t *testing.T) {
+	orderNumber := "123"
+	rules := ReferralRules{Bonus: domain.NewMoney(100, 0), MaxRewards: 5}
+	tests := []struct {
+		name             string
+		rules            ReferralRules
+		shouldCallReward bool
+		referral         *domain.Referral
+		rewardErr        error
+	}{
+		{
+			name:             "bonus is awarded",
+			rules:            rules,
+			shouldCallReward: true,
+			referral:         &domain.Referral{ID: 1, Status: domain.ReferralRewardedStatus, Bonus: rules.Bonus},
+		},
+		{
+			name:             "referrer reached the cap",
+			rules:            rules,
+			shouldCallReward: true,
+			referral:         &domain.Referral{ID: 1, Status: domain.ReferralCapReachedStatus},
+		},
+		{
+			name:             "user was not referred",
+			rules:            rules,
+			shouldCallReward: true,
+		},
+		{
+			name:             "user referred themselves",
+			rules:            rules,
+			shouldCallReward: true,
+			rewardErr:        repositories.ErrSelfReferral,
+		},
+		{
+			name:  "referral bonus is disabled",
+			rules: ReferralRules{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			referralRepositoryMock := mock_services.NewMockReferralRepository(ctrl)
+			if tt.shouldCallReward {
+				referralRepositoryMock.EXPECT().RewardReferral(
+					ctx, orderNumber, tt.rules.Bonus, tt.rules.MaxRewards,
+				).Return(tt.referral, tt.rewardErr)
+			}
+
+			referralService := NewReferralService(referralRepositoryMock, tt.rules)
+			require.NoError(t, referralService.ApplyReferralBonus(ctx, orderNumber))
+		})
+	}
+}
+
+func TestReferralService_GetReferralSummary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	user := &domain.UserDTO{ID: 1, ReferralCode: "ABCD2345"}
+	referralRepositoryMock := mock_services.NewMockReferralRepository(ctrl)
+	referralRepositoryMock.EXPECT().GetReferrals(ctx, user.ID).Return(nil, nil)
+
+	referralService := NewReferralService(referralRepositoryMock, ReferralRules{})
+	summary, err := referralService.GetReferralSummary(ctx, user)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.ReferralSummary{ReferralCode: user.ReferralCode, Referrals: []*domain.Referral{}}, summary)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+type TierRepository interface {
+	GetEarnedPoints(ctx context.Context, since time.Time) ([]*domain.UserTierRecord, error)
+	GetUserEarnedPoints(ctx context.Context, userID int, since time.Time) (domain.Money, error)
+	SaveUserTier(ctx context.Context, record *domain.UserTierRecord) error
+	GetUserTier(ctx context.Context, userID int) (*domain.UserTierRecord, error)
+}
+
+type TierService struct {
+	tierRepository TierRepository
+	tiers          domain.Tiers
+	// period период, за который учитываются заработанные баллы
+	period time.Duration
+}
+
+func NewTierService(tierRepository TierRepository, tiers domain.Tiers, period time.Duration) *TierService {
+	return &TierService{tierRepository: tierRepository, tiers: tiers, period: period}
+}
+
+// RecalculateTiers пересчитывает уровни всех пользователей по баллам, заработанным за период
+func (s *TierService) RecalculateTiers(ctx context.Context) error {
+	now := time.Now()
+	records, err := s.tierRepository.GetEarnedPoints(ctx, now.Add(-s.period))
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		record.Tier = s.tiers.TierFor(record.Earned).Name
+		record.CalculatedAt = now
+		if err := s.tierRepository.SaveUserTier(ctx, record); err != nil {
+			return err
+		}
+	}
+	log.Info().Msg(fmt.Sprintf("recalculated tiers of %d users", len(records)))
+	return nil
+}
+
+// GetUserTier возвращает уровень пользователя, рассчитанный при последнем пересчете,
+// и сколько баллов осталось заработать до следующего уровня
+func (s *TierService) GetUserTier(ctx context.Context, userID int) (*domain.UserTier, error) {
+	record, err := s.tierRepository.GetUserTier(ctx, userID)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not get tier for user: %v", err.Error()))
+		return nil, err
+	}
+	earned, err := s.tierRepository.GetUserEarnedPoints(ctx, userID, time.Now().Add(-s.period))
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not get earned points for user: %v", err.Error()))
+		return nil, err
+	}
+
+	tierName := domain.TierBasic
+	userTier := &domain.UserTier{Earned: earned}
+	if record != nil {
+		tierName = record.Tier
+		userTier.CalculatedAt = &record.CalculatedAt
+	}
+	tier := s.tiers.Get(tierName)
+	userTier.Tier = tier.Name
+	userTier.Multiplier = tier.Multiplier
+	if next, ok := s.tiers.Next(tier.Name); ok {
+		userTier.NextTier = next.Name
+		if earned < next.Threshold {
+			userTier.PointsToNextTier = next.Threshold - earned
+		}
+	}
+
+	return userTier, nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+	"time"
+)
+
+var testTiers = domain.Tiers{
+	{Name: domain.TierSilver, Threshold: domain.NewMoney(1000, 0), Multiplier: 1.1},
+	{Name: domain.TierGold, Threshold: domain.NewMoney(5000, 0), Multiplier: 1.25},
+	{Name: domain.TierPlatinum, Threshold: domain.NewMoney(20000, 0), Multiplier: 1.5},
+}
+
+func TestTierService_RecalculateTiers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	tierRepositoryMock := mock_services.NewMockTierRepository(ctrl)
+	tierRepositoryMock.EXPECT().GetEarnedPoints(ctx, gomock.Any()).Return([]*domain.UserTierRecord{
+		{UserID: 1, Earned: domain.NewMoney(500, 0)},
+		{UserID: 2, Earned: domain.NewMoney(7000, 0)},
+	}, nil)
+	var saved []*domain.UserTierRecord
+	tierRepositoryMock.EXPECT().SaveUserTier(ctx, gomock.Any()).Times(2).DoAndReturn(
+		func(_ context.Context, record *domain.UserTierRecord) error {
+			saved = append(saved, record)
+			return nil
+		},
+	)
+
+	tierService := NewTierService(tierRepositoryMock, testTiers, 365*24*time.Hour)
+	require.NoError(t, tierService.RecalculateTiers(ctx))
+	require.Len(t, saved, 2)
+	assert.Equal(t, domain.TierBasic, saved[0].Tier)
+	assert.Equal(t, domain.TierGold, saved[1].Tier)
+}
+
+func TestTierService_GetUserTier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	tierRepositoryMock := mock_services.NewMockTierRepository(ctrl)
+	tierRepositoryMock.EXPECT().GetUserTier(ctx, 1).Return(
+		&domain.UserTierRecord{UserID: 1, Tier: domain.TierSilver, CalculatedAt: time.Now()}, nil,
+	)
+	tierRepositoryMock.EXPECT().GetUserEarnedPoints(ctx, 1, gomock.Any()).Return(domain.NewMoney(4000, 0), nil)
+
+	tierService := NewTierService(tierRepositoryMock, testTiers, 365*24*time.Hour)
+	userTier, err := tierService.GetUserTier(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TierSilver, userTier.Tier)
+	assert.Equal(t, 1.1, userTier.Multiplier)
+	assert.Equal(t, domain.TierGold, userTier.NextTier)
+	assert.Equal(t, domain.NewMoney(1000, 0), userTier.PointsToNextTier)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+)
+
+type TransferRepository interface {
+	CreateTransfer(ctx context.Context, transfer *domain.Transfer, dailyLimit domain.Money) error
+	GetTransfersByUser(ctx context.Context, userID int) ([]*domain.Transfer, error)
+}
+
+// TransferUserService поиск получателя перевода по логину
+type TransferUserService interface {
+	GetUserByLogin(ctx context.Context, username string) (*domain.UserDTO, error)
+}
+
+// TransferCooldownChecker запрет переводов вскоре после смены пароля
+type TransferCooldownChecker interface {
+	// CheckPasswordCooldown при недавней смене пароля возвращает *WithdrawalRuleError
+	CheckPasswordCooldown(ctx context.Context, userID int) error
+}
+
+// TransferLimits ограничения на переводы баллов, нулевое значение означает отсутствие ограничения
+type TransferLimits struct {
+	MinSum     domain.Money
+	MaxSum     domain.Money
+	DailyLimit domain.Money
+}
+
+type TransferService struct {
+	transferRepository TransferRepository
+	userService        TransferUserService
+	limits             TransferLimits
+	cooldown           TransferCooldownChecker
+}
+
+func NewTransferService(
+	transferRepository TransferRepository, userService TransferUserService, limits TransferLimits,
+	cooldown TransferCooldownChecker,
+) *TransferService {
+	return &TransferService{
+		transferRepository: transferRepository, userService: userService, limits: limits, cooldown: cooldown,
+	}
+}
+
+// TransferPoints переводит баллы пользователя sender пользователю с логином recipientLogin
+func (s *TransferService) TransferPoints(
+	ctx context.Context, sender *domain.UserDTO, recipientLogin string, sum domain.Money, note string,
+) (*domain.Transfer, error) {
+	recipient, err := s.userService.GetUserByLogin(ctx, recipientLogin)
+	if errors.Is(err, ErrUserDoesNotExist) {
+		return nil, ErrTransferRecipientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if recipient.ID == sender.ID {
+		return nil, ErrSelfTransfer
+	}
+
+	if err := s.checkLimits(sum); err != nil {
+		return nil, err
+	}
+	if err := s.cooldown.CheckPasswordCooldown(ctx, sender.ID); err != nil {
+		return nil, err
+	}
+
+	transfer := &domain.Transfer{
+		SenderID:    sender.ID,
+		RecipientID: recipient.ID,
+		Sender:      sender.Login,
+		Recipient:   recipient.Login,
+		Sum:         sum,
+		Note:        note,
+		Direction:   domain.TransferOutgoingDirection,
+	}
+	err = s.transferRepository.CreateTransfer(ctx, transfer, s.limits.DailyLimit)
+	if errors.Is(err, repositories.ErrTransferLimitExceeded) {
+		return nil, ErrTransferLimitExceeded
+	}
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not transfer points to user %d: %v", recipient.ID, err.Error()))
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// checkLimits проверяет, что сумма sum не выходит за ограничения на один перевод
+// дневной лимит проверяется в репозитории после блокировки баланса отправителя
+func (s *TransferService) checkLimits(sum domain.Money) error {
+	if s.limits.MinSum > 0 && sum < s.limits.MinSum {
+		return ErrTransferLimitExceeded
+	}
+	if s.limits.MaxSum > 0 && sum > s.limits.MaxSum {
+		return ErrTransferLimitExceeded
+	}
+	return nil
+}
+
+// GetUserTransfers возвращает историю переводов пользователя с направлением относительно него
+func (s *TransferService) GetUserTransfers(ctx context.Context, userID int) ([]*domain.Transfer, error) {
+	transfers, err := s.transferRepository.GetTransfersByUser(ctx, userID)
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("can not get transfers for user: %v", err.Error()))
+		return nil, err
+	}
+
+	for _, transfer := range transfers {
+		transfer.Direction = domain.TransferIncomingDirection
+		if transfer.SenderID == userID {
+			transfer.Direction = domain.TransferOutgoingDirection
+		}
+	}
+	return transfers, nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+)
+
+func TestTransferService_TransferPoints(t *testing.T) {
+	sender := &domain.UserDTO{ID: 1, Login: "sender"}
+	recipient := &domain.UserDTO{ID: 2, Login: "recipient"}
+	limits := TransferLimits{
+		MinSum:     domain.NewMoney(1, 0),
+		MaxSum:     domain.NewMoney(500, 0),
+		DailyLimit: domain.NewMoney(1000, 0),
+	}
+
+	tests := []struct {
+		name              string
+		recipientLogin    string
+		recipient         *domain.UserDTO
+		recipientErr      error
+		sum               domain.Money
+		shouldCheck       bool
+		cooldownErr       error
+		shouldCreate      bool
+		createTransferErr error
+		wantErr           error
+	}{
+		{
+			name:           "positive test",
+			recipientLogin: recipient.Login,
+			recipient:      recipient,
+			sum:            domain.NewMoney(100, 0),
+			shouldCheck:    true,
+			shouldCreate:   true,
+		},
+		{
+			name:           "recipient does not exist",
+			recipientLogin: "unknown",
+			recipientErr:   ErrUserDoesNotExist,
+			sum:            domain.NewMoney(100, 0),
+			wantErr:        ErrTransferRecipientNotFound,
+		},
+		{
+			name:           "transfer to yourself",
+			recipientLogin: sender.Login,
+			recipient:      sender,
+			sum:            domain.NewMoney(100, 0),
+			wantErr:        ErrSelfTransfer,
+		},
+		{
+			name:           "sum is less than minimum",
+			recipientLogin: recipient.Login,
+			recipient:      recipient,
+			sum:            domain.NewMoney(0, 50),
+			wantErr:        ErrTransferLimitExceeded,
+		},
+		{
+			name:           "sum is greater than maximum",
+			recipientLogin: recipient.Login,
+			recipient:      recipient,
+			sum:            domain.NewMoney(501, 0),
+			wantErr:        ErrTransferLimitExceeded,
+		},
+		{
+			name:           "password was changed recently",
+			recipientLogin: recipient.Login,
+			recipient:      recipient,
+			sum:            domain.NewMoney(100, 0),
+			shouldCheck:    true,
+			cooldownErr:    ErrWithdrawalPasswordCooldown,
+			wantErr:        ErrWithdrawalPasswordCooldown,
+		},
+		{
+			name:              "daily limit exceeded",
+			recipientLogin:    recipient.Login,
+			recipient:         recipient,
+			sum:               domain.NewMoney(100, 0),
+			shouldCheck:       true,
+			shouldCreate:      true,
+			createTransferErr: repositories.ErrTransferLimitExceeded,
+			wantErr:           ErrTransferLimitExceeded,
+		},
+		{
+			name:              "not enough points",
+			recipientLogin:    recipient.Login,
+			recipient:         recipient,
+			sum:               domain.NewMoney(100, 0),
+			shouldCheck:       true,
+			shouldCreate:      true,
+			createTransferErr: repositories.ErrCanNotWithdrawBalance,
+			wantErr:           repositories.ErrCanNotWithdrawBalance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			userServiceMock := mock_services.NewMockTransferUserService(ctrl)
+			userServiceMock.EXPECT().GetUserByLogin(ctx, tt.recipientLogin).Return(tt.recipient, tt.recipientErr)
+			cooldownMock := mock_services.NewMockTransferCooldownChecker(ctrl)
+			if tt.shouldCheck {
+				cooldownMock.EXPECT().CheckPasswordCooldown(ctx, sender.ID).Return(tt.cooldownErr)
+			}
+			transferRepositoryMock := mock_services.NewMockTransferRepository(ctrl)
+			if tt.shouldCreate {
+				transferRepositoryMock.EXPECT().CreateTransfer(ctx, gomock.Any(), limits.DailyLimit).Return(tt.createTransferErr)
+			}
+
+			transferService := NewTransferService(transferRepositoryMock, userServiceMock, limits, cooldownMock)
+			transfer, err := transferService.TransferPoints(ctx, sender, tt.recipientLogin, tt.sum, "")
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, recipient.ID, transfer.RecipientID)
+				assert.Equal(t, tt.sum, transfer.Sum)
+			}
+		})
+	}
+}
+
+func TestTransferService_GetUserTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	transfers := []*domain.Transfer{
+		{ID: 1, SenderID: 1, RecipientID: 2, Sum: domain.NewMoney(10, 0)},
+		{ID: 2, SenderID: 2, RecipientID: 1, Sum: domain.NewMoney(5, 0)},
+	}
+	transferRepositoryMock := mock_services.NewMockTransferRepository(ctrl)
+	transferRepositoryMock.EXPECT().GetTransfersByUser(ctx, 1).Return(transfers, nil)
+
+	transferService := NewTransferService(transferRepositoryMock, nil, TransferLimits{}, nil)
+	res, err := transferService.GetUserTransfers(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TransferOutgoingDirection, res[0].Direction)
+	assert.Equal(t, domain.TransferIncomingDirection, res[1].Direction)
+}
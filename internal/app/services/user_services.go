@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 	"gophermart/internal/app/domain"
 	"gophermart/internal/app/repositories"
@@ -13,15 +15,20 @@ const PasswordHashCost = 10
 type UserRepository interface {
 	CreateUser(ctx context.Context, user domain.UserDTO) error
 	GetUserByLogin(ctx context.Context, username string) (*domain.UserDTO, error)
-	IncreaseBalanceAndUpdateOrderStatus(ctx context.Context, orderNumber string, accrual float32, orderStatus string) error
+	ChangePassword(ctx context.Context, userID int, password string) error
+	IncreaseBalanceAndUpdateOrderStatus(
+		ctx context.Context, orderNumber string, accrual domain.Money, orderStatus string, tiers domain.Tiers,
+	) error
 }
 
 type UserService struct {
 	userRepository UserRepository
+	// tiers уровни программы лояльности, начисления за заказы умножаются на множитель уровня пользователя
+	tiers domain.Tiers
 }
 
-func NewUserService(userRepository UserRepository) *UserService {
-	return &UserService{userRepository: userRepository}
+func NewUserService(userRepository UserRepository, tiers domain.Tiers) *UserService {
+	return &UserService{userRepository: userRepository, tiers: tiers}
 }
 
 func (s *UserService) CreateUser(ctx context.Context, user domain.UserDTO) error {
@@ -31,7 +38,20 @@ func (s *UserService) CreateUser(ctx context.Context, user domain.UserDTO) error
 		return err
 	}
 	user.Password = string(hashedPwd)
-	return s.userRepository.CreateUser(ctx, user)
+	// код пригласившего пользователя вводится вручную, поэтому регистр, пробелы и дефисы в нем не важны
+	user.ReferrerCode = normalizeCode(user.ReferrerCode)
+
+	// при совпадении реферального кода с уже существующим генерируем новый
+	for attempt := 1; ; attempt++ {
+		user.ReferralCode, err = generateCode(referralCodeLen)
+		if err != nil {
+			return err
+		}
+		err = s.userRepository.CreateUser(ctx, user)
+		if !errors.Is(err, repositories.ErrReferralCodeAlreadyExists) || attempt == referralCodeAttempts {
+			return err
+		}
+	}
 }
 
 func (s *UserService) GetUserByLogin(ctx context.Context, username string) (*domain.UserDTO, error) {
@@ -43,6 +63,31 @@ func (s *UserService) GetUserByLogin(ctx context.Context, username string) (*dom
 	return user, err
 }
 
-func (s *UserService) IncreaseBalanceAndUpdateOrderStatus(ctx context.Context, orderNumber string, accrual float32, orderStatus string) error {
-	return s.userRepository.IncreaseBalanceAndUpdateOrderStatus(ctx, orderNumber, accrual, orderStatus)
+// ChangePassword меняет пароль пользователя после проверки текущего пароля
+// время смены пароля учитывается правилами списания баллов
+func (s *UserService) ChangePassword(ctx context.Context, user *domain.UserDTO, oldPassword string, newPassword string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrInvalidCredentials
+	}
+	if err != nil {
+		return err
+	}
+
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(newPassword), PasswordHashCost)
+	if err != nil {
+		return err
+	}
+	return s.userRepository.ChangePassword(ctx, user.ID, string(hashedPwd))
+}
+
+// IncreaseBalanceAndUpdateOrderStatus начисляет баллы за заказ с учетом уровня пользователя и обновляет статус заказа
+// если заказ уже обработан, например другим экземпляром сервиса, то баллы повторно не начисляются
+func (s *UserService) IncreaseBalanceAndUpdateOrderStatus(ctx context.Context, orderNumber string, accrual domain.Money, orderStatus string) error {
+	err := s.userRepository.IncreaseBalanceAndUpdateOrderStatus(ctx, orderNumber, accrual, orderStatus, s.tiers)
+	if errors.Is(err, repositories.ErrOrderStatusIsFinal) {
+		log.Info().Msg(fmt.Sprintf("order '%s' already has a final status, skipping accrual", orderNumber))
+		return nil
+	}
+	return err
 }
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+)
+
+func TestUserService_IncreaseBalanceAndUpdateOrderStatus(t *testing.T) {
+	orderNumber := "123"
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{
+			name: "order is credited",
+		},
+		{
+			name:    "order was already processed",
+			repoErr: repositories.ErrOrderStatusIsFinal,
+		},
+		{
+			name:    "order does not exist",
+			repoErr: repositories.ErrOrderDoesNotExist,
+			wantErr: repositories.ErrOrderDoesNotExist,
+		},
+		{
+			name:    "database error",
+			repoErr: errors.New("connection refused"),
+			wantErr: errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepositoryMock := mock_services.NewMockUserRepository(ctrl)
+			userRepositoryMock.EXPECT().IncreaseBalanceAndUpdateOrderStatus(
+				gomock.Any(), orderNumber, domain.NewMoney(100, 0), domain.OrderProcessedStatus, testTiers,
+			).Return(tt.repoErr)
+
+			userService := NewUserService(userRepositoryMock, testTiers)
+			err := userService.IncreaseBalanceAndUpdateOrderStatus(
+				context.Background(), orderNumber, domain.NewMoney(100, 0), domain.OrderProcessedStatus,
+			)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestUserService_CreateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userRepositoryMock := mock_services.NewMockUserRepository(ctrl)
+	var referralCodes []string
+	userRepositoryMock.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, user domain.UserDTO) error {
+			assert.Equal(t, "ABCD2345", user.ReferrerCode)
+			assert.Len(t, user.ReferralCode, referralCodeLen)
+			referralCodes = append(referralCodes, user.ReferralCode)
+			// первый сгенерированный код уже занят
+			if len(referralCodes) == 1 {
+				return repositories.ErrReferralCodeAlreadyExists
+			}
+			return nil
+		},
+	).Times(2)
+
+	userService := NewUserService(userRepositoryMock, testTiers)
+	err := userService.CreateUser(
+		context.Background(), domain.UserDTO{Login: "John", Password: "secret", ReferrerCode: " abcd-2345 "},
+	)
+	assert.NoError(t, err)
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte("old"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &domain.UserDTO{ID: 1, Login: "John", Password: string(hashedPwd)}
+
+	tests := []struct {
+		name         string
+		oldPassword  string
+		shouldChange bool
+		wantErr      error
+	}{
+		{
+			name:         "password is changed",
+			oldPassword:  "old",
+			shouldChange: true,
+		},
+		{
+			name:        "wrong current password",
+			oldPassword: "wrong",
+			wantErr:     ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			userRepositoryMock := mock_services.NewMockUserRepository(ctrl)
+			if tt.shouldChange {
+				userRepositoryMock.EXPECT().ChangePassword(ctx, user.ID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int, password string) error {
+						assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(password), []byte("new")))
+						return nil
+					},
+				)
+			}
+
+			userService := NewUserService(userRepositoryMock, testTiers)
+			err := userService.ChangePassword(ctx, user, tt.oldPassword, "new")
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	"time"
+)
+
+const (
+	// voucherCodeLen длина кода ваучера
+	voucherCodeLen = 12
+	// voucherBatchAttempts количество попыток сгенерировать партию ваучеров с уникальными кодами
+	voucherBatchAttempts = 3
+)
+
+type VoucherRepository interface {
+	CreateVoucherBatch(ctx context.Context, batch *domain.VoucherBatch) error
+	GetVoucherBatch(ctx context.Context, batchID int) (*domain.VoucherBatch, error)
+	RedeemVoucher(ctx context.Context, code string, userID int, now time.Time) (*domain.VoucherRedemption, error)
+}
+
+type VoucherService struct {
+	voucherRepository VoucherRepository
+}
+
+func NewVoucherService(voucherRepository VoucherRepository) *VoucherService {
+	return &VoucherService{voucherRepository: voucherRepository}
+}
+
+// GenerateVouchers создает партию из count ваучеров со случайными кодами
+func (s *VoucherService) GenerateVouchers(ctx context.Context, batch *domain.VoucherBatch, count int) error {
+	if !batch.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: vouchers must expire in the future", ErrInvalidVoucherBatch)
+	}
+
+	// при совпадении кода с уже существующим генерируем партию заново
+	for attempt := 1; ; attempt++ {
+		batch.Vouchers = make([]*domain.Voucher, count)
+		for i := range batch.Vouchers {
+			code, err := generateCode(voucherCodeLen)
+			if err != nil {
+				return err
+			}
+			batch.Vouchers[i] = &domain.Voucher{Code: code}
+		}
+
+		err := s.voucherRepository.CreateVoucherBatch(ctx, batch)
+		if errors.Is(err, repositories.ErrVoucherCodeAlreadyExists) && attempt < voucherBatchAttempts {
+			continue
+		}
+		if err != nil {
+			log.Error().Msg(fmt.Sprintf("can not create voucher batch: %v", err.Error()))
+			return err
+		}
+		log.Info().Msg(fmt.Sprintf(
+			"admin %d created voucher batch %d of %d vouchers", batch.CreatedBy, batch.ID, count,
+		))
+		return nil
+	}
+}
+
+func (s *VoucherService) GetVoucherBatch(ctx context.Context, batchID int) (*domain.VoucherBatch, error) {
+	return s.voucherRepository.GetVoucherBatch(ctx, batchID)
+}
+
+// RedeemVoucher гасит ваучер и начисляет его номинал на баланс пользователя
+func (s *VoucherService) RedeemVoucher(ctx context.Context, code string, userID int) (*domain.VoucherRedemption, error) {
+	redemption, err := s.voucherRepository.RedeemVoucher(ctx, normalizeCode(code), userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Msg(fmt.Sprintf(
+		"user %d redeemed voucher %d for %v points", userID, redemption.VoucherID, redemption.Sum,
+	))
+	return redemption, nil
+}
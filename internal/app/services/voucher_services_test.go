@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/repositories"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+	"time"
+)
+
+func TestVoucherService_GenerateVouchers(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiresAt  time.Time
+		createErrs []error
+		wantErr    error
+	}{
+		{
+			name:       "positive test",
+			expiresAt:  time.Now().Add(time.Hour),
+			createErrs: []error{nil},
+		},
+		{
+			name:       "code collision is retried",
+			expiresAt:  time.Now().Add(time.Hour),
+			createErrs: []error{repositories.ErrVoucherCodeAlreadyExists, nil},
+		},
+		{
+			name:      "expired batch",
+			expiresAt: time.Now().Add(-time.Hour),
+			wantErr:   ErrInvalidVoucherBatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			voucherRepositoryMock := mock_services.NewMockVoucherRepository(ctrl)
+			for _, createErr := range tt.createErrs {
+				voucherRepositoryMock.EXPECT().CreateVoucherBatch(ctx, gomock.Any()).Return(createErr)
+			}
+
+			batch := &domain.VoucherBatch{Value: domain.NewMoney(500, 0), ExpiresAt: tt.expiresAt, UsageLimit: 1}
+			voucherService := NewVoucherService(voucherRepositoryMock)
+			err := voucherService.GenerateVouchers(ctx, batch, 3)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, batch.Vouchers, 3)
+			for _, voucher := range batch.Vouchers {
+				assert.Len(t, voucher.Code, voucherCodeLen)
+			}
+		})
+	}
+}
+
+func TestVoucherService_RedeemVoucher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	redemption := &domain.VoucherRedemption{VoucherID: 1, UserID: 2, Sum: domain.NewMoney(500, 0)}
+	voucherRepositoryMock := mock_services.NewMockVoucherRepository(ctrl)
+	// код, введенный пользователем, приводится к виду, в котором он хранится
+	voucherRepositoryMock.EXPECT().RedeemVoucher(ctx, "ABCD2345EFGH", 2, gomock.Any()).Return(redemption, nil)
+
+	voucherService := NewVoucherService(voucherRepositoryMock)
+	actual, err := voucherService.RedeemVoucher(ctx, "abcd-2345-efgh", 2)
+	require.NoError(t, err)
+	assert.Equal(t, redemption, actual)
+}
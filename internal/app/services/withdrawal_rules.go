@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"gophermart/internal/app/domain"
+	"time"
+)
+
+const (
+	withdrawalDailyPeriod   = 24 * time.Hour
+	withdrawalMonthlyPeriod = 30 * 24 * time.Hour
+)
+
+// WithdrawalRuleError отказ в списании баллов из-за нарушения правил списания
+// Code - машиночитаемый код отказа, который возвращается клиенту
+type WithdrawalRuleError struct {
+	Code    string
+	Message string
+}
+
+func (e *WithdrawalRuleError) Error() string {
+	return e.Message
+}
+
+var ErrWithdrawalBelowMinimum = &WithdrawalRuleError{
+	Code: "WITHDRAWAL_BELOW_MINIMUM", Message: "Withdrawal sum is less than the minimum redemption amount",
+}
+var ErrWithdrawalAboveMaximum = &WithdrawalRuleError{
+	Code: "WITHDRAWAL_ABOVE_MAXIMUM", Message: "Withdrawal sum exceeds the maximum per withdrawal",
+}
+var ErrWithdrawalDailyLimitExceeded = &WithdrawalRuleError{
+	Code: "DAILY_LIMIT_EXCEEDED", Message: "Daily withdrawal limit exceeded",
+}
+var ErrWithdrawalMonthlyLimitExceeded = &WithdrawalRuleError{
+	Code: "MONTHLY_LIMIT_EXCEEDED", Message: "Monthly withdrawal limit exceeded",
+}
+var ErrWithdrawalPasswordCooldown = &WithdrawalRuleError{
+	Code: "PASSWORD_CHANGE_COOLDOWN", Message: "Withdrawals are not allowed shortly after a password change",
+}
+
+type WithdrawalRulesRepository interface {
+	// GetPasswordChangedAt возвращает время последней смены пароля или nil, если пароль не менялся
+	GetPasswordChangedAt(ctx context.Context, userID int) (*time.Time, error)
+}
+
+// WithdrawalRuleChecker проверка списаний и резервов баллов по правилам списания
+type WithdrawalRuleChecker interface {
+	// Check проверяет, может ли пользователь списать сумму sum, при нарушении правил возвращает *WithdrawalRuleError
+	Check(ctx context.Context, userID int, sum domain.Money) error
+	// Caps возвращает ограничения на сумму списаний за период, действующие в момент now
+	Caps(now time.Time) []domain.WithdrawalCap
+}
+
+// WithdrawalLimits ограничения на списание баллов, нулевое значение означает отсутствие ограничения
+type WithdrawalLimits struct {
+	MinSum     domain.Money
+	MaxSum     domain.Money
+	DailyCap   domain.Money
+	MonthlyCap domain.Money
+	// PasswordChangeCooldown время после смены пароля, в течение которого списания запрещены
+	PasswordChangeCooldown time.Duration
+}
+
+// WithdrawalRules проверяет списания и резервы баллов на соответствие правилам списания
+type WithdrawalRules struct {
+	repository WithdrawalRulesRepository
+	limits     WithdrawalLimits
+}
+
+func NewWithdrawalRules(repository WithdrawalRulesRepository, limits WithdrawalLimits) *WithdrawalRules {
+	return &WithdrawalRules{repository: repository, limits: limits}
+}
+
+// Check проверяет, может ли пользователь списать сумму sum
+// при нарушении правил возвращает *WithdrawalRuleError, ограничения за период проверяются отдельно, см. Caps
+func (r *WithdrawalRules) Check(ctx context.Context, userID int, sum domain.Money) error {
+	if r.limits.MinSum > 0 && sum < r.limits.MinSum {
+		return ErrWithdrawalBelowMinimum
+	}
+	if r.limits.MaxSum > 0 && sum > r.limits.MaxSum {
+		return ErrWithdrawalAboveMaximum
+	}
+
+	return r.CheckPasswordCooldown(ctx, userID)
+}
+
+// CheckPasswordCooldown проверяет, что с последней смены пароля пользователя прошло не меньше PasswordChangeCooldown,
+// иначе возвращает ErrWithdrawalPasswordCooldown
+// проверка действует и для переводов баллов, чтобы получивший доступ к аккаунту не мог сменить пароль и вывести баллы
+func (r *WithdrawalRules) CheckPasswordCooldown(ctx context.Context, userID int) error {
+	if r.limits.PasswordChangeCooldown <= 0 {
+		return nil
+	}
+	changedAt, err := r.repository.GetPasswordChangedAt(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if changedAt != nil && time.Now().Before(changedAt.Add(r.limits.PasswordChangeCooldown)) {
+		return ErrWithdrawalPasswordCooldown
+	}
+	return nil
+}
+
+// Caps возвращает ограничения на сумму списаний и активных резервов за сутки и за месяц
+// ограничения проверяются в репозитории после блокировки баланса пользователя,
+// иначе параллельные списания могли бы вместе их превысить
+func (r *WithdrawalRules) Caps(now time.Time) []domain.WithdrawalCap {
+	var caps []domain.WithdrawalCap
+	if r.limits.DailyCap > 0 {
+		caps = append(caps, domain.WithdrawalCap{
+			Limit: r.limits.DailyCap, Since: now.Add(-withdrawalDailyPeriod), Err: ErrWithdrawalDailyLimitExceeded,
+		})
+	}
+	if r.limits.MonthlyCap > 0 {
+		caps = append(caps, domain.WithdrawalCap{
+			Limit: r.limits.MonthlyCap, Since: now.Add(-withdrawalMonthlyPeriod), Err: ErrWithdrawalMonthlyLimitExceeded,
+		})
+	}
+	return caps
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gophermart/internal/app/domain"
+	mock_services "gophermart/internal/app/services/mocks"
+	"testing"
+	"time"
+)
+
+func TestWithdrawalRules_Check(t *testing.T) {
+	limits := WithdrawalLimits{
+		MinSum:                 domain.NewMoney(10, 0),
+		MaxSum:                 domain.NewMoney(500, 0),
+		DailyCap:               domain.NewMoney(600, 0),
+		MonthlyCap:             domain.NewMoney(2000, 0),
+		PasswordChangeCooldown: 24 * time.Hour,
+	}
+	longAgo := time.Now().Add(-48 * time.Hour)
+	recently := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name              string
+		sum               domain.Money
+		passwordChangedAt *time.Time
+		checkCooldown     bool
+		wantErr           error
+	}{
+		{
+			name:              "positive test",
+			sum:               domain.NewMoney(100, 0),
+			passwordChangedAt: &longAgo,
+			checkCooldown:     true,
+		},
+		{
+			name:          "password was never changed",
+			sum:           domain.NewMoney(100, 0),
+			checkCooldown: true,
+		},
+		{
+			name:    "below minimum",
+			sum:     domain.NewMoney(9, 99),
+			wantErr: ErrWithdrawalBelowMinimum,
+		},
+		{
+			name:    "above maximum",
+			sum:     domain.NewMoney(500, 1),
+			wantErr: ErrWithdrawalAboveMaximum,
+		},
+		{
+			name:              "password changed recently",
+			sum:               domain.NewMoney(100, 0),
+			passwordChangedAt: &recently,
+			checkCooldown:     true,
+			wantErr:           ErrWithdrawalPasswordCooldown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			repositoryMock := mock_services.NewMockWithdrawalRulesRepository(ctrl)
+			if tt.checkCooldown {
+				repositoryMock.EXPECT().GetPasswordChangedAt(ctx, 1).Return(tt.passwordChangedAt, nil)
+			}
+
+			rules := NewWithdrawalRules(repositoryMock, limits)
+			err := rules.Check(ctx, 1, tt.sum)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestWithdrawalRules_Caps(t *testing.T) {
+	now := time.Now()
+	rules := NewWithdrawalRules(nil, WithdrawalLimits{
+		DailyCap:   domain.NewMoney(600, 0),
+		MonthlyCap: domain.NewMoney(2000, 0),
+	})
+	assert.Equal(t, []domain.WithdrawalCap{
+		{Limit: domain.NewMoney(600, 0), Since: now.Add(-24 * time.Hour), Err: ErrWithdrawalDailyLimitExceeded},
+		{Limit: domain.NewMoney(2000, 0), Since: now.Add(-30 * 24 * time.Hour), Err: ErrWithdrawalMonthlyLimitExceeded},
+	}, rules.Caps(now))
+
+	// нулевое ограничение не проверяется
+	rules = NewWithdrawalRules(nil, WithdrawalLimits{MonthlyCap: domain.NewMoney(2000, 0)})
+	assert.Len(t, rules.Caps(now), 1)
+	assert.Empty(t, NewWithdrawalRules(nil, WithdrawalLimits{}).Caps(now))
+}
@@ -0,0 +1,64 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"sync"
+)
+
+// Worker воркер, который работает до отмены контекста
+type Worker interface {
+	Run(ctx context.Context, wg *sync.WaitGroup)
+}
+
+// AccrualWorkerPool пул воркеров, обрабатывающих заказы из общей очереди
+// размер пула можно менять во время работы сервиса
+type AccrualWorkerPool struct {
+	ctx       context.Context
+	wg        *sync.WaitGroup
+	newWorker func() Worker
+	mu        sync.Mutex
+	// cancels функции остановки запущенных воркеров
+	cancels []context.CancelFunc
+}
+
+// NewAccrualWorkerPool создает пустой пул, воркеры которого останавливаются вместе с контекстом ctx
+func NewAccrualWorkerPool(ctx context.Context, wg *sync.WaitGroup, newWorker func() Worker) *AccrualWorkerPool {
+	return &AccrualWorkerPool{ctx: ctx, wg: wg, newWorker: newWorker}
+}
+
+// Size возвращает количество запущенных воркеров
+func (p *AccrualWorkerPool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.cancels)
+}
+
+// Resize запускает недостающих или останавливает лишних воркеров
+// остановленный воркер возвращает захваченные задания в очередь, и их забирают оставшиеся воркеры
+// после остановки пула вместе с контекстом размер не меняется: новых воркеров уже не дождался бы WaitWorkersToStop
+func (p *AccrualWorkerPool) Resize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ctx.Err() != nil {
+		log.Info().Msg("accrual worker pool is stopped, resizing is ignored")
+		return
+	}
+
+	log.Info().Msg(fmt.Sprintf("resizing accrual worker pool from %d to %d", len(p.cancels), size))
+	for len(p.cancels) < size {
+		ctx, cancel := context.WithCancel(p.ctx)
+		p.cancels = append(p.cancels, cancel)
+		p.wg.Add(1)
+		go p.newWorker().Run(ctx, p.wg)
+	}
+	for len(p.cancels) > size {
+		last := len(p.cancels) - 1
+		p.cancels[last]()
+		p.cancels = p.cancels[:last]
+	}
+}
@@ -0,0 +1,59 @@
+package workers
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingWorker считает запущенных воркеров и работает до отмены контекста
+type countingWorker struct {
+	running *int32
+}
+
+func (w *countingWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	atomic.AddInt32(w.running, 1)
+	<-ctx.Done()
+	atomic.AddInt32(w.running, -1)
+}
+
+func TestAccrualWorkerPool_Resize(t *testing.T) {
+	var running int32
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	pool := NewAccrualWorkerPool(ctx, wg, func() Worker { return &countingWorker{running: &running} })
+	runningWorkers := func(n int32) func() bool {
+		return func() bool { return atomic.LoadInt32(&running) == n }
+	}
+
+	pool.Resize(3)
+	assert.Equal(t, 3, pool.Size())
+	require.Eventually(t, runningWorkers(3), time.Second, time.Millisecond)
+
+	// лишние воркеры останавливаются
+	pool.Resize(1)
+	assert.Equal(t, 1, pool.Size())
+	require.Eventually(t, runningWorkers(1), time.Second, time.Millisecond)
+
+	pool.Resize(-1)
+	assert.Equal(t, 0, pool.Size())
+	require.Eventually(t, runningWorkers(0), time.Second, time.Millisecond)
+
+	// при остановке сервиса останавливаются все воркеры пула
+	pool.Resize(2)
+	require.Eventually(t, runningWorkers(2), time.Second, time.Millisecond)
+	cancel()
+	wg.Wait()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&running))
+
+	// после остановки пул не запускает новых воркеров
+	pool.Resize(5)
+	assert.Equal(t, 2, pool.Size())
+	wg.Wait()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&running))
+}
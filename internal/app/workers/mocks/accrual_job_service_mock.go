@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/workers (interfaces: AccrualJobService)
+
+// Package mock_workers is a generated GoMock package.
+package mock_workers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAccrualJobService is a mock of AccrualJobService interface.
+type MockAccrualJobService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualJobServiceMockRecorder
+}
+
+// MockAccrualJobServiceMockRecorder is the mock recorder for MockAccrualJobService.
+type MockAccrualJobServiceMockRecorder struct {
+	mock *MockAccrualJobService
+}
+
+// NewMockAccrualJobService creates a new mock instance.
+func NewMockAccrualJobService(ctrl *gomock.Controller) *MockAccrualJobService {
+	mock := &MockAccrualJobService{ctrl: ctrl}
+	mock.recorder = &MockAccrualJobServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualJobService) EXPECT() *MockAccrualJobServiceMockRecorder {
+	return m.recorder
+}
+
+// ClaimJobs mocks base method.
+func (m *MockAccrualJobService) ClaimJobs(arg0 context.Context, arg1 int) ([]*domain.AccrualJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJobs", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.AccrualJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJobs indicates an expected call of ClaimJobs.
+func (mr *MockAccrualJobServiceMockRecorder) ClaimJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJobs", reflect.TypeOf((*MockAccrualJobService)(nil).ClaimJobs), arg0, arg1)
+}
+
+// CompleteJob mocks base method.
+func (m *MockAccrualJobService) CompleteJob(arg0 context.Context, arg1 *domain.AccrualJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteJob indicates an expected call of CompleteJob.
+func (mr *MockAccrualJobServiceMockRecorder) CompleteJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteJob", reflect.TypeOf((*MockAccrualJobService)(nil).CompleteJob), arg0, arg1)
+}
+
+// PollJobLater mocks base method.
+func (m *MockAccrualJobService) PollJobLater(arg0 context.Context, arg1 *domain.AccrualJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PollJobLater", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PollJobLater indicates an expected call of PollJobLater.
+func (mr *MockAccrualJobServiceMockRecorder) PollJobLater(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PollJobLater", reflect.TypeOf((*MockAccrualJobService)(nil).PollJobLater), arg0, arg1)
+}
+
+// PostponeJob mocks base method.
+func (m *MockAccrualJobService) PostponeJob(arg0 context.Context, arg1 *domain.AccrualJob, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostponeJob", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostponeJob indicates an expected call of PostponeJob.
+func (mr *MockAccrualJobServiceMockRecorder) PostponeJob(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostponeJob", reflect.TypeOf((*MockAccrualJobService)(nil).PostponeJob), arg0, arg1, arg2)
+}
+
+// RetryJob mocks base method.
+func (m *MockAccrualJobService) RetryJob(arg0 context.Context, arg1 *domain.AccrualJob, arg2 error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryJob", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryJob indicates an expected call of RetryJob.
+func (mr *MockAccrualJobServiceMockRecorder) RetryJob(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryJob", reflect.TypeOf((*MockAccrualJobService)(nil).RetryJob), arg0, arg1, arg2)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/workers (interfaces: CampaignService)
+
+// Package mock_workers is a generated GoMock package.
+package mock_workers
+
+import (
+	context "context"
+	domain "gophermart/internal/app/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCampaignService is a mock of CampaignService interface.
+type MockCampaignService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCampaignServiceMockRecorder
+}
+
+// MockCampaignServiceMockRecorder is the mock recorder for MockCampaignService.
+type MockCampaignServiceMockRecorder struct {
+	mock *MockCampaignService
+}
+
+// NewMockCampaignService creates a new mock instance.
+func NewMockCampaignService(ctrl *gomock.Controller) *MockCampaignService {
+	mock := &MockCampaignService{ctrl: ctrl}
+	mock.recorder = &MockCampaignServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCampaignService) EXPECT() *MockCampaignServiceMockRecorder {
+	return m.recorder
+}
+
+// ApplyCampaigns mocks base method.
+func (m *MockCampaignService) ApplyCampaigns(arg0 context.Context, arg1 string, arg2 domain.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyCampaigns", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyCampaigns indicates an expected call of ApplyCampaigns.
+func (mr *MockCampaignServiceMockRecorder) ApplyCampaigns(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyCampaigns", reflect.TypeOf((*MockCampaignService)(nil).ApplyCampaigns), arg0, arg1, arg2)
+}
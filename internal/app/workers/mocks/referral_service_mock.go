@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gophermart/internal/app/workers (interfaces: ReferralService)
+
+// Package mock_workers is a generated GoMock package.
+package mock_workers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReferralService is a mock of ReferralService interface.
+type MockReferralService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReferralServiceMockRecorder
+}
+
+// MockReferralServiceMockRecorder is the mock recorder for MockReferralService.
+type MockReferralServiceMockRecorder struct {
+	mock *MockReferralService
+}
+
+// NewMockReferralService creates a new mock instance.
+func NewMockReferralService(ctrl *gomock.Controller) *MockReferralService {
+	mock := &MockReferralService{ctrl: ctrl}
+	mock.recorder = &MockReferralServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReferralService) EXPECT() *MockReferralServiceMockRecorder {
+	return m.recorder
+}
+
+// ApplyReferralBonus mocks base method.
+func (m *MockReferralService) ApplyReferralBonus(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyReferralBonus", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyReferralBonus indicates an expected call of ApplyReferralBonus.
+func (mr *MockReferralServiceMockRecorder) ApplyReferralBonus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyReferralBonus", reflect.TypeOf((*MockReferralService)(nil).ApplyReferralBonus), arg0, arg1)
+}
@@ -6,6 +6,7 @@ package mock_workers
 
 import (
 	context "context"
+	domain "gophermart/internal/app/domain"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,7 +36,7 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 }
 
 // IncreaseBalanceAndUpdateOrderStatus mocks base method.
-func (m *MockUserService) IncreaseBalanceAndUpdateOrderStatus(arg0 context.Context, arg1 string, arg2 float32, arg3 string) error {
+func (m *MockUserService) IncreaseBalanceAndUpdateOrderStatus(arg0 context.Context, arg1 string, arg2 domain.Money, arg3 string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "IncreaseBalanceAndUpdateOrderStatus", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
@@ -2,43 +2,66 @@ package workers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/rs/zerolog/log"
 	"gophermart/internal/app/domain"
+	"gophermart/internal/app/services"
 	"sync"
 	"time"
 )
 
 type UserService interface {
-	IncreaseBalanceAndUpdateOrderStatus(ctx context.Context, orderNumber string, accrual float32, orderStatus string) error
+	IncreaseBalanceAndUpdateOrderStatus(ctx context.Context, orderNumber string, accrual domain.Money, orderStatus string) error
 }
 
 type OrderService interface {
-	UpdateOrderStatusAndAccrual(ctx context.Context, orderNumber string, orderStatus string, accrual float32) error
-	GetUnprocessedOrdersNumbers(ctx context.Context) ([]string, error)
+	UpdateOrderStatusAndAccrual(ctx context.Context, orderNumber string, orderStatus string, accrual domain.Money) error
+}
+
+// AccrualJobService выдает воркерам задания на обработку заказов из очереди в базе данных
+type AccrualJobService interface {
+	ClaimJobs(ctx context.Context, limit int) ([]*domain.AccrualJob, error)
+	PollJobLater(ctx context.Context, job *domain.AccrualJob) error
+	RetryJob(ctx context.Context, job *domain.AccrualJob, cause error) error
+	PostponeJob(ctx context.Context, job *domain.AccrualJob, delay time.Duration) error
+	CompleteJob(ctx context.Context, job *domain.AccrualJob) error
+}
+
+// CampaignService начисляет бонусы по промо-акциям за обработанные заказы
+type CampaignService interface {
+	ApplyCampaigns(ctx context.Context, orderNumber string, accrual domain.Money) error
+}
+
+// ReferralService начисляет бонусы за приглашение после первого обработанного заказа приглашенного пользователя
+type ReferralService interface {
+	ApplyReferralBonus(ctx context.Context, orderNumber string) error
 }
 
 type OrderAccrualWorker struct {
-	ordersCh          chan string
+	jobService        AccrualJobService
 	userService       UserService
 	orderService      OrderService
+	campaignService   CampaignService
+	referralService   ReferralService
 	accrualCalculator AccrualCalculator
-	unprocessedOrders []string
 }
 
 func NewOrderAccrualWorker(
-	ordersCh chan string,
+	jobService AccrualJobService,
 	userService UserService,
 	orderService OrderService,
+	campaignService CampaignService,
+	referralService ReferralService,
 	accrualCalculator AccrualCalculator,
-	unprocessedOrders []string,
 ) *OrderAccrualWorker {
 	return &OrderAccrualWorker{
-		ordersCh:          ordersCh,
+		jobService:        jobService,
 		accrualCalculator: accrualCalculator,
 		userService:       userService,
 		orderService:      orderService,
-		unprocessedOrders: unprocessedOrders,
+		campaignService:   campaignService,
+		referralService:   referralService,
 	}
 }
 
@@ -47,20 +70,26 @@ func NewOrderAccrualWorker(
 // возвращает флаг processed, указывающий на то, был ли обработан заказ
 func (w *OrderAccrualWorker) processOrder(ctx context.Context, orderNumber string) (bool, error) {
 	// получаем сведения по начислению баллов за заказ
-	accrualRes, err := w.accrualCalculator.GetOrderAccrualRes(orderNumber)
+	accrualRes, err := w.accrualCalculator.GetOrderAccrualRes(ctx, orderNumber)
 	if err != nil {
 		return false, err
 	}
-	time.Sleep(3 * time.Second)
+
+	// заказ зарегистрирован, но расчет еще не начат: у заказа в системе лояльности нет такого статуса,
+	// поэтому статус заказа не меняется, а результат запрашивается позже
+	if accrualRes.Status == domain.AccrualRegisteredStatus {
+		return false, nil
+	}
 
 	// обновляем статус заказа
 	newOrderStatus := accrualRes.Status
 	orderAccrual := accrualRes.Accrual
 
-	// если заказ оказался обработанным, то прибавляем пользователю баланс по этому заказу
+	// если заказ оказался обработанным, то прибавляем пользователю баланс по этому заказу,
+	// начисление увеличивается в соответствии с уровнем пользователя
 	if newOrderStatus == domain.OrderProcessedStatus && accrualRes.Accrual != 0 {
-		log.Info().Msg(fmt.Sprintf("increasing balance for order '%s', accrual - %f", orderNumber, accrualRes.Accrual))
-		err = w.userService.IncreaseBalanceAndUpdateOrderStatus(ctx, orderNumber, accrualRes.Accrual, newOrderStatus)
+		log.Info().Msg(fmt.Sprintf("increasing balance for order '%s', accrual - %v", orderNumber, orderAccrual))
+		err = w.userService.IncreaseBalanceAndUpdateOrderStatus(ctx, orderNumber, orderAccrual, newOrderStatus)
 		if err != nil {
 			log.Error().Msg("increasing user balance failed: " + err.Error())
 			return false, err
@@ -79,72 +108,101 @@ func (w *OrderAccrualWorker) processOrder(ctx context.Context, orderNumber strin
 		return true, nil
 	}
 	if accrualRes.Status == domain.OrderProcessedStatus {
+		// при ошибке начисления бонусов задание остается в очереди и повторяется:
+		// повторно баллы за уже обработанный заказ не начисляются, а бонусы начисляются не больше одного раза
+		if err := w.campaignService.ApplyCampaigns(ctx, orderNumber, accrualRes.Accrual); err != nil {
+			return false, fmt.Errorf("applying campaigns to order '%s' failed: %w", orderNumber, err)
+		}
+		if err := w.referralService.ApplyReferralBonus(ctx, orderNumber); err != nil {
+			return false, fmt.Errorf("applying referral bonus to order '%s' failed: %w", orderNumber, err)
+		}
 		return true, nil
 	}
 
 	return false, nil
 }
 
-// processOrders поочередно берет заказы из списка необработанных заказов
-// и для каждого проверяет статус
-func (w *OrderAccrualWorker) processOrders(ctx context.Context) error {
-	log.Info().Msg(fmt.Sprintf("processing orders list: %v", w.unprocessedOrders))
-	var tmpOrders []string
+// processJob выполняет очередной этап задания: регистрирует заказ в системе расчета баллов
+// или запрашивает результат расчета, пока заказ не получит окончательный статус
+func (w *OrderAccrualWorker) processJob(ctx context.Context, job *domain.AccrualJob) error {
+	if job.Stage == domain.AccrualJobRegisterStage {
+		log.Info().Msg(fmt.Sprintf("registering order '%s' for accrual", job.OrderNumber))
+		if err := w.accrualCalculator.CreateOrderForCalculation(ctx, job.OrderNumber); err != nil {
+			return err
+		}
+		return w.jobService.PollJobLater(ctx, job)
+	}
+
+	orderProcessed, err := w.processOrder(ctx, job.OrderNumber)
+	if err != nil {
+		return err
+	}
+	if orderProcessed {
+		return w.jobService.CompleteJob(ctx, job)
+	}
+	return w.jobService.PollJobLater(ctx, job)
+}
 
-	for _, orderNumber := range w.unprocessedOrders {
-		select {
+// processJobs поочередно выполняет захваченные задания
+// задания, до которых не дошла очередь, возвращаются в очередь
+func (w *OrderAccrualWorker) processJobs(ctx context.Context, jobs []*domain.AccrualJob) {
+	for i, job := range jobs {
 		// на каждом шаге проверяем, нужно ли завершать работу
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			orderProcessed, err := w.processOrder(ctx, orderNumber)
-			if err != nil {
-				log.Error().Msg(fmt.Sprintf("failed to process order: %v", err.Error()))
-			}
-			if !orderProcessed {
-				tmpOrders = append(tmpOrders, orderNumber)
+		if ctx.Err() != nil {
+			// контекст уже отменен, поэтому задания возвращаются в очередь без него
+			w.postponeJobs(context.Background(), jobs[i:], 0)
+			return
+		}
+		// если захват истек, задание уже могли забрать другой воркер или другой экземпляр сервиса
+		if job.LockedUntil != nil && !time.Now().Before(*job.LockedUntil) {
+			log.Info().Msg(fmt.Sprintf("accrual job %d lease expired, skipping order '%s'", job.ID, job.OrderNumber))
+			continue
+		}
+		err := w.processJob(ctx, job)
+		// пока система расчета баллов недоступна, не обращаемся к ней и откладываем оставшиеся задания
+		if errors.Is(err, services.ErrAccrualCircuitOpen) {
+			log.Info().Msg(fmt.Sprintf("accrual system is unavailable, backing off for %v", circuitOpenBackoff))
+			w.postponeJobs(ctx, jobs[i:], circuitOpenBackoff)
+			waitBackoff(ctx, circuitOpenBackoff)
+			return
+		}
+		if err != nil && ctx.Err() != nil {
+			w.postponeJobs(context.Background(), jobs[i:], 0)
+			return
+		}
+		if err != nil {
+			log.Error().Msg(fmt.Sprintf("failed to process order '%s': %v", job.OrderNumber, err.Error()))
+			if retryErr := w.jobService.RetryJob(ctx, job, err); retryErr != nil {
+				log.Error().Msg(fmt.Sprintf("rescheduling accrual job %d failed: %v", job.ID, retryErr.Error()))
 			}
 		}
 	}
+}
 
-	w.unprocessedOrders = tmpOrders
-
-	return nil
+// postponeJobs возвращает задания в очередь с паузой delay
+func (w *OrderAccrualWorker) postponeJobs(ctx context.Context, jobs []*domain.AccrualJob, delay time.Duration) {
+	for _, job := range jobs {
+		if err := w.jobService.PostponeJob(ctx, job, delay); err != nil {
+			log.Error().Msg(fmt.Sprintf("rescheduling accrual job %d failed: %v", job.ID, err.Error()))
+		}
+	}
 }
 
 func (w *OrderAccrualWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for {
-		// если необработанных заказов нет, то просто ожидаем, когда придет новый заказ
-		if len(w.unprocessedOrders) == 0 {
-			log.Info().Msg("waiting for new order number")
-			select {
-			case orderNumber, ok := <-w.ordersCh:
-				if !ok {
-					log.Info().Msg("orders worker stops - order chan is closed")
-					return
-				}
-				log.Info().Msg(fmt.Sprintf("receiving new order '%s' in accrual worker", orderNumber))
-				w.unprocessedOrders = append(w.unprocessedOrders, orderNumber)
-			case <-ctx.Done():
-				log.Info().Msg("orders worker stops - context is done")
-				return
-			}
+		jobs, err := w.jobService.ClaimJobs(ctx, accrualJobsBatchSize)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Msg(fmt.Sprintf("claiming accrual jobs failed - %v", err.Error()))
 		}
-		select {
-		case orderNumber, ok := <-w.ordersCh:
-			if !ok {
-				log.Info().Msg("orders worker stops - order chan is closed")
-				return
-			}
-			log.Info().Msg(fmt.Sprintf("receiving new order '%s' in accrual worker", orderNumber))
-			w.unprocessedOrders = append(w.unprocessedOrders, orderNumber)
-		default:
-			err := w.processOrders(ctx)
-			if err != nil {
-				log.Error().Msg(fmt.Sprintf("processing orders failed - %v", err.Error()))
+		// если готовых заданий нет, то ждем, когда они появятся
+		if len(jobs) == 0 {
+			if !waitBackoff(ctx, emptyQueueInterval) {
+				log.Info().Msg("orders worker stops - context is done")
 				return
 			}
+			continue
 		}
+		w.processJobs(ctx, jobs)
 	}
 }
@@ -2,12 +2,15 @@ package workers
 
 import (
 	"context"
+	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gophermart/internal/app/domain"
+	"gophermart/internal/app/services"
 	mock_workers "gophermart/internal/app/workers/mocks"
 	"testing"
+	"time"
 )
 
 func TestOrderAccrualWorker_processOrder(t *testing.T) {
@@ -60,8 +63,15 @@ func TestOrderAccrualWorker_processOrder(t *testing.T) {
 			defer ctrl.Finish()
 			accrualCalculatorMock := mock_workers.NewMockAccrualCalculator(ctrl)
 			ctx := context.Background()
-			accrualCalculatorMock.EXPECT().GetOrderAccrualRes(orderNumber).Return(tt.accrualRes, nil)
+			accrualCalculatorMock.EXPECT().GetOrderAccrualRes(gomock.Any(), orderNumber).Return(tt.accrualRes, nil)
 			userServiceMock := mock_workers.NewMockUserService(ctrl)
+			campaignServiceMock := mock_workers.NewMockCampaignService(ctrl)
+			referralServiceMock := mock_workers.NewMockReferralService(ctrl)
+			if tt.accrualRes.Status == domain.OrderProcessedStatus {
+				// бонусы по акциям считаются от начисления системы расчета баллов
+				campaignServiceMock.EXPECT().ApplyCampaigns(gomock.Any(), orderNumber, tt.accrualRes.Accrual).Return(nil)
+				referralServiceMock.EXPECT().ApplyReferralBonus(gomock.Any(), orderNumber).Return(nil)
+			}
 			if tt.shouldIncreaseBalance {
 				userServiceMock.EXPECT().IncreaseBalanceAndUpdateOrderStatus(
 					gomock.Any(), orderNumber, tt.accrualRes.Accrual, tt.accrualRes.Status,
@@ -78,7 +88,12 @@ func TestOrderAccrualWorker_processOrder(t *testing.T) {
 			}
 
 			orderWorker := NewOrderAccrualWorker(
-				make(chan string), userServiceMock, orderServiceMock, accrualCalculatorMock, []string{},
+				mock_workers.NewMockAccrualJobService(ctrl),
+				userServiceMock,
+				orderServiceMock,
+				campaignServiceMock,
+				referralServiceMock,
+				accrualCalculatorMock,
 			)
 			actualRes, err := orderWorker.processOrder(ctx, orderNumber)
 			require.NoError(t, err)
@@ -86,3 +101,204 @@ func TestOrderAccrualWorker_processOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderAccrualWorker_processOrder_BonusFailure(t *testing.T) {
+	orderNumber := "123"
+	accrualRes := &domain.AccrualCalculationRes{Order: orderNumber, Status: domain.OrderProcessedStatus, Accrual: 100}
+	bonusErr := errors.New("connection refused")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	accrualCalculatorMock := mock_workers.NewMockAccrualCalculator(ctrl)
+	accrualCalculatorMock.EXPECT().GetOrderAccrualRes(gomock.Any(), orderNumber).Return(accrualRes, nil).Times(2)
+	// при повторной обработке заказ уже имеет окончательный статус, поэтому баллы повторно не начисляются
+	userServiceMock := mock_workers.NewMockUserService(ctrl)
+	userServiceMock.EXPECT().IncreaseBalanceAndUpdateOrderStatus(
+		gomock.Any(), orderNumber, accrualRes.Accrual, domain.OrderProcessedStatus,
+	).Return(nil).Times(2)
+	campaignServiceMock := mock_workers.NewMockCampaignService(ctrl)
+	referralServiceMock := mock_workers.NewMockReferralService(ctrl)
+	gomock.InOrder(
+		campaignServiceMock.EXPECT().ApplyCampaigns(gomock.Any(), orderNumber, accrualRes.Accrual).Return(bonusErr),
+		campaignServiceMock.EXPECT().ApplyCampaigns(gomock.Any(), orderNumber, accrualRes.Accrual).Return(nil),
+	)
+	referralServiceMock.EXPECT().ApplyReferralBonus(gomock.Any(), orderNumber).Return(nil)
+
+	orderWorker := NewOrderAccrualWorker(
+		mock_workers.NewMockAccrualJobService(ctrl),
+		userServiceMock,
+		mock_workers.NewMockOrderService(ctrl),
+		campaignServiceMock,
+		referralServiceMock,
+		accrualCalculatorMock,
+	)
+	// ошибка начисления бонусов не завершает задание, чтобы бонусы начислились при повторе
+	processed, err := orderWorker.processOrder(ctx, orderNumber)
+	assert.ErrorIs(t, err, bonusErr)
+	assert.False(t, processed)
+
+	processed, err = orderWorker.processOrder(ctx, orderNumber)
+	require.NoError(t, err)
+	assert.True(t, processed)
+}
+
+func TestOrderAccrualWorker_processJobs(t *testing.T) {
+	orderNumber := "123"
+	tests := []struct {
+		name          string
+		stage         string
+		registerErr   error
+		accrualRes    *domain.AccrualCalculationRes
+		accrualErr    error
+		wantPostponed bool
+		wantCompleted bool
+		wantRetried   bool
+	}{
+		{
+			name:          "order is registered for accrual",
+			stage:         domain.AccrualJobRegisterStage,
+			wantPostponed: true,
+		},
+		{
+			name:        "registration failed",
+			stage:       domain.AccrualJobRegisterStage,
+			registerErr: errors.New("bad gateway"),
+			wantRetried: true,
+		},
+		{
+			name:          "order is still processing",
+			stage:         domain.AccrualJobPollStage,
+			accrualRes:    &domain.AccrualCalculationRes{Order: orderNumber, Status: domain.OrderProcessingStatus},
+			wantPostponed: true,
+		},
+		{
+			name:          "order got final status",
+			stage:         domain.AccrualJobPollStage,
+			accrualRes:    &domain.AccrualCalculationRes{Order: orderNumber, Status: domain.OrderInvalidStatus},
+			wantCompleted: true,
+		},
+		{
+			name:        "getting accrual failed",
+			stage:       domain.AccrualJobPollStage,
+			accrualErr:  errors.New("bad gateway"),
+			wantRetried: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			job := &domain.AccrualJob{ID: 1, OrderNumber: orderNumber, Stage: tt.stage}
+			accrualCalculatorMock := mock_workers.NewMockAccrualCalculator(ctrl)
+			orderServiceMock := mock_workers.NewMockOrderService(ctrl)
+			if tt.stage == domain.AccrualJobRegisterStage {
+				accrualCalculatorMock.EXPECT().CreateOrderForCalculation(gomock.Any(), orderNumber).Return(tt.registerErr)
+			} else {
+				accrualCalculatorMock.EXPECT().GetOrderAccrualRes(gomock.Any(), orderNumber).Return(tt.accrualRes, tt.accrualErr)
+			}
+			if tt.accrualRes != nil {
+				orderServiceMock.EXPECT().UpdateOrderStatusAndAccrual(
+					gomock.Any(), orderNumber, tt.accrualRes.Status, tt.accrualRes.Accrual,
+				).Return(nil)
+			}
+			jobServiceMock := mock_workers.NewMockAccrualJobService(ctrl)
+			// после регистрации и пока заказ рассчитывается, задание переходит к запросу результата
+			if tt.wantPostponed {
+				jobServiceMock.EXPECT().PollJobLater(gomock.Any(), job).Return(nil)
+			}
+			if tt.wantCompleted {
+				jobServiceMock.EXPECT().CompleteJob(gomock.Any(), job).Return(nil)
+			}
+			if tt.wantRetried {
+				// ошибка сохраняется в задании, чтобы администратор мог разобрать отложенные заказы
+				cause := tt.registerErr
+				if cause == nil {
+					cause = tt.accrualErr
+				}
+				jobServiceMock.EXPECT().RetryJob(gomock.Any(), job, cause).Return(nil)
+			}
+
+			orderWorker := NewOrderAccrualWorker(
+				jobServiceMock,
+				mock_workers.NewMockUserService(ctrl),
+				orderServiceMock,
+				mock_workers.NewMockCampaignService(ctrl),
+				mock_workers.NewMockReferralService(ctrl),
+				accrualCalculatorMock,
+			)
+			orderWorker.processJobs(context.Background(), []*domain.AccrualJob{job})
+		})
+	}
+}
+
+func TestOrderAccrualWorker_processJobs_Registered(t *testing.T) {
+	orderNumber := "123"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	job := &domain.AccrualJob{ID: 1, OrderNumber: orderNumber, Stage: domain.AccrualJobPollStage}
+	accrualCalculatorMock := mock_workers.NewMockAccrualCalculator(ctrl)
+	accrualCalculatorMock.EXPECT().GetOrderAccrualRes(gomock.Any(), orderNumber).Return(
+		&domain.AccrualCalculationRes{Order: orderNumber, Status: domain.AccrualRegisteredStatus}, nil,
+	)
+	// статус REGISTERED не записывается в заказ, задание просто ждет следующего запроса результата
+	jobServiceMock := mock_workers.NewMockAccrualJobService(ctrl)
+	jobServiceMock.EXPECT().PollJobLater(gomock.Any(), job).Return(nil)
+
+	orderWorker := NewOrderAccrualWorker(
+		jobServiceMock,
+		mock_workers.NewMockUserService(ctrl),
+		mock_workers.NewMockOrderService(ctrl),
+		mock_workers.NewMockCampaignService(ctrl),
+		mock_workers.NewMockReferralService(ctrl),
+		accrualCalculatorMock,
+	)
+	orderWorker.processJobs(context.Background(), []*domain.AccrualJob{job})
+}
+
+func TestOrderAccrualWorker_processJobs_LeaseExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// задание с истекшим захватом могли забрать другие воркеры, поэтому оно не выполняется и не меняется
+	lockedUntil := time.Now().Add(-time.Second)
+	jobs := []*domain.AccrualJob{{ID: 1, OrderNumber: "123", Stage: domain.AccrualJobPollStage, LockedUntil: &lockedUntil}}
+
+	orderWorker := NewOrderAccrualWorker(
+		mock_workers.NewMockAccrualJobService(ctrl),
+		mock_workers.NewMockUserService(ctrl),
+		mock_workers.NewMockOrderService(ctrl),
+		mock_workers.NewMockCampaignService(ctrl),
+		mock_workers.NewMockReferralService(ctrl),
+		mock_workers.NewMockAccrualCalculator(ctrl),
+	)
+	orderWorker.processJobs(context.Background(), jobs)
+}
+
+func TestOrderAccrualWorker_processJobs_CircuitOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	jobs := []*domain.AccrualJob{
+		{ID: 1, OrderNumber: "123", Stage: domain.AccrualJobPollStage},
+		{ID: 2, OrderNumber: "456", Stage: domain.AccrualJobRegisterStage},
+	}
+	accrualCalculatorMock := mock_workers.NewMockAccrualCalculator(ctrl)
+	accrualCalculatorMock.EXPECT().GetOrderAccrualRes(gomock.Any(), "123").Return(nil, services.ErrAccrualCircuitOpen)
+	// пока выключатель разомкнут, все захваченные задания откладываются на прежних этапах без учета попытки
+	jobServiceMock := mock_workers.NewMockAccrualJobService(ctrl)
+	jobServiceMock.EXPECT().PostponeJob(gomock.Any(), jobs[0], circuitOpenBackoff).Return(nil)
+	jobServiceMock.EXPECT().PostponeJob(gomock.Any(), jobs[1], circuitOpenBackoff).Return(nil)
+
+	orderWorker := NewOrderAccrualWorker(
+		jobServiceMock,
+		mock_workers.NewMockUserService(ctrl),
+		mock_workers.NewMockOrderService(ctrl),
+		mock_workers.NewMockCampaignService(ctrl),
+		mock_workers.NewMockReferralService(ctrl),
+		accrualCalculatorMock,
+	)
+	// контекст ограничен, чтобы не ждать окончания паузы
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	orderWorker.processJobs(ctx, jobs)
+}
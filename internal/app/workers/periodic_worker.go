@@ -0,0 +1,41 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"sync"
+	"time"
+)
+
+// PeriodicTask задача, которую воркер периодически выполняет, например списание сгоревших баллов
+type PeriodicTask func(ctx context.Context) error
+
+// PeriodicWorker выполняет задачу сразу после запуска и затем каждые interval, пока не отменен контекст
+// ошибка задачи записывается в лог и не останавливает воркер
+type PeriodicWorker struct {
+	name     string
+	task     PeriodicTask
+	interval time.Duration
+}
+
+func NewPeriodicWorker(name string, task PeriodicTask, interval time.Duration) *PeriodicWorker {
+	return &PeriodicWorker{name: name, task: task, interval: interval}
+}
+
+func (w *PeriodicWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.task(ctx); err != nil {
+			log.Error().Msg(fmt.Sprintf("%s failed - %v", w.name, err.Error()))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Info().Msg(fmt.Sprintf("%s worker stops - context is done", w.name))
+			return
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPeriodicWorker_Run(t *testing.T) {
+	var runs int32
+	task := func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		// ошибка задачи не останавливает воркер
+		return errors.New("connection refused")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go NewPeriodicWorker("test", task, time.Millisecond).Run(ctx, wg)
+
+	// задача выполняется сразу после запуска и затем повторяется
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 3 }, time.Second, time.Millisecond)
+
+	// после отмены контекста воркер останавливается
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,257 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gophermart/internal/app/accrualfake"
+	"gophermart/internal/app/domain"
+	"gophermart/internal/app/services"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryJobRepository очередь заданий в памяти с теми же правилами захвата, что и очередь в базе данных
+type memoryJobRepository struct {
+	mu   sync.Mutex
+	jobs map[int64]domain.AccrualJob
+}
+
+func newMemoryJobRepository(orderNumbers ...string) *memoryJobRepository {
+	r := &memoryJobRepository{jobs: make(map[int64]domain.AccrualJob)}
+	for i, orderNumber := range orderNumbers {
+		id := int64(i + 1)
+		r.jobs[id] = domain.AccrualJob{ID: id, OrderNumber: orderNumber, Stage: domain.AccrualJobRegisterStage}
+	}
+	return r
+}
+
+func (r *memoryJobRepository) ClaimAccrualJobs(
+	_ context.Context, owner string, now time.Time, lockedUntil time.Time, limit int,
+) ([]*domain.AccrualJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]int64, 0, len(r.jobs))
+	for id := range r.jobs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var claimed []*domain.AccrualJob
+	for _, id := range ids {
+		job := r.jobs[id]
+		if len(claimed) == limit || job.DeadLetteredAt != nil || job.NextAttemptAt.After(now) {
+			continue
+		}
+		if job.LockedUntil != nil && job.LockedUntil.After(now) {
+			continue
+		}
+		until := lockedUntil
+		job.LockedUntil = &until
+		job.LockedBy = owner
+		r.jobs[id] = job
+		claimed = append(claimed, &job)
+	}
+	return claimed, nil
+}
+
+func (r *memoryJobRepository) checkLease(job *domain.AccrualJob) error {
+	if stored, ok := r.jobs[job.ID]; !ok || stored.LockedBy != job.LockedBy {
+		return fmt.Errorf("accrual job %d lease lost", job.ID)
+	}
+	return nil
+}
+
+func (r *memoryJobRepository) RescheduleAccrualJob(_ context.Context, job *domain.AccrualJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkLease(job); err != nil {
+		return err
+	}
+	job.LockedUntil = nil
+	job.LockedBy = ""
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryJobRepository) DeleteAccrualJob(_ context.Context, job *domain.AccrualJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkLease(job); err != nil {
+		return err
+	}
+	delete(r.jobs, job.ID)
+	return nil
+}
+
+func (r *memoryJobRepository) GetDeadLetteredAccrualJobs(context.Context, int, int) ([]*domain.AccrualJob, error) {
+	return nil, nil
+}
+
+func (r *memoryJobRepository) RequeueAccrualJob(context.Context, int64, int, time.Time) (*domain.AccrualJob, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// pendingJobs возвращает задания, которые еще должны быть выполнены
+func (r *memoryJobRepository) pendingJobs() []domain.AccrualJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var jobs []domain.AccrualJob
+	for _, job := range r.jobs {
+		if job.DeadLetteredAt == nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+func (r *memoryJobRepository) job(orderNumber string) (domain.AccrualJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, job := range r.jobs {
+		if job.OrderNumber == orderNumber {
+			return job, true
+		}
+	}
+	return domain.AccrualJob{}, false
+}
+
+// orderBook запоминает статусы заказов и начисления пользователям вместо базы данных
+type orderBook struct {
+	mu       sync.Mutex
+	statuses map[string]string
+	credits  map[string]domain.Money
+}
+
+func newOrderBook() *orderBook {
+	return &orderBook{statuses: make(map[string]string), credits: make(map[string]domain.Money)}
+}
+
+func (b *orderBook) IncreaseBalanceAndUpdateOrderStatus(
+	_ context.Context, orderNumber string, accrual domain.Money, orderStatus string,
+) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.statuses[orderNumber] = orderStatus
+	b.credits[orderNumber] += accrual
+	return nil
+}
+
+func (b *orderBook) UpdateOrderStatusAndAccrual(
+	_ context.Context, orderNumber string, orderStatus string, _ domain.Money,
+) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.statuses[orderNumber] = orderStatus
+	return nil
+}
+
+func (b *orderBook) status(orderNumber string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.statuses[orderNumber]
+}
+
+func (b *orderBook) credit(orderNumber string) domain.Money {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.credits[orderNumber]
+}
+
+// noBonuses программа лояльности без акций и приглашений
+type noBonuses struct{}
+
+func (noBonuses) ApplyCampaigns(context.Context, string, domain.Money) error {
+	return nil
+}
+
+func (noBonuses) ApplyReferralBonus(context.Context, string) error {
+	return nil
+}
+
+// TestAccrualPipeline проверяет обработку заказов воркерами от очереди заданий до начисления баллов
+// с поддельной системой расчета баллов вместо внешнего сервиса
+func TestAccrualPipeline(t *testing.T) {
+	processed := func(accrual domain.Money) accrualfake.Step {
+		return accrualfake.Step{Status: domain.OrderProcessedStatus, Accrual: &accrual}
+	}
+	fake, srv := accrualfake.NewServer()
+	t.Cleanup(srv.Close)
+	fake.SetScenario("1", accrualfake.Scenario{Steps: []accrualfake.Step{
+		{Status: domain.AccrualRegisteredStatus},
+		{Status: domain.OrderProcessingStatus},
+		processed(domain.NewMoney(500, 0)),
+	}})
+	fake.SetScenario("2", accrualfake.Scenario{Steps: []accrualfake.Step{
+		{Status: domain.OrderProcessingStatus},
+		{Status: domain.OrderInvalidStatus},
+	}})
+	fake.SetScenario("3", accrualfake.Scenario{Steps: []accrualfake.Step{
+		{HTTPStatus: http.StatusTooManyRequests, RetryAfter: 1},
+		processed(domain.NewMoney(100, 50)),
+	}})
+	fake.SetScenario("4", accrualfake.Scenario{Steps: []accrualfake.Step{
+		{HTTPStatus: http.StatusNoContent},
+		processed(domain.NewMoney(50, 0)),
+	}})
+	fake.SetScenario("5", accrualfake.Scenario{RegisterStatus: http.StatusInternalServerError})
+
+	accrualCalculator := services.NewAccrualCalculationService(
+		srv.URL, services.NewAccrualRateLimiter(0), http.DefaultClient, services.NewCircuitBreaker(100, time.Minute),
+	)
+	jobRepository := newMemoryJobRepository("1", "2", "3", "4", "5")
+	jobService := services.NewAccrualJobService(
+		jobRepository,
+		services.NewBackoff(time.Millisecond, 10*time.Millisecond),
+		services.NewBackoff(time.Millisecond, 10*time.Millisecond),
+		2,
+	)
+	book := newOrderBook()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	pool := NewAccrualWorkerPool(ctx, wg, func() Worker {
+		return NewOrderAccrualWorker(jobService, book, book, noBonuses{}, noBonuses{}, accrualCalculator)
+	})
+	pool.Resize(2)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	require.Eventually(t, func() bool {
+		return len(jobRepository.pendingJobs()) == 0
+	}, 20*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, domain.OrderProcessedStatus, book.status("1"))
+	assert.Equal(t, domain.NewMoney(500, 0), book.credit("1"))
+	assert.Equal(t, 3, fake.Polls("1"))
+
+	assert.Equal(t, domain.OrderInvalidStatus, book.status("2"))
+	assert.Equal(t, domain.Money(0), book.credit("2"))
+
+	assert.Equal(t, domain.OrderProcessedStatus, book.status("3"))
+	assert.Equal(t, domain.NewMoney(100, 50), book.credit("3"))
+
+	assert.Equal(t, domain.OrderProcessedStatus, book.status("4"))
+	assert.Equal(t, domain.NewMoney(50, 0), book.credit("4"))
+
+	// заказ, который система расчета так и не приняла, откладывается до решения администратора
+	job, ok := jobRepository.job("5")
+	require.True(t, ok)
+	assert.NotNil(t, job.DeadLetteredAt)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Equal(t, domain.AccrualJobRegisterStage, job.Stage)
+	assert.Empty(t, book.status("5"))
+}
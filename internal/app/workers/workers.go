@@ -12,16 +12,22 @@ import (
 )
 
 const (
-	accrualWorkersNum = 2
+	// accrualJobsBatchSize количество заданий, которые воркер захватывает за один раз
+	accrualJobsBatchSize = 10
+	// emptyQueueInterval пауза перед повторной проверкой очереди, если в ней нет готовых заданий
+	emptyQueueInterval = time.Second
+	// circuitOpenBackoff пауза воркеров, пока выключатель запросов к системе расчета баллов разомкнут
+	circuitOpenBackoff = 5 * time.Second
 )
 
 type AccrualCalculator interface {
-	CreateOrderForCalculation(orderNumber string) error
-	GetOrderAccrualRes(orderNumber string) (*domain.AccrualCalculationRes, error)
+	CreateOrderForCalculation(ctx context.Context, orderNumber string) error
+	GetOrderAccrualRes(ctx context.Context, orderNumber string) (*domain.AccrualCalculationRes, error)
 }
 
 type Runner struct {
-	ordersWorkersWG *sync.WaitGroup
+	ordersWorkersWG   *sync.WaitGroup
+	accrualWorkerPool *AccrualWorkerPool
 }
 
 func NewRunner() *Runner {
@@ -31,38 +37,55 @@ func NewRunner() *Runner {
 func (r *Runner) StartWorkers(
 	ctx context.Context,
 	config *configs.Config,
-	ordersCh chan string,
+	accrualCalculator AccrualCalculator,
+	accrualJobService *services.AccrualJobService,
 	orderService *services.OrderService,
 	userService *services.UserService,
+	ledgerService *services.LedgerService,
+	holdService *services.HoldService,
+	tierService *services.TierService,
+	campaignService *services.CampaignService,
+	referralService *services.ReferralService,
+	idempotencyService *services.IdempotencyService,
 ) {
-	accrualCalculator := services.NewAccrualCalculationService(config.AccrualSystemAddr)
+	r.startPeriodicWorker(ctx, "points expiration", ledgerService.ExpirePoints, config.PointsExpirationInterval)
+	r.startPeriodicWorker(ctx, "hold sweeper", holdService.ReleaseExpiredHolds, config.HoldSweepInterval)
+	r.startPeriodicWorker(ctx, "tier recalculation", tierService.RecalculateTiers, config.TierRecalculationInterval)
+	r.startPeriodicWorker(
+		ctx, "idempotency keys purge", idempotencyService.PurgeExpiredKeys, config.IdempotencyKeyPurgeInterval,
+	)
 
-	processOrdersCh := make(chan string, 100)
-	log.Info().Msg("starting register orders worker")
-	worker := NewRegisterOrdersWorker(ordersCh, processOrdersCh, accrualCalculator)
+	// воркеры берут заказы из общей очереди в базе данных, поэтому заказы не нужно распределять между ними
+	log.Info().Msg("starting orders accrual workers")
+	r.accrualWorkerPool = NewAccrualWorkerPool(ctx, r.ordersWorkersWG, func() Worker {
+		return NewOrderAccrualWorker(
+			accrualJobService, userService, orderService, campaignService, referralService, accrualCalculator,
+		)
+	})
+	r.accrualWorkerPool.Resize(config.AccrualWorkers)
+}
+
+// startPeriodicWorker запускает воркер, периодически выполняющий служебную задачу
+func (r *Runner) startPeriodicWorker(ctx context.Context, name string, task PeriodicTask, interval time.Duration) {
+	log.Info().Msg(fmt.Sprintf("starting %s worker", name))
 	r.ordersWorkersWG.Add(1)
-	go worker.Run(ctx, r.ordersWorkersWG)
+	go NewPeriodicWorker(name, task, interval).Run(ctx, r.ordersWorkersWG)
+}
 
-	log.Info().Msg("starting orders accrual workers")
-	orders, err := orderService.GetUnprocessedOrdersNumbers(ctx)
-	if err != nil {
-		log.Error().Msg(fmt.Sprintf("getting unprocessed orders failed - %v", err.Error()))
-		return
-	}
+// AccrualWorkerPool возвращает пул воркеров, обрабатывающих заказы, после запуска воркеров
+func (r *Runner) AccrualWorkerPool() *AccrualWorkerPool {
+	return r.accrualWorkerPool
+}
 
-	ordersNum := len(orders)
-	ordersPerWorker := ordersNum / accrualWorkersNum
-	for i := 0; i < accrualWorkersNum; i++ {
-		var ordersForWorker []string
-		ordersStartIdx := i * ordersPerWorker
-		if i == accrualWorkersNum-1 {
-			ordersForWorker = orders[ordersStartIdx:]
-		} else {
-			ordersForWorker = orders[ordersStartIdx : ordersStartIdx+ordersPerWorker]
-		}
-		worker := NewOrderAccrualWorker(processOrdersCh, userService, orderService, accrualCalculator, ordersForWorker)
-		r.ordersWorkersWG.Add(1)
-		go worker.Run(ctx, r.ordersWorkersWG)
+// waitBackoff ожидает окончания паузы d, возвращает false, если воркеры останавливаются
+func waitBackoff(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
 